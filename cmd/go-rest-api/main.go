@@ -9,6 +9,9 @@ import (
 	"go-rest-api/config"
 	"go-rest-api/internal/app"
 	"go-rest-api/pkg/logger"
+	"go-rest-api/pkg/tracing"
+
+	"go.uber.org/zap"
 
 	_ "go-rest-api/docs"
 )
@@ -42,9 +45,36 @@ func main() {
 	zapLogger.Info("Logger initialized")
 
 	ctx := context.Background()
+
+	shutdownTracing, err := tracing.New(ctx, &tracing.Config{
+		Exporter:       cfg.Tracing.Exporter,
+		Endpoint:       cfg.Tracing.Endpoint,
+		Insecure:       cfg.Tracing.Insecure,
+		SampleRatio:    cfg.Tracing.SampleRatio,
+		ServiceName:    cfg.App.Name,
+		ServiceVersion: cfg.App.Version,
+		Environment:    cfg.App.Environment,
+	})
+	if err != nil {
+		zapLogger.Fatal("Error initialize tracing", zap.Error(err))
+	}
+	defer shutdownTracing(context.Background())
+	zapLogger.Info("Tracing initialized", zap.String("exporter", cfg.Tracing.Exporter))
+
 	ctx = config.ToContext(ctx, cfg)
 	ctx = logger.ToContext(ctx, zapLogger)
 
+	// go-rest-api import|export ... -- разовая команда вместо HTTP-сервера
+	if len(os.Args) > 1 {
+		if err := app.Exec(ctx, os.Args[1:]); err != nil {
+			zapLogger.Error("Command failed", zap.Error(err))
+			shutdownTracing(context.Background())
+			zapLogger.Sync()
+			os.Exit(1)
+		}
+		return
+	}
+
 	zapLogger.Info("Application launching..")
 	app.Run(ctx)
 }
@@ -2,6 +2,7 @@ package config
 
 import (
 	"context"
+	"time"
 
 	"github.com/ilyakaznacheev/cleanenv"
 	"github.com/joho/godotenv"
@@ -9,11 +10,18 @@ import (
 
 type (
 	Config struct {
-		App    `yaml:"app"`
-		HTTP   `yaml:"http"`
-		Logger `yaml:"logger"`
-		Postgres
+		App      `yaml:"app"`
+		HTTP     `yaml:"http"`
+		Logger   `yaml:"logger"`
+		Postgres `yaml:"postgres"`
 		Webapi
+		LinkChecker `yaml:"link_checker"`
+		Plays       `yaml:"plays"`
+		Similar     `yaml:"similar"`
+		Metrics     `yaml:"metrics"`
+		Tracing     `yaml:"tracing"`
+		Health      `yaml:"health"`
+		RateLimit   `yaml:"rate_limit"`
 	}
 
 	App struct {
@@ -23,8 +31,61 @@ type (
 		ApiKey      string `env:"API_KEY"`
 	}
 
+	// Таймауты и MaxHeaderBytes: 0 -- умолчание pkg/http-server.
+	// ReadTimeout и WriteTimeout рассчитаны на обычные запросы: импорт (/songs:import), выгрузка
+	// (/songs:export) и NDJSON-листинг /songs продлевают дедлайны своего соединения сами.
+	// С CertFile и KeyFile сервер работает по HTTPS (HTTP/2 включается сам), ClientCAFile добавляет mTLS.
+	// H2C -- HTTP/2 открытым текстом, для внутреннего трафика без TLS.
 	HTTP struct {
-		Port string `env-required:"true" yaml:"port" env:"HTTP_PORT"`
+		Port              string        `env-required:"true" yaml:"port" env:"HTTP_PORT"`
+		ReadTimeout       time.Duration `yaml:"read_timeout" env:"HTTP_READ_TIMEOUT"`
+		ReadHeaderTimeout time.Duration `yaml:"read_header_timeout" env:"HTTP_READ_HEADER_TIMEOUT"`
+		WriteTimeout      time.Duration `yaml:"write_timeout" env:"HTTP_WRITE_TIMEOUT"`
+		IdleTimeout       time.Duration `yaml:"idle_timeout" env:"HTTP_IDLE_TIMEOUT"`
+		ShutdownTimeout   time.Duration `yaml:"shutdown_timeout" env:"HTTP_SHUTDOWN_TIMEOUT"`
+		MaxHeaderBytes    int           `yaml:"max_header_bytes" env:"HTTP_MAX_HEADER_BYTES"`
+		CertFile          string        `yaml:"cert_file" env:"HTTP_CERT_FILE"`
+		KeyFile           string        `yaml:"key_file" env:"HTTP_KEY_FILE"`
+		ClientCAFile      string        `yaml:"client_ca_file" env:"HTTP_CLIENT_CA_FILE"`
+		H2C               bool          `yaml:"h2c" env:"HTTP_H2C"`
+	}
+
+	// Если порт пуст или совпадает с HTTP-портом, метрики отдаются основным сервером.
+	Metrics struct {
+		Port string `yaml:"port" env:"METRICS_PORT"`
+		Path string `yaml:"path" env:"METRICS_PATH" env-default:"/metrics"`
+	}
+
+	// DrainDelay -- сколько /readyz отвечает отказом перед остановкой сервера.
+	Health struct {
+		Webapi     bool          `yaml:"webapi" env:"HEALTH_WEBAPI"`
+		Timeout    time.Duration `yaml:"timeout" env:"HEALTH_TIMEOUT"`
+		DrainDelay time.Duration `yaml:"drain_delay" env:"HEALTH_DRAIN_DELAY"`
+	}
+
+	// Routes -- лимиты маршрутов вида "POST /api/v1/songs" (шаблоны как в роутере);
+	// Default действует на остальные пути /api/. Rate -- запросов в секунду, 0 -- без лимита.
+	RateLimit struct {
+		Enabled        bool                     `yaml:"enabled" env:"RATE_LIMIT_ENABLED"`
+		Backend        string                   `yaml:"backend" env:"RATE_LIMIT_BACKEND"`
+		RedisAddr      string                   `yaml:"redis_addr" env:"RATE_LIMIT_REDIS_ADDR"`
+		RedisPassword  string                   `env:"RATE_LIMIT_REDIS_PASSWORD"`
+		Key            string                   `yaml:"key" env:"RATE_LIMIT_KEY"`
+		TrustedProxies []string                 `yaml:"trusted_proxies" env:"RATE_LIMIT_TRUSTED_PROXIES" env-separator:","`
+		Default        RateLimitRule            `yaml:"default"`
+		Routes         map[string]RateLimitRule `yaml:"routes"`
+	}
+
+	RateLimitRule struct {
+		Rate  float64 `yaml:"rate"`
+		Burst int     `yaml:"burst"`
+	}
+
+	Tracing struct {
+		Exporter    string  `yaml:"exporter" env:"TRACING_EXPORTER"`
+		Endpoint    string  `yaml:"endpoint" env:"TRACING_ENDPOINT"`
+		Insecure    bool    `yaml:"insecure" env:"TRACING_INSECURE"`
+		SampleRatio float64 `yaml:"sample_ratio" env:"TRACING_SAMPLE_RATIO"`
 	}
 
 	Logger struct {
@@ -34,18 +95,53 @@ type (
 		KibanaIndex string `yaml:"kibana_index" env:"LOGGER_KIBANA_INDEX"`
 	}
 
+	// Поля совпадают с postgres.Config. DSN, если задан, заменяет параметры подключения.
+	// Replicas -- реплики для чтения: DSN или host[:port], остальное берётся от основной базы.
 	Postgres struct {
-		Host     string `env:"POSTGRES_HOST"`
-		Port     string `env:"POSTGRES_PORT"`
-		DB       string `env:"POSTGRES_DB"`
-		User     string `env:"POSTGRES_USER"`
-		Password string `env:"POSTGRES_PASSWORD"`
+		DSN              string        `env:"POSTGRES_DSN"`
+		Host             string        `env:"POSTGRES_HOST"`
+		Port             string        `env:"POSTGRES_PORT"`
+		DB               string        `env:"POSTGRES_DB"`
+		User             string        `env:"POSTGRES_USER"`
+		Password         string        `env:"POSTGRES_PASSWORD"`
+		SSLMode          string        `yaml:"sslmode" env:"POSTGRES_SSLMODE"`
+		SSLRootCert      string        `yaml:"sslrootcert" env:"POSTGRES_SSLROOTCERT"`
+		ApplicationName  string        `yaml:"application_name" env:"POSTGRES_APPLICATION_NAME"`
+		StatementTimeout time.Duration `yaml:"statement_timeout" env:"POSTGRES_STATEMENT_TIMEOUT"`
+		MaxOpenConns     int           `yaml:"max_open_conns" env:"POSTGRES_MAX_OPEN_CONNS"`
+		MaxIdleConns     int           `yaml:"max_idle_conns" env:"POSTGRES_MAX_IDLE_CONNS"`
+		ConnMaxLifetime  time.Duration `yaml:"conn_max_lifetime" env:"POSTGRES_CONN_MAX_LIFETIME"`
+		ConnMaxIdleTime  time.Duration `yaml:"conn_max_idle_time" env:"POSTGRES_CONN_MAX_IDLE_TIME"`
+		StartupTimeout   time.Duration `yaml:"startup_timeout" env:"POSTGRES_STARTUP_TIMEOUT"`
+		Replicas         []string      `yaml:"replicas" env:"POSTGRES_REPLICAS" env-separator:","`
+		ReplicaCheck     time.Duration `yaml:"replica_check" env:"POSTGRES_REPLICA_CHECK"`
 	}
 
 	Webapi struct {
 		URL   string `env:"WEBAPI_URL"`
 		Token string `env:"WEBAPI_TOKEN"`
 	}
+
+	LinkChecker struct {
+		Enabled     bool          `yaml:"enabled" env:"LINK_CHECKER_ENABLED"`
+		Interval    time.Duration `yaml:"interval" env:"LINK_CHECKER_INTERVAL"`
+		Recheck     time.Duration `yaml:"recheck" env:"LINK_CHECKER_RECHECK"`
+		Concurrency int           `yaml:"concurrency" env:"LINK_CHECKER_CONCURRENCY"`
+		Batch       int           `yaml:"batch" env:"LINK_CHECKER_BATCH"`
+		Timeout     time.Duration `yaml:"timeout" env:"LINK_CHECKER_TIMEOUT"`
+	}
+
+	Plays struct {
+		Buffer   int           `yaml:"buffer" env:"PLAYS_BUFFER"`
+		Batch    int           `yaml:"batch" env:"PLAYS_BATCH"`
+		Interval time.Duration `yaml:"interval" env:"PLAYS_INTERVAL"`
+	}
+
+	Similar struct {
+		GroupWeight   float64 `yaml:"group_weight" env:"SIMILAR_GROUP_WEIGHT"`
+		ReleaseWeight float64 `yaml:"release_weight" env:"SIMILAR_RELEASE_WEIGHT"`
+		LyricsWeight  float64 `yaml:"lyrics_weight" env:"SIMILAR_LYRICS_WEIGHT"`
+	}
 )
 
 func New() (*Config, error) {
@@ -68,10 +164,13 @@ func New() (*Config, error) {
 	return cfg, nil
 }
 
+// ctxKey -- ключ конфига в контексте; сам Config ключом быть не может, в нём есть срезы.
+type ctxKey struct{}
+
 func ToContext(ctx context.Context, cfg *Config) context.Context {
-	return context.WithValue(ctx, Config{}, cfg)
+	return context.WithValue(ctx, ctxKey{}, cfg)
 }
 
 func FromContext(ctx context.Context) *Config {
-	return ctx.Value(Config{}).(*Config)
+	return ctx.Value(ctxKey{}).(*Config)
 }
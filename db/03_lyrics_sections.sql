@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS public.song_sections (
+    id SERIAL PRIMARY KEY,
+    song_id INT REFERENCES public.songs(id) ON DELETE CASCADE NOT NULL,
+    position INT NOT NULL,
+    kind VARCHAR(16) NOT NULL CHECK (kind IN ('verse', 'chorus', 'bridge', 'outro')),
+    label VARCHAR(255),
+    lines TEXT[] NOT NULL,
+    UNIQUE (song_id, position)
+);
+
+-- Каждый элемент существующего songs.text становится куплетом (verse),
+-- строки куплета -- это его части, разделённые переводом строки.
+INSERT INTO public.song_sections (song_id, position, kind, lines)
+SELECT s.id, t.position, 'verse', string_to_array(t.couplet, E'\n')
+FROM public.songs s, unnest(s.text) WITH ORDINALITY AS t(couplet, position)
+WHERE NOT EXISTS (SELECT 1 FROM public.song_sections sec WHERE sec.song_id = s.id);
@@ -0,0 +1,40 @@
+-- Полнотекстовый поиск по названию, группе и тексту песни.
+-- search_lang -- конфигурация текстового поиска (simple, english, russian, ...),
+-- с которой строится вектор песни.
+ALTER TABLE public.songs ADD COLUMN IF NOT EXISTS search_lang REGCONFIG NOT NULL DEFAULT 'simple';
+ALTER TABLE public.songs ADD COLUMN IF NOT EXISTS search TSVECTOR;
+
+CREATE OR REPLACE FUNCTION public.songs_search_vector(s public.songs) RETURNS TSVECTOR AS $$
+    SELECT setweight(to_tsvector(s.search_lang, coalesce(s.name, '')), 'A')
+        || setweight(to_tsvector(s.search_lang, coalesce((SELECT g.name FROM public.music_groups g WHERE g.id = s.group_id), '')), 'B')
+        || setweight(to_tsvector(s.search_lang, coalesce(array_to_string(s.text, ' '), '')), 'C');
+$$ LANGUAGE sql STABLE;
+
+CREATE OR REPLACE FUNCTION public.songs_search_update() RETURNS TRIGGER AS $$
+BEGIN
+    NEW.search := public.songs_search_vector(NEW);
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS songs_search_update ON public.songs;
+CREATE TRIGGER songs_search_update
+    BEFORE INSERT OR UPDATE OF name, group_id, text, search_lang ON public.songs
+    FOR EACH ROW EXECUTE FUNCTION public.songs_search_update();
+
+-- Переименование группы должно попасть в вектор её песен.
+CREATE OR REPLACE FUNCTION public.music_groups_search_update() RETURNS TRIGGER AS $$
+BEGIN
+    UPDATE public.songs SET search = public.songs_search_vector(songs) WHERE group_id = NEW.id;
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS music_groups_search_update ON public.music_groups;
+CREATE TRIGGER music_groups_search_update
+    AFTER UPDATE OF name ON public.music_groups
+    FOR EACH ROW EXECUTE FUNCTION public.music_groups_search_update();
+
+UPDATE public.songs SET search = public.songs_search_vector(songs);
+
+CREATE INDEX IF NOT EXISTS songs_search_idx ON public.songs USING gin (search);
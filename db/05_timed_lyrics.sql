@@ -0,0 +1,10 @@
+-- Синхронизированный текст (LRC): строки с временем начала в миллисекундах.
+CREATE TABLE IF NOT EXISTS public.song_timed_lines (
+    id SERIAL PRIMARY KEY,
+    song_id INT REFERENCES public.songs(id) ON DELETE CASCADE NOT NULL,
+    position INT NOT NULL,
+    time_ms INT NOT NULL CHECK (time_ms >= 0),
+    text TEXT NOT NULL,
+    UNIQUE (song_id, position)
+);
+CREATE INDEX ON public.song_timed_lines USING btree (song_id, time_ms);
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS public.albums (
+    id SERIAL PRIMARY KEY,
+    title VARCHAR(255) NOT NULL,
+    group_id INT REFERENCES public.music_groups(id) NOT NULL,
+    release_date VARCHAR(10),
+    cover_link VARCHAR(255),
+    deleted TIMESTAMP
+);
+CREATE INDEX ON public.albums USING btree (group_id, title);
+
+-- Песня входит максимум в один альбом.
+ALTER TABLE public.songs ADD COLUMN IF NOT EXISTS album_id INT REFERENCES public.albums(id);
+ALTER TABLE public.songs ADD COLUMN IF NOT EXISTS disc_number INT CHECK (disc_number > 0);
+ALTER TABLE public.songs ADD COLUMN IF NOT EXISTS track_number INT CHECK (track_number > 0);
+CREATE INDEX ON public.songs USING btree (album_id, disc_number, track_number);
@@ -0,0 +1,14 @@
+-- Несколько исполнителей у песни (feat., совместные работы, авторы).
+-- songs.group_id остаётся основным исполнителем и дублируется строкой с ролью primary.
+CREATE TABLE IF NOT EXISTS public.song_artists (
+    song_id INT REFERENCES public.songs(id) ON DELETE CASCADE NOT NULL,
+    group_id INT REFERENCES public.music_groups(id) NOT NULL,
+    role VARCHAR(16) NOT NULL CHECK (role IN ('primary', 'featured', 'composer', 'lyricist')),
+    position INT NOT NULL DEFAULT 0,
+    PRIMARY KEY (song_id, group_id, role)
+);
+CREATE INDEX ON public.song_artists USING btree (group_id);
+
+INSERT INTO public.song_artists (song_id, group_id, role)
+SELECT id, group_id, 'primary' FROM public.songs
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS public.playlists (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    description TEXT,
+    created TIMESTAMP NOT NULL DEFAULT NOW(),
+    deleted TIMESTAMP
+);
+
+-- Песня входит в плейлист не больше одного раза; позиции 1..N без пропусков.
+-- Уникальность позиции проверяется в конце транзакции, чтобы сдвигать записи одним UPDATE.
+CREATE TABLE IF NOT EXISTS public.playlist_entries (
+    playlist_id INT REFERENCES public.playlists(id) ON DELETE CASCADE NOT NULL,
+    song_id INT REFERENCES public.songs(id) NOT NULL,
+    position INT NOT NULL CHECK (position > 0),
+    added TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (playlist_id, song_id),
+    UNIQUE (playlist_id, position) DEFERRABLE INITIALLY DEFERRED
+);
@@ -0,0 +1,21 @@
+-- Жанры -- закрытый словарь; теги -- произвольные метки песни (в нижнем регистре).
+CREATE TABLE IF NOT EXISTS public.genres (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(64) NOT NULL UNIQUE
+);
+
+INSERT INTO public.genres (name) VALUES
+    ('blues'), ('classical'), ('country'), ('electronic'), ('folk'),
+    ('hip-hop'), ('indie'), ('jazz'), ('metal'), ('pop'),
+    ('punk'), ('r&b'), ('reggae'), ('rock'), ('soul')
+ON CONFLICT DO NOTHING;
+
+CREATE TABLE IF NOT EXISTS public.song_genres (
+    song_id INT REFERENCES public.songs(id) ON DELETE CASCADE NOT NULL,
+    genre_id INT REFERENCES public.genres(id) NOT NULL,
+    PRIMARY KEY (song_id, genre_id)
+);
+CREATE INDEX ON public.song_genres USING btree (genre_id);
+
+ALTER TABLE public.songs ADD COLUMN IF NOT EXISTS tags TEXT[] NOT NULL DEFAULT '{}';
+CREATE INDEX ON public.songs USING gin (tags);
@@ -0,0 +1,10 @@
+-- Язык оригинального текста песни (BCP-47); NULL -- язык не указан.
+ALTER TABLE public.songs ADD COLUMN IF NOT EXISTS lang VARCHAR(35);
+
+-- Переводы текста по куплетам; оригинал остаётся в songs.text.
+CREATE TABLE IF NOT EXISTS public.song_translations (
+    song_id INT REFERENCES public.songs(id) ON DELETE CASCADE NOT NULL,
+    lang VARCHAR(35) NOT NULL,
+    text TEXT[] NOT NULL,
+    PRIMARY KEY (song_id, lang)
+);
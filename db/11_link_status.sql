@@ -0,0 +1,7 @@
+-- Результат проверки ссылки фоновым link-checker: unchecked | ok | broken.
+ALTER TABLE public.songs ADD COLUMN IF NOT EXISTS link_status VARCHAR(16) NOT NULL DEFAULT 'unchecked'
+    CHECK (link_status IN ('unchecked', 'ok', 'broken'));
+ALTER TABLE public.songs ADD COLUMN IF NOT EXISTS link_status_code INT;
+ALTER TABLE public.songs ADD COLUMN IF NOT EXISTS link_checked TIMESTAMP;
+CREATE INDEX ON public.songs USING btree (link_status);
+CREATE INDEX ON public.songs USING btree (link_checked NULLS FIRST);
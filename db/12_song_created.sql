@@ -0,0 +1,3 @@
+-- Время добавления песни для статистики; у существующих песен -- время миграции.
+ALTER TABLE public.songs ADD COLUMN IF NOT EXISTS created TIMESTAMP NOT NULL DEFAULT NOW();
+CREATE INDEX ON public.songs USING btree (created DESC);
@@ -0,0 +1,9 @@
+-- Журнал прослушиваний и чтений текста (append-only): play -- явное прослушивание, read -- запрос текста.
+CREATE TABLE IF NOT EXISTS public.song_plays (
+    id BIGSERIAL PRIMARY KEY,
+    song_id INT REFERENCES public.songs(id) NOT NULL,
+    kind VARCHAR(8) NOT NULL CHECK (kind IN ('play', 'read')),
+    created TIMESTAMP NOT NULL DEFAULT NOW()
+);
+CREATE INDEX ON public.song_plays USING btree (created);
+CREATE INDEX ON public.song_plays USING btree (song_id, created);
@@ -0,0 +1,61 @@
+-- Конфигурация поиска песни выводится из языка её текста (songs.lang, BCP-47):
+-- по основному подтегу выбирается встроенная конфигурация, для прочих языков -- simple.
+CREATE OR REPLACE FUNCTION public.search_config(lang VARCHAR) RETURNS REGCONFIG AS $$
+    SELECT CASE lower(split_part(coalesce(lang, ''), '-', 1))
+        WHEN 'ar' THEN 'arabic'
+        WHEN 'hy' THEN 'armenian'
+        WHEN 'eu' THEN 'basque'
+        WHEN 'ca' THEN 'catalan'
+        WHEN 'da' THEN 'danish'
+        WHEN 'nl' THEN 'dutch'
+        WHEN 'en' THEN 'english'
+        WHEN 'fi' THEN 'finnish'
+        WHEN 'fr' THEN 'french'
+        WHEN 'de' THEN 'german'
+        WHEN 'el' THEN 'greek'
+        WHEN 'hi' THEN 'hindi'
+        WHEN 'hu' THEN 'hungarian'
+        WHEN 'id' THEN 'indonesian'
+        WHEN 'ga' THEN 'irish'
+        WHEN 'it' THEN 'italian'
+        WHEN 'lt' THEN 'lithuanian'
+        WHEN 'ne' THEN 'nepali'
+        WHEN 'no' THEN 'norwegian'
+        WHEN 'nb' THEN 'norwegian'
+        WHEN 'nn' THEN 'norwegian'
+        WHEN 'pt' THEN 'portuguese'
+        WHEN 'ro' THEN 'romanian'
+        WHEN 'ru' THEN 'russian'
+        WHEN 'sr' THEN 'serbian'
+        WHEN 'es' THEN 'spanish'
+        WHEN 'sv' THEN 'swedish'
+        WHEN 'ta' THEN 'tamil'
+        WHEN 'tr' THEN 'turkish'
+        WHEN 'yi' THEN 'yiddish'
+        ELSE 'simple'
+    END::regconfig;
+$$ LANGUAGE sql IMMUTABLE;
+
+-- Срабатывает раньше songs_search_update (триггеры идут по имени), поэтому вектор
+-- строится уже с новой конфигурацией.
+CREATE OR REPLACE FUNCTION public.songs_search_lang_update() RETURNS TRIGGER AS $$
+BEGIN
+    NEW.search_lang := public.search_config(NEW.lang);
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS songs_search_lang_update ON public.songs;
+CREATE TRIGGER songs_search_lang_update
+    BEFORE INSERT OR UPDATE OF lang ON public.songs
+    FOR EACH ROW EXECUTE FUNCTION public.songs_search_lang_update();
+
+-- Смена языка меняет search_lang внутри триггера, а не в UPDATE, поэтому lang
+-- нужно добавить в список колонок триггера вектора.
+DROP TRIGGER IF EXISTS songs_search_update ON public.songs;
+CREATE TRIGGER songs_search_update
+    BEFORE INSERT OR UPDATE OF name, group_id, text, lang, search_lang ON public.songs
+    FOR EACH ROW EXECUTE FUNCTION public.songs_search_update();
+
+UPDATE public.songs SET search_lang = public.search_config(lang)
+    WHERE search_lang <> public.search_config(lang);
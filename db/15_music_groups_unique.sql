@@ -0,0 +1,25 @@
+-- Имя группы уникально: CreateGroup делает upsert по имени, и параллельные
+-- создания песен одной новой группы не плодят дубликаты.
+-- Уже накопившиеся дубликаты сливаются в группу с наименьшим id.
+CREATE TEMP TABLE group_duplicates AS
+SELECT id, min(id) OVER (PARTITION BY "name") AS keep_id FROM public.music_groups;
+DELETE FROM group_duplicates WHERE id = keep_id;
+
+UPDATE public.songs s SET group_id = d.keep_id FROM group_duplicates d WHERE s.group_id = d.id;
+UPDATE public.albums a SET group_id = d.keep_id FROM group_duplicates d WHERE a.group_id = d.id;
+INSERT INTO public.song_artists (song_id, group_id, role, position)
+SELECT sa.song_id, d.keep_id, sa.role, sa.position FROM public.song_artists sa JOIN group_duplicates d ON d.id = sa.group_id
+ON CONFLICT DO NOTHING;
+DELETE FROM public.song_artists sa USING group_duplicates d WHERE sa.group_id = d.id;
+DELETE FROM public.music_groups g USING group_duplicates d WHERE g.id = d.id;
+DROP TABLE group_duplicates;
+
+CREATE UNIQUE INDEX IF NOT EXISTS music_groups_name_key ON public.music_groups USING btree (name);
+
+-- Upsert переписывает имя тем же значением; пересчитывать векторы песен нужно
+-- только при настоящем переименовании.
+DROP TRIGGER IF EXISTS music_groups_search_update ON public.music_groups;
+CREATE TRIGGER music_groups_search_update
+    AFTER UPDATE OF name ON public.music_groups
+    FOR EACH ROW WHEN (OLD.name IS DISTINCT FROM NEW.name)
+    EXECUTE FUNCTION public.music_groups_search_update();
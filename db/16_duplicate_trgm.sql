@@ -0,0 +1,11 @@
+-- Поиск дублей: нормализованное название (без пробелов по краям, с одиночными пробелами,
+-- в нижнем регистре) и триграммные индексы по нему, чтобы пары искались оператором %
+-- через индекс, а не перебором всех пар.
+CREATE OR REPLACE FUNCTION public.normalize_name(name TEXT) RETURNS TEXT AS $$
+    SELECT lower(regexp_replace(btrim(name), '\s+', ' ', 'g'));
+$$ LANGUAGE sql IMMUTABLE PARALLEL SAFE;
+
+CREATE INDEX IF NOT EXISTS songs_name_trgm_idx ON public.songs
+    USING gin (public.normalize_name(name) gin_trgm_ops) WHERE deleted IS NULL;
+CREATE INDEX IF NOT EXISTS music_groups_name_trgm_idx ON public.music_groups
+    USING gin (public.normalize_name(name) gin_trgm_ops);
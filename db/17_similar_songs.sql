@@ -0,0 +1,11 @@
+-- Похожие песни оцениваются не по всему каталогу, а по кандидатам: песни той же группы
+-- и песни с похожим текстом (оператор % по триграммному индексу).
+-- array_to_string только STABLE, а для text[] результат от настроек не зависит.
+CREATE OR REPLACE FUNCTION public.song_lyrics(text TEXT[]) RETURNS TEXT AS $$
+    SELECT array_to_string(text, ' ');
+$$ LANGUAGE sql IMMUTABLE PARALLEL SAFE;
+
+CREATE INDEX IF NOT EXISTS songs_lyrics_trgm_idx ON public.songs
+    USING gin (public.song_lyrics(text) gin_trgm_ops) WHERE deleted IS NULL;
+CREATE INDEX IF NOT EXISTS songs_group_id_idx ON public.songs
+    USING btree (group_id) WHERE deleted IS NULL;
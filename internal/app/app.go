@@ -2,18 +2,29 @@ package app
 
 import (
 	"context"
+	"net/http"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
+	"time"
 
 	"go-rest-api/config"
 	"go-rest-api/internal/composite"
+	"go-rest-api/internal/health"
+	"go-rest-api/internal/linkcheck"
+	"go-rest-api/internal/playtrack"
+	"go-rest-api/internal/ratelimit"
+	"go-rest-api/internal/transport/http/middleware"
 	http_v1_route "go-rest-api/internal/transport/http/v1/route"
 	http_server "go-rest-api/pkg/http-server"
 	"go-rest-api/pkg/logger"
 	"go-rest-api/pkg/postgres"
 
 	"github.com/julienschmidt/httprouter"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"go.uber.org/zap"
 )
 
@@ -21,19 +32,149 @@ func Run(ctx context.Context) {
 	logger := logger.FromContext(ctx)
 	cfg := config.FromContext(ctx)
 
-	pgClient, err := postgres.New((*postgres.Config)(&cfg.Postgres))
+	cluster, err := postgres.NewCluster(ctx, (*postgres.Config)(&cfg.Postgres))
 	if err != nil {
 		logger.Fatal("Error initialize DB", zap.Error(err))
 	}
+	pgClient := cluster.Primary().DB
 
-	composite := composite.New(ctx, pgClient)
+	// Пулы реплик различаются меткой db_name: <база>@<адрес реплики>.
+	prometheus.MustRegister(collectors.NewDBStatsCollector(pgClient, cfg.Postgres.DB))
+	for name, db := range cluster.Replicas() {
+		prometheus.MustRegister(collectors.NewDBStatsCollector(db, cfg.Postgres.DB+"@"+name))
+	}
+
+	composite := composite.New(ctx, cluster,
+		playtrack.Buffer(cfg.Plays.Buffer),
+		playtrack.Batch(cfg.Plays.Batch),
+		playtrack.Interval(cfg.Plays.Interval),
+	)
+	go composite.Plays.Run()
+
+	// Фоновые задачи останавливаются отменой workers; wg ждёт их завершения при остановке.
+	workers, stopWorkers := context.WithCancel(ctx)
+	defer stopWorkers()
+	var wg sync.WaitGroup
+
+	if cfg.LinkChecker.Enabled {
+		checker := linkcheck.New(workers, composite.Repo,
+			linkcheck.Interval(cfg.LinkChecker.Interval),
+			linkcheck.Recheck(cfg.LinkChecker.Recheck),
+			linkcheck.Concurrency(cfg.LinkChecker.Concurrency),
+			linkcheck.Batch(cfg.LinkChecker.Batch),
+			linkcheck.Timeout(cfg.LinkChecker.Timeout),
+		)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			checker.Run()
+		}()
+		logger.Info("Link checker started")
+	}
+
+	checks := []health.Option{
+		health.Timeout(cfg.Health.Timeout),
+		health.Dependency("postgres", pgClient.PingContext),
+	}
+	// Недоступная реплика не снимает готовность: чтения уходят на основную базу.
+	for name, db := range cluster.Replicas() {
+		checks = append(checks, health.Warning("postgres_replica:"+name, db.PingContext))
+	}
+	if cfg.Health.Webapi {
+		checks = append(checks, health.Dependency("webapi", composite.Webapi.Ping))
+	}
+	// Сертификаты загружаются до сервера, чтобы ошибку их перечитывания можно было показать в /readyz.
+	var certs *http_server.CertReloader
+	if cfg.HTTP.CertFile != "" {
+		certs, err = http_server.NewCertReloader(ctx, cfg.HTTP.CertFile, cfg.HTTP.KeyFile, cfg.HTTP.ClientCAFile)
+		if err != nil {
+			logger.Fatal("Failed to load TLS certificate", zap.Error(err))
+		}
+		checks = append(checks, health.Warning("tls", func(context.Context) error { return certs.Err() }))
+	}
+	checker := health.New(ctx, checks...)
 
 	router := httprouter.New()
+	http_v1_route.HealthRouteRegister(router, checker)
 	http_v1_route.SwaggerRouteRegister(ctx, router)
 	http_v1_route.MusicRouteRegister(ctx, router, composite)
+	http_v1_route.AlbumRouteRegister(ctx, router, composite)
+	http_v1_route.PlaylistRouteRegister(ctx, router, composite)
+	http_v1_route.AdminRouteRegister(ctx, router, composite)
+
+	actions := http.NewServeMux()
+	http_v1_route.ActionRouteRegister(ctx, actions, composite)
+	router.NotFound = actions
+
+	// Метрики отдаются без авторизации: их забирает Prometheus.
+	var metricsServer *http_server.Server
+	var metricsNotify <-chan error
+	if cfg.Metrics.Port == "" || cfg.Metrics.Port == cfg.HTTP.Port {
+		router.Handler(http.MethodGet, cfg.Metrics.Path, promhttp.Handler())
+	} else {
+		mux := http.NewServeMux()
+		mux.Handle("GET "+cfg.Metrics.Path, promhttp.Handler())
+		metricsServer = http_server.New(mux, http_server.Port(cfg.Metrics.Port))
+		metricsNotify = metricsServer.Notify()
+		logger.Info("Metrics server started", zap.String("port", cfg.Metrics.Port))
+	}
+
+	var handler http.Handler = router
+	var redisStore *ratelimit.RedisStore
+	if cfg.RateLimit.Enabled {
+		var store ratelimit.Store
+		switch cfg.RateLimit.Backend {
+		case "", "memory":
+			store = ratelimit.NewMemoryStore()
+		case "redis":
+			redisStore = ratelimit.NewRedisStore(cfg.RateLimit.RedisAddr, cfg.RateLimit.RedisPassword)
+			store = redisStore
+		default:
+			logger.Fatal("Unknown rate limit backend", zap.String("backend", cfg.RateLimit.Backend))
+		}
 
-	server := http_server.New(router, http_server.Port(cfg.HTTP.Port))
-	logger.Info("HTTP-server started")
+		proxies, err := middleware.ParseProxies(cfg.RateLimit.TrustedProxies)
+		if err != nil {
+			logger.Fatal("Invalid rate limit trusted proxies", zap.Error(err))
+		}
+		client := middleware.ClientIP(proxies)
+		if cfg.RateLimit.Key == "api_key" {
+			client = middleware.ClientAPIKey(cfg.App.ApiKey, client)
+		}
+
+		routes := make(map[string]ratelimit.Limit, len(cfg.RateLimit.Routes))
+		for route, rule := range cfg.RateLimit.Routes {
+			routes[route] = ratelimit.Limit(rule)
+		}
+		policy := ratelimit.NewPolicy(routes, ratelimit.Limit(cfg.RateLimit.Default))
+
+		handler = middleware.RateLimit(ctx, store, policy, client, router)
+		logger.Info("Rate limiter enabled", zap.String("backend", cfg.RateLimit.Backend), zap.String("key", cfg.RateLimit.Key))
+	}
+
+	// Серверные таймауты -- для обычных запросов; импорт, выгрузка и NDJSON-листинг
+	// продлевают дедлайны соединения в хендлерах (http.ResponseController).
+	serverOpts := []http_server.Option{
+		http_server.Port(cfg.HTTP.Port),
+		http_server.ReadTimeout(cfg.HTTP.ReadTimeout),
+		http_server.ReadHeaderTimeout(cfg.HTTP.ReadHeaderTimeout),
+		http_server.WriteTimeout(cfg.HTTP.WriteTimeout),
+		http_server.IdleTimeout(cfg.HTTP.IdleTimeout),
+		http_server.ShutdownTimeout(cfg.HTTP.ShutdownTimeout),
+		http_server.MaxHeaderBytes(cfg.HTTP.MaxHeaderBytes),
+	}
+	switch {
+	case certs != nil:
+		serverOpts = append(serverOpts, http_server.TLS(certs))
+	case cfg.HTTP.H2C:
+		serverOpts = append(serverOpts, http_server.H2C())
+	}
+
+	server := http_server.New(middleware.RequestID(ctx, handler), serverOpts...)
+	logger.Info("HTTP-server started",
+		zap.Bool("tls", cfg.HTTP.CertFile != ""),
+		zap.Bool("mtls", cfg.HTTP.CertFile != "" && cfg.HTTP.ClientCAFile != ""),
+		zap.Bool("h2c", cfg.HTTP.CertFile == "" && cfg.HTTP.H2C))
 
 	interrupt := make(chan os.Signal, 1)
 	signal.Notify(interrupt, os.Interrupt, syscall.SIGTERM)
@@ -43,6 +184,15 @@ func Run(ctx context.Context) {
 		logger.Info("Received interrupt signal", zap.String("signal", s.String()))
 	case err := <-server.Notify():
 		logger.Error("HTTP-server received error", zap.Error(err))
+	case err := <-metricsNotify:
+		logger.Error("Metrics server received error", zap.Error(err))
+	}
+
+	// Сначала /readyz начинает отвечать отказом, и только после паузы сервер перестаёт принимать запросы.
+	checker.Drain()
+	if cfg.Health.DrainDelay > 0 {
+		logger.Info("Draining traffic", zap.Duration("delay", cfg.Health.DrainDelay))
+		time.Sleep(cfg.Health.DrainDelay)
 	}
 
 	if err := server.Shutdown(ctx); err != nil {
@@ -50,4 +200,31 @@ func Run(ctx context.Context) {
 	} else {
 		logger.Info("HTTP-server stopped")
 	}
+
+	if metricsServer != nil {
+		if err := metricsServer.Shutdown(ctx); err != nil {
+			logger.Error("Metrics server shutdown error", zap.Error(err))
+		}
+	}
+
+	// Новых запросов больше нет: останавливаем фоновые задачи и дописываем прослушивания,
+	// и только потом закрываем соединения с хранилищами.
+	stopWorkers()
+	wg.Wait()
+	logger.Info("Background workers stopped")
+
+	composite.Plays.Close()
+	logger.Info("Play events flushed")
+
+	if redisStore != nil {
+		if err := redisStore.Close(); err != nil {
+			logger.Error("Rate limit store close error", zap.Error(err))
+		}
+	}
+
+	if err := cluster.Close(); err != nil {
+		logger.Error("DB close error", zap.Error(err))
+	} else {
+		logger.Info("DB connections closed")
+	}
 }
@@ -0,0 +1,189 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"go-rest-api/config"
+	"go-rest-api/internal/composite"
+	"go-rest-api/internal/entity"
+	"go-rest-api/internal/transport/catalog"
+	"go-rest-api/pkg/logger"
+	"go-rest-api/pkg/postgres"
+
+	"go.uber.org/zap"
+)
+
+// Exec выполняет подкоманду CLI вместо запуска HTTP-сервера:
+//
+//	go-rest-api import [-format ndjson|csv] [-enrich] [file]
+//	go-rest-api export [-format ndjson|csv] [-name ..] [-group ..] [-release_date ..] [-o file]
+func Exec(ctx context.Context, args []string) error {
+	cmd, args := args[0], args[1:]
+
+	switch cmd {
+	case "import":
+		return importCmd(ctx, args)
+	case "export":
+		return exportCmd(ctx, args)
+	default:
+		return fmt.Errorf("unknown command %q (expected import or export)", cmd)
+	}
+}
+
+func importCmd(ctx context.Context, args []string) error {
+	// Импорт читает то, что сам только что записал (группы, исполнители): все чтения после первой записи -- с основной базы.
+	ctx = postgres.WithSession(ctx)
+
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	format := fs.String("format", "", "batch format: ndjson | csv (default: by file extension)")
+	enrich := fs.Bool("enrich", false, "fill missing fields from the external service")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	in, name, err := openInput(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	if *format == "" {
+		*format = formatByExt(name)
+	}
+
+	reader, err := catalog.NewReader(*format, in)
+	if err != nil {
+		return err
+	}
+
+	c, closeDB, err := newComposite(ctx)
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	report, err := catalog.Import(reader, 0, func(row entity.ImportSong) (bool, error) {
+		return c.Usecase.ImportSong(ctx, row, *enrich)
+	})
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		return err
+	}
+
+	if report.Failed > 0 {
+		return fmt.Errorf("%d of %d rows failed", report.Failed, report.Total)
+	}
+	return nil
+}
+
+func exportCmd(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	format := fs.String("format", "", "export format: ndjson | csv (default: by file extension or ndjson)")
+	output := fs.String("o", "-", "output file, '-' for stdout")
+	name := fs.String("name", "", "song name")
+	group := fs.String("group", "", "song group")
+	releaseDate := fs.String("release_date", "", "song release date")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *format == "" {
+		*format = formatByExt(*output)
+	}
+
+	out := io.WriteCloser(os.Stdout)
+	if *output != "-" {
+		f, err := os.Create(*output)
+		if err != nil {
+			return err
+		}
+		out = f
+	}
+	defer out.Close()
+
+	writer, err := catalog.NewWriter(*format, out)
+	if err != nil {
+		return err
+	}
+
+	c, closeDB, err := newComposite(ctx)
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	filter := entity.FilterSong{}
+	if *name != "" {
+		filter.Name = name
+	}
+	if *group != "" {
+		filter.Group = group
+	}
+	if *releaseDate != "" {
+		filter.ReleaseDate = releaseDate
+	}
+
+	songs, err := c.Usecase.StreamSongs(ctx, filter)
+	if err != nil {
+		return err
+	}
+
+	for song, err := range songs {
+		if err != nil {
+			return err
+		}
+		if err := writer.Write(catalog.FromSong(song)); err != nil {
+			return err
+		}
+	}
+
+	return writer.Flush()
+}
+
+func newComposite(ctx context.Context) (*composite.Composite, func(), error) {
+	cfg := config.FromContext(ctx)
+
+	cluster, err := postgres.NewCluster(ctx, (*postgres.Config)(&cfg.Postgres))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	closeDB := func() {
+		if err := cluster.Close(); err != nil {
+			logger.FromContext(ctx).Error("Failed to close DB", zap.Error(err))
+		}
+	}
+
+	return composite.New(ctx, cluster), closeDB, nil
+}
+
+func openInput(name string) (io.ReadCloser, string, error) {
+	if name == "" || name == "-" {
+		return io.NopCloser(os.Stdin), "", nil
+	}
+
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, "", err
+	}
+	return f, name, nil
+}
+
+func formatByExt(name string) string {
+	if strings.EqualFold(filepath.Ext(name), ".csv") {
+		return catalog.FormatCSV
+	}
+	return catalog.FormatNDJSON
+}
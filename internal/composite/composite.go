@@ -2,29 +2,36 @@ package composite
 
 import (
 	"context"
-	"database/sql"
 
+	"go-rest-api/internal/playtrack"
 	"go-rest-api/internal/repo"
 	http_v1_handler "go-rest-api/internal/transport/http/v1/handler"
 	"go-rest-api/internal/usecase"
 	"go-rest-api/internal/webapi"
+	"go-rest-api/pkg/postgres"
 )
 
 type Composite struct {
 	*repo.Repo
 	*usecase.Usecase
 	*http_v1_handler.Handler
+
+	Plays  *playtrack.Recorder
+	Webapi *webapi.Webapi
 }
 
-func New(ctx context.Context, db *sql.DB) *Composite {
-	repo := repo.New(ctx, db)
+func New(ctx context.Context, cluster *postgres.Cluster, plays ...playtrack.Option) *Composite {
+	repo := repo.New(ctx, cluster)
 	webapi := webapi.New(ctx)
-	usecase := usecase.New(ctx, repo, webapi)
+	recorder := playtrack.New(ctx, repo, plays...)
+	usecase := usecase.New(ctx, repo, webapi, recorder)
 	handler := http_v1_handler.New(ctx, usecase)
 
 	return &Composite{
 		Repo:    repo,
 		Usecase: usecase,
 		Handler: handler,
+		Plays:   recorder,
+		Webapi:  webapi,
 	}
 }
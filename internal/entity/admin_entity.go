@@ -0,0 +1,32 @@
+package entity
+
+// Models -- handlers
+type (
+	// merge duplicates into the kept record
+	Merge struct {
+		Keep  int   `json:"keep" validate:"int"`
+		Merge []int `json:"merge" validate:"array"`
+	}
+)
+
+// Duplicate kinds
+const (
+	DuplicateSongs  = "songs"
+	DuplicateGroups = "groups"
+)
+
+// Models -- response
+type (
+	// pair of likely duplicates; the older record comes first
+	Duplicate struct {
+		First      DuplicateRecord `json:"first"`
+		Second     DuplicateRecord `json:"second"`
+		Similarity float64         `json:"similarity"`
+	}
+
+	DuplicateRecord struct {
+		ID    int    `json:"id"`
+		Name  string `json:"name"`
+		Group string `json:"group,omitempty"`
+	}
+)
@@ -0,0 +1,31 @@
+package entity
+
+// Models -- handlers
+type (
+	// add, update album
+	Album struct {
+		ID          *int    `json:"id,omitempty" validate:"int"`
+		Title       *string `json:"title" validate:"string"`
+		Group       *string `json:"group" validate:"string"`
+		ReleaseDate *string `json:"release_date,omitempty" validate:"string"`
+		CoverLink   *string `json:"cover_link,omitempty" validate:"string"`
+	}
+
+	// album track order
+	Track struct {
+		SongID      int    `json:"song_id" validate:"int"`
+		Name        string `json:"name,omitempty" validate:"string"`
+		DiscNumber  int    `json:"disc_number" validate:"int"`
+		TrackNumber int    `json:"track_number" validate:"int"`
+	}
+)
+
+// DTO -- repo (postgres)
+type (
+	AlbumDTO struct {
+		Title       *string
+		GroupID     *int
+		ReleaseDate *string
+		CoverLink   *string
+	}
+)
@@ -1,11 +1,23 @@
 package entity
 
+import "time"
+
 // Models -- handlers, webapi
 type (
 	// add new song
 	NewSong struct {
-		Group string `json:"group" validate:"string"`
-		Name  string `json:"song" validate:"string"`
+		Group   string   `json:"group" validate:"string"`
+		Name    string   `json:"song" validate:"string"`
+		Artists []Artist `json:"artists,omitempty" validate:"array"`
+		Genres  []string `json:"genres,omitempty" validate:"array"`
+		Tags    []string `json:"tags,omitempty" validate:"array"`
+		Lang    string   `json:"lang,omitempty" validate:"string"`
+	}
+
+	// song artist: primary, featured, composer, lyricist
+	Artist struct {
+		Name string `json:"name" validate:"string"`
+		Role string `json:"role" validate:"string"`
 	}
 
 	// get song detail from external api
@@ -13,25 +25,109 @@ type (
 		ReleaseDate string   `json:"releaseDate" validate:"string"`
 		Text        []string `json:"text" validate:"array"`
 		Link        string   `json:"link" validate:"string"`
+		Album       string   `json:"album,omitempty" validate:"string"`
+		DiscNumber  int      `json:"discNumber,omitempty" validate:"int"`
+		TrackNumber int      `json:"trackNumber,omitempty" validate:"int"`
 	}
 
 	// update, filtered song
 	Song struct {
-		Name        *string   `json:"name"  validate:"string"`
-		Group       *string   `json:"group" validate:"string"`
-		ReleaseDate *string   `json:"release_date" validate:"string"`
-		Text        *[]string `json:"text" validate:"array"`
-		Link        *string   `json:"link" validate:"string"`
+		ID          *int       `json:"id,omitempty" validate:"int"`
+		Name        *string    `json:"name"  validate:"string"`
+		Group       *string    `json:"group" validate:"string"`
+		Artists     *[]Artist  `json:"artists,omitempty" validate:"array"`
+		ReleaseDate *string    `json:"release_date" validate:"string"`
+		Text        *[]string  `json:"text" validate:"array"`
+		Link        *string    `json:"link" validate:"string"`
+		Sections    *[]Section `json:"sections,omitempty" validate:"array"`
+		Album       *string    `json:"album,omitempty" validate:"string"`
+		DiscNumber  *int       `json:"disc_number,omitempty" validate:"int"`
+		TrackNumber *int       `json:"track_number,omitempty" validate:"int"`
+		Genres      *[]string  `json:"genres,omitempty" validate:"array"`
+		Tags        *[]string  `json:"tags,omitempty" validate:"array"`
+		Lang        *string    `json:"lang,omitempty" validate:"string"`
+		LinkStatus  *string    `json:"link_status,omitempty" validate:"string"`
+		LinkChecked *string    `json:"link_checked,omitempty" validate:"string"`
+	}
+
+	// lyrics translation (BCP-47 language tag); the original text is marked
+	Translation struct {
+		Lang     string   `json:"lang" validate:"string"`
+		Original bool     `json:"original" validate:"bool"`
+		Text     []string `json:"text,omitempty" validate:"array"`
+	}
+
+	// synced lyrics line (LRC)
+	TimedLine struct {
+		TimeMS int    `json:"time_ms" validate:"int"`
+		Text   string `json:"text" validate:"string"`
+	}
+
+	// lyrics section: verse, chorus, bridge, outro
+	Section struct {
+		Type  string   `json:"type" validate:"string"`
+		Label string   `json:"label,omitempty" validate:"string"`
+		Lines []string `json:"lines" validate:"array"`
 	}
 
 	// filtered songs
 	FilterSong struct {
 		Name        *string `json:"name,omitempty"  validate:"string"`
 		Group       *string `json:"group,omitempty" validate:"string"`
+		Artist      *string `json:"artist,omitempty" validate:"string"`
 		ReleaseDate *string `json:"release_date,omitempty" validate:"string"`
+		Album       *string `json:"album,omitempty" validate:"string"`
+		AlbumID     *int    `json:"album_id,omitempty" validate:"int"`
+		Genre       *string `json:"genre,omitempty" validate:"string"`
+		Tag         *string `json:"tag,omitempty" validate:"string"`
+		LinkStatus  *string `json:"link_status,omitempty" validate:"string"`
+		Sort        *string `json:"sort,omitempty" validate:"string"`
+		Window      *string `json:"window,omitempty" validate:"string"`
+	}
+
+	// bulk import row
+	ImportSong struct {
+		Group       string   `json:"group" validate:"string"`
+		Name        string   `json:"song" validate:"string"`
+		ReleaseDate string   `json:"release_date,omitempty" validate:"string"`
+		Text        []string `json:"text,omitempty" validate:"array"`
+		Link        string   `json:"link,omitempty" validate:"string"`
+		Artists     []Artist `json:"artists,omitempty" validate:"array"`
+		Genres      []string `json:"genres,omitempty" validate:"array"`
+		Tags        []string `json:"tags,omitempty" validate:"array"`
+		Lang        string   `json:"lang,omitempty" validate:"string"`
 	}
 )
 
+// Lyrics section types
+const (
+	SectionVerse  = "verse"
+	SectionChorus = "chorus"
+	SectionBridge = "bridge"
+	SectionOutro  = "outro"
+)
+
+// Song artist roles
+const (
+	RolePrimary  = "primary"
+	RoleFeatured = "featured"
+	RoleComposer = "composer"
+	RoleLyricist = "lyricist"
+)
+
+// Song link statuses
+const (
+	LinkStatusUnchecked = "unchecked"
+	LinkStatusOK        = "ok"
+	LinkStatusBroken    = "broken"
+)
+
+// Song list sort orders
+const (
+	SortID         = "id"
+	SortPopularity = "popularity"
+)
+
 // Models -- response
 type (
 	Content struct {
@@ -42,7 +138,77 @@ type (
 	}
 
 	Couplet struct {
-		Text string `json:"text"`
+		Text     string `json:"text"`
+		Lang     string `json:"lang,omitempty"`
+		Original bool   `json:"original,omitempty"`
+	}
+
+	SearchResult struct {
+		Name        string  `json:"name"`
+		Group       string  `json:"group"`
+		ReleaseDate string  `json:"release_date"`
+		Rank        float64 `json:"rank"`
+		Headline    string  `json:"headline"`
+	}
+
+	// song counts per genre, tag, group and release year
+	SongFacets struct {
+		Genres []FacetCount `json:"genres"`
+		Tags   []FacetCount `json:"tags"`
+		Groups []FacetCount `json:"groups"`
+		Years  []FacetCount `json:"years"`
+	}
+
+	FacetCount struct {
+		Value string `json:"value"`
+		Count int    `json:"count"`
+	}
+
+	// song ranked by similarity to another song
+	SimilarSong struct {
+		Name        string  `json:"name"`
+		Group       string  `json:"group"`
+		ReleaseDate string  `json:"release_date"`
+		Score       float64 `json:"score"`
+	}
+
+	// catalog statistics
+	SongStats struct {
+		Songs         int          `json:"songs"`
+		AverageVerses float64      `json:"average_verses"`
+		Groups        []FacetCount `json:"groups"`
+		Years         []FacetCount `json:"years"`
+		Decades       []FacetCount `json:"decades"`
+		Recent        []RecentSong `json:"recent"`
+		Deleted       DeletedStats `json:"deleted"`
+	}
+
+	RecentSong struct {
+		Name    string `json:"name"`
+		Group   string `json:"group"`
+		Created string `json:"created"`
+	}
+
+	// soft-deleted entities
+	DeletedStats struct {
+		Songs     int `json:"songs"`
+		Albums    int `json:"albums"`
+		Playlists int `json:"playlists"`
+	}
+
+	ImportResult struct {
+		Row    int    `json:"row"`
+		Group  string `json:"group"`
+		Name   string `json:"song"`
+		Status string `json:"status"`
+		Error  string `json:"error,omitempty"`
+	}
+
+	ImportReport struct {
+		Total    int            `json:"total"`
+		Imported int            `json:"imported"`
+		Failed   int            `json:"failed"`
+		Results  []ImportResult `json:"results"`
 	}
 )
 
@@ -54,11 +220,49 @@ type (
 		ReleaseDate *string
 		Text        *[]string
 		Link        *string
+		Sections    *[]Section
+		TimedLines  *[]TimedLine
+		Artists     *[]ArtistDTO
+		AlbumID     *int
+		DiscNumber  *int
+		TrackNumber *int
+		Genres      *[]string
+		Tags        *[]string
+		Lang        *string
+	}
+
+	ArtistDTO struct {
+		GroupID int
+		Role    string
 	}
 
 	FilterSongDTO struct {
 		Name        *string
 		GroupID     *int
+		ArtistID    *int
 		ReleaseDate *string
+		Album       *string
+		AlbumID     *int
+		Genre       *string
+		Tag         *string
+		LinkStatus  *string
+		// сортировка по популярности за окно; nil -- по id
+		Popularity *time.Duration
+	}
+
+	// similarity score weights
+	SimilarWeights struct {
+		Group   float64
+		Release float64
+		Lyrics  float64
+	}
+
+	// song link check result
+	LinkCheck struct {
+		SongID     int
+		Link       string
+		Status     string
+		StatusCode int
+		CheckedAt  time.Time
 	}
 )
@@ -0,0 +1,32 @@
+package entity
+
+import "time"
+
+// Song play event kinds
+const (
+	PlayKindPlay = "play"
+	PlayKindRead = "read"
+)
+
+// Models -- response
+type (
+	// song ranked by plays and lyrics reads within a window
+	PopularSong struct {
+		Name        string `json:"name"`
+		Group       string `json:"group"`
+		ReleaseDate string `json:"release_date"`
+		Plays       int    `json:"plays"`
+		Reads       int    `json:"reads"`
+	}
+)
+
+// DTO -- repo (postgres)
+type (
+	// song play or lyrics read
+	PlayEvent struct {
+		SongID   int
+		SongName string
+		Kind     string
+		At       time.Time
+	}
+)
@@ -0,0 +1,35 @@
+package entity
+
+// Models -- handlers
+type (
+	// add, update playlist
+	Playlist struct {
+		ID          *int    `json:"id,omitempty" validate:"int"`
+		Name        *string `json:"name" validate:"string"`
+		Description *string `json:"description,omitempty" validate:"string"`
+		Size        *int    `json:"size,omitempty" validate:"int"`
+		Created     *string `json:"created,omitempty" validate:"string"`
+	}
+
+	// add song to playlist; position 0 -- to the end
+	NewPlaylistEntry struct {
+		SongID   int `json:"song_id" validate:"int"`
+		Position int `json:"position,omitempty" validate:"int"`
+	}
+
+	// move playlist entry
+	MovePlaylistEntry struct {
+		Position int `json:"position" validate:"int"`
+	}
+)
+
+// Models -- response
+type (
+	PlaylistEntry struct {
+		Position  int    `json:"position"`
+		SongID    int    `json:"song_id"`
+		Name      string `json:"name"`
+		Group     string `json:"group"`
+		Available bool   `json:"available"`
+	}
+)
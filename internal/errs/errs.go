@@ -6,8 +6,11 @@ var (
 	ErrBadRequest    = NewAppError(nil, "bad request")
 	ErrUnauthorized  = NewAppError(nil, "unauthorized")
 	ErrNotFound      = NewAppError(nil, "not found")
+	ErrConflict      = NewAppError(nil, "conflict")
 	ErrInternal      = NewAppError(nil, "internal server error")
 	ErrIncorrectBody = NewAppError(nil, "incorrect body")
+
+	ErrTooManyRequests = NewAppError(nil, "too many requests")
 )
 
 type AppError struct {
@@ -0,0 +1,144 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go-rest-api/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+const (
+	_defaultTimeout = 2 * time.Second
+
+	StatusOK       = "ok"
+	StatusWarn     = "warn"
+	StatusFail     = "fail"
+	StatusDraining = "draining"
+)
+
+type (
+	// CheckFunc проверяет доступность зависимости; nil -- зависимость доступна.
+	CheckFunc func(context.Context) error
+
+	Check struct {
+		Status     string `json:"status"`
+		DurationMs int64  `json:"duration_ms"`
+		Error      string `json:"error,omitempty"`
+	}
+
+	Report struct {
+		Status string           `json:"status"`
+		Checks map[string]Check `json:"checks,omitempty"`
+	}
+
+	dependency struct {
+		name  string
+		check CheckFunc
+		// warnOnly -- ошибка попадает в отчёт как warn, но готовность не снимает.
+		warnOnly bool
+	}
+
+	// Checker отвечает на пробы живости и готовности.
+	// Готовность проверяет зависимости и выключается при остановке сервиса (Drain).
+	Checker struct {
+		logger       *logger.Logger
+		dependencies []dependency
+		timeout      time.Duration
+		draining     atomic.Bool
+	}
+)
+
+func New(ctx context.Context, opts ...Option) *Checker {
+	c := &Checker{
+		logger:  logger.FromContext(ctx),
+		timeout: _defaultTimeout,
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// Drain переводит готовность в отказ, чтобы балансировщик перестал слать запросы до остановки сервера.
+func (c *Checker) Drain() {
+	c.draining.Store(true)
+}
+
+// Live -- процесс жив и обслуживает HTTP.
+func (c *Checker) Live(w http.ResponseWriter, r *http.Request) {
+	respond(w, http.StatusOK, Report{Status: StatusOK})
+}
+
+// Ready -- все зависимости отвечают за отведённое время.
+func (c *Checker) Ready(w http.ResponseWriter, r *http.Request) {
+	if c.draining.Load() {
+		respond(w, http.StatusServiceUnavailable, Report{Status: StatusDraining})
+		return
+	}
+
+	report := c.check(r.Context())
+	for name, check := range report.Checks {
+		if check.Status == StatusWarn {
+			c.logger.Warn("Readiness check warning", zap.String("check", name), zap.String("error", check.Error))
+		}
+	}
+	if report.Status != StatusOK {
+		c.logger.Warn("Readiness check failed", zap.Any("checks", report.Checks))
+		respond(w, http.StatusServiceUnavailable, report)
+		return
+	}
+	respond(w, http.StatusOK, report)
+}
+
+// check опрашивает зависимости параллельно, каждую со своим таймаутом.
+func (c *Checker) check(ctx context.Context) Report {
+	report := Report{Status: StatusOK, Checks: make(map[string]Check, len(c.dependencies))}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, dep := range c.dependencies {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			ctx, cancel := context.WithTimeout(ctx, c.timeout)
+			defer cancel()
+
+			start := time.Now()
+			err := dep.check(ctx)
+			check := Check{Status: StatusOK, DurationMs: time.Since(start).Milliseconds()}
+			if err != nil {
+				check.Status = StatusFail
+				if dep.warnOnly {
+					check.Status = StatusWarn
+				}
+				check.Error = err.Error()
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Checks[dep.name] = check
+			if check.Status == StatusFail {
+				report.Status = StatusFail
+			}
+		}()
+	}
+	wg.Wait()
+
+	return report
+}
+
+func respond(w http.ResponseWriter, status int, report Report) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(report)
+}
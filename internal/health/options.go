@@ -0,0 +1,29 @@
+package health
+
+import "time"
+
+type Option func(*Checker)
+
+// Timeout -- сколько ждать ответа каждой зависимости.
+func Timeout(timeout time.Duration) Option {
+	return func(c *Checker) {
+		if timeout > 0 {
+			c.timeout = timeout
+		}
+	}
+}
+
+// Dependency добавляет зависимость в проверку готовности.
+func Dependency(name string, check CheckFunc) Option {
+	return func(c *Checker) {
+		c.dependencies = append(c.dependencies, dependency{name: name, check: check})
+	}
+}
+
+// Warning добавляет проверку, ошибка которой видна в отчёте готовности (warn), но не снимает её:
+// например, сервис продолжает работать на прежнем TLS-сертификате, не сумев перечитать новый.
+func Warning(name string, check CheckFunc) Option {
+	return func(c *Checker) {
+		c.dependencies = append(c.dependencies, dependency{name: name, check: check, warnOnly: true})
+	}
+}
@@ -0,0 +1,191 @@
+package linkcheck
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"go-rest-api/internal/entity"
+	"go-rest-api/pkg/logger"
+	"go-rest-api/pkg/netguard"
+
+	"go.uber.org/zap"
+)
+
+const (
+	_defaultInterval    = time.Hour
+	_defaultRecheck     = 24 * time.Hour
+	_defaultConcurrency = 4
+	_defaultBatch       = 100
+	_defaultTimeout     = 10 * time.Second
+
+	_userAgent = "go-rest-api link-checker"
+)
+
+type Repo interface {
+	GetLinksToCheck(context.Context, time.Time, int) ([]entity.LinkCheck, error)
+	SaveLinkChecks(context.Context, []entity.LinkCheck) error
+}
+
+// Checker в фоне проверяет ссылки песен HEAD-запросами и записывает их статус.
+type Checker struct {
+	ctx    context.Context
+	logger *logger.Logger
+	repo   Repo
+	client *http.Client
+
+	interval    time.Duration
+	recheck     time.Duration
+	concurrency int
+	batch       int
+}
+
+func New(ctx context.Context, repo Repo, opts ...Option) *Checker {
+	c := &Checker{
+		ctx:         ctx,
+		logger:      logger.FromContext(ctx),
+		repo:        repo,
+		client:      newClient(),
+		interval:    _defaultInterval,
+		recheck:     _defaultRecheck,
+		concurrency: _defaultConcurrency,
+		batch:       _defaultBatch,
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// Run проверяет устаревшие ссылки раз в interval, пока не отменён ctx.
+func (c *Checker) Run() {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		checked, err := c.CheckStale()
+		if err != nil {
+			c.logger.Error("Link check failed", zap.Error(err))
+		} else {
+			c.logger.Info("Links checked", zap.Int("count", checked))
+		}
+
+		select {
+		case <-c.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// CheckStale проверяет партиями все ссылки, не проверявшиеся дольше recheck,
+// и возвращает число проверенных ссылок; или возвращает ошибку.
+func (c *Checker) CheckStale() (int, error) {
+	before := time.Now().Add(-c.recheck)
+	checked := 0
+
+	for c.ctx.Err() == nil {
+		links, err := c.repo.GetLinksToCheck(c.ctx, before, c.batch)
+		if err != nil {
+			return checked, err
+		}
+		if len(links) == 0 {
+			break
+		}
+
+		if err := c.repo.SaveLinkChecks(c.ctx, c.Check(links)); err != nil {
+			return checked, err
+		}
+		checked += len(links)
+
+		if len(links) < c.batch {
+			break
+		}
+	}
+
+	return checked, nil
+}
+
+// Check проверяет ссылки, выполняя не больше concurrency запросов одновременно,
+// и возвращает их с заполненными статусом и временем проверки.
+func (c *Checker) Check(links []entity.LinkCheck) []entity.LinkCheck {
+	sem := make(chan struct{}, c.concurrency)
+	var wg sync.WaitGroup
+
+	for i := range links {
+		wg.Add(1)
+		sem <- struct{}{}
+
+		go func(link *entity.LinkCheck) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			c.check(link)
+		}(&links[i])
+	}
+
+	wg.Wait()
+	return links
+}
+
+/*
+check проверяет одну ссылку:
+- ok -- ответ 2xx или 3xx (редиректы клиент проходит сам)
+- broken -- ответ 4xx, 5xx или ошибка сети (код 0)
+
+Заметки:
+1. Если сервер не поддерживает HEAD (405, 501), ссылка проверяется GET-запросом без чтения тела.
+*/
+func (c *Checker) check(link *entity.LinkCheck) {
+	code, err := c.request(http.MethodHead, link.Link)
+	if err == nil && (code == http.StatusMethodNotAllowed || code == http.StatusNotImplemented) {
+		code, err = c.request(http.MethodGet, link.Link)
+	}
+
+	link.CheckedAt = time.Now()
+	link.StatusCode = code
+	link.Status = entity.LinkStatusOK
+
+	if err != nil || code >= http.StatusBadRequest {
+		c.logger.Debug("Broken link", zap.Int("song_id", link.SongID), zap.String("link", link.Link), zap.Int("status_code", code), zap.Error(err))
+		link.Status = entity.LinkStatusBroken
+	}
+}
+
+// newClient -- клиент, который не ходит во внутреннюю сеть: ссылки задают пользователи,
+// и без этого фоновая проверка превращалась бы в SSRF (в том числе через редиректы).
+func newClient() *http.Client {
+	dialer := &net.Dialer{Timeout: _defaultTimeout, Control: netguard.Control}
+
+	return &http.Client{
+		Timeout: _defaultTimeout,
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: _defaultTimeout,
+			MaxIdleConns:        100,
+			IdleConnTimeout:     90 * time.Second,
+		},
+	}
+}
+
+func (c *Checker) request(method, link string) (int, error) {
+	req, err := http.NewRequestWithContext(c.ctx, method, link, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("User-Agent", _userAgent)
+
+	res, err := c.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(res.Body, 4096))
+
+	return res.StatusCode, nil
+}
@@ -0,0 +1,136 @@
+package linkcheck
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go-rest-api/internal/entity"
+)
+
+func newServer(t *testing.T, handler http.HandlerFunc) *httptest.Server {
+	t.Helper()
+
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestCheck(t *testing.T) {
+	srv := newServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ok":
+			w.WriteHeader(http.StatusOK)
+		case "/redirect":
+			http.Redirect(w, r, "/ok", http.StatusFound)
+		case "/no-head":
+			if r.Method == http.MethodHead {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		case "/error":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+
+	tests := []struct {
+		name   string
+		path   string
+		status string
+		code   int
+	}{
+		{"ok", "/ok", entity.LinkStatusOK, http.StatusOK},
+		{"redirect", "/redirect", entity.LinkStatusOK, http.StatusOK},
+		{"head not allowed falls back to get", "/no-head", entity.LinkStatusOK, http.StatusOK},
+		{"not found", "/missing", entity.LinkStatusBroken, http.StatusNotFound},
+		{"server error", "/error", entity.LinkStatusBroken, http.StatusInternalServerError},
+	}
+
+	c := New(context.Background(), nil, Client(srv.Client()))
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			links := c.Check([]entity.LinkCheck{{SongID: 1, Link: srv.URL + tt.path}})
+
+			if links[0].Status != tt.status || links[0].StatusCode != tt.code {
+				t.Errorf("got %s (%d), want %s (%d)", links[0].Status, links[0].StatusCode, tt.status, tt.code)
+			}
+			if links[0].CheckedAt.IsZero() {
+				t.Error("checked_at is not set")
+			}
+		})
+	}
+}
+
+func TestCheckNetworkError(t *testing.T) {
+	srv := newServer(t, func(w http.ResponseWriter, r *http.Request) {})
+	link := srv.URL
+	srv.Close()
+
+	c := New(context.Background(), nil, Client(srv.Client()))
+	links := c.Check([]entity.LinkCheck{{SongID: 1, Link: link}})
+
+	if links[0].Status != entity.LinkStatusBroken || links[0].StatusCode != 0 {
+		t.Errorf("got %s (%d), want %s (0)", links[0].Status, links[0].StatusCode, entity.LinkStatusBroken)
+	}
+}
+
+func TestCheckConcurrency(t *testing.T) {
+	const concurrency = 3
+
+	var inFlight, peak atomic.Int32
+	srv := newServer(t, func(w http.ResponseWriter, r *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+	})
+
+	links := make([]entity.LinkCheck, 4*concurrency)
+	for i := range links {
+		links[i] = entity.LinkCheck{SongID: i + 1, Link: srv.URL}
+	}
+
+	c := New(context.Background(), nil, Client(srv.Client()), Concurrency(concurrency))
+	for _, link := range c.Check(links) {
+		if link.Status != entity.LinkStatusOK {
+			t.Fatalf("song %d: got %s, want %s", link.SongID, link.Status, entity.LinkStatusOK)
+		}
+	}
+
+	if got := peak.Load(); got > concurrency {
+		t.Errorf("peak concurrency %d, want at most %d", got, concurrency)
+	}
+	if got := peak.Load(); got < 2 {
+		t.Errorf("peak concurrency %d, links were checked sequentially", got)
+	}
+}
+
+func TestDefaultClientBlocksInternalAddresses(t *testing.T) {
+	var hits atomic.Int32
+	srv := newServer(t, func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+	})
+
+	c := New(context.Background(), nil)
+	links := c.Check([]entity.LinkCheck{{SongID: 1, Link: srv.URL}})
+
+	if links[0].Status != entity.LinkStatusBroken {
+		t.Errorf("got %s, want %s", links[0].Status, entity.LinkStatusBroken)
+	}
+	if hits.Load() != 0 {
+		t.Error("request reached a loopback server")
+	}
+}
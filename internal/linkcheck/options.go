@@ -0,0 +1,62 @@
+package linkcheck
+
+import (
+	"net/http"
+	"time"
+)
+
+type Option func(*Checker)
+
+// Interval -- пауза между проходами проверки.
+func Interval(interval time.Duration) Option {
+	return func(c *Checker) {
+		if interval > 0 {
+			c.interval = interval
+		}
+	}
+}
+
+// Recheck -- через сколько времени ссылку нужно проверить повторно.
+func Recheck(recheck time.Duration) Option {
+	return func(c *Checker) {
+		if recheck > 0 {
+			c.recheck = recheck
+		}
+	}
+}
+
+// Concurrency -- сколько ссылок проверяется одновременно.
+func Concurrency(concurrency int) Option {
+	return func(c *Checker) {
+		if concurrency > 0 {
+			c.concurrency = concurrency
+		}
+	}
+}
+
+// Batch -- сколько ссылок читается из хранилища за раз.
+func Batch(batch int) Option {
+	return func(c *Checker) {
+		if batch > 0 {
+			c.batch = batch
+		}
+	}
+}
+
+// Timeout -- таймаут одного запроса к ссылке.
+func Timeout(timeout time.Duration) Option {
+	return func(c *Checker) {
+		if timeout > 0 {
+			c.client.Timeout = timeout
+		}
+	}
+}
+
+// Client подменяет HTTP-клиент, например, клиентом httptest-сервера.
+func Client(client *http.Client) Option {
+	return func(c *Checker) {
+		if client != nil {
+			c.client = client
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package playtrack
+
+import "time"
+
+type Option func(*Recorder)
+
+// Buffer -- сколько событий может ждать записи; сверх этого события отбрасываются.
+func Buffer(buffer int) Option {
+	return func(r *Recorder) {
+		if buffer > 0 {
+			r.buffer = buffer
+		}
+	}
+}
+
+// Batch -- сколько событий записывается одним запросом.
+func Batch(batch int) Option {
+	return func(r *Recorder) {
+		if batch > 0 {
+			r.batch = batch
+		}
+	}
+}
+
+// Interval -- как часто записывать неполную партию.
+func Interval(interval time.Duration) Option {
+	return func(r *Recorder) {
+		if interval > 0 {
+			r.interval = interval
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package playtrack
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go-rest-api/internal/entity"
+	"go-rest-api/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+const (
+	_defaultBuffer   = 1024
+	_defaultBatch    = 100
+	_defaultInterval = 5 * time.Second
+)
+
+type Repo interface {
+	SavePlays(context.Context, []entity.PlayEvent) error
+}
+
+// Recorder копит события прослушиваний и чтений в памяти и в фоне пишет их
+// в хранилище партиями, не задерживая обработку запросов.
+type Recorder struct {
+	ctx    context.Context
+	logger *logger.Logger
+	repo   Repo
+
+	events chan entity.PlayEvent
+	stop   chan struct{}
+	done   chan struct{}
+	once   sync.Once
+
+	buffer   int
+	batch    int
+	interval time.Duration
+}
+
+func New(ctx context.Context, repo Repo, opts ...Option) *Recorder {
+	r := &Recorder{
+		ctx:      ctx,
+		logger:   logger.FromContext(ctx),
+		repo:     repo,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+		buffer:   _defaultBuffer,
+		batch:    _defaultBatch,
+		interval: _defaultInterval,
+	}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	r.events = make(chan entity.PlayEvent, r.buffer)
+
+	return r
+}
+
+// Record ставит событие в очередь на запись. Если очередь переполнена,
+// событие отбрасывается -- запрос не ждёт хранилище.
+func (r *Recorder) Record(event entity.PlayEvent) bool {
+	select {
+	case r.events <- event:
+		return true
+	default:
+		r.logger.Warn("Play events buffer is full, event dropped", zap.String("song_name", event.SongName), zap.String("kind", event.Kind))
+		return false
+	}
+}
+
+// Run пишет накопленные события, когда набралась партия или прошёл interval.
+// Работает, пока не отменён ctx или не вызван Close; оставшиеся в очереди события при этом дописываются.
+func (r *Recorder) Run() {
+	defer close(r.done)
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	batch := make([]entity.PlayEvent, 0, r.batch)
+	for {
+		select {
+		case event := <-r.events:
+			batch = append(batch, event)
+			if len(batch) >= r.batch {
+				batch = r.flush(r.ctx, batch)
+			}
+		case <-ticker.C:
+			batch = r.flush(r.ctx, batch)
+		case <-r.ctx.Done():
+			r.drain(batch)
+			return
+		case <-r.stop:
+			r.drain(batch)
+			return
+		}
+	}
+}
+
+// Close останавливает Run и ждёт, пока будут записаны оставшиеся события.
+func (r *Recorder) Close() {
+	r.once.Do(func() { close(r.stop) })
+	<-r.done
+}
+
+// drain дописывает партию и всё, что осталось в очереди, даже если ctx уже отменён.
+func (r *Recorder) drain(batch []entity.PlayEvent) {
+	ctx := context.WithoutCancel(r.ctx)
+
+	for {
+		select {
+		case event := <-r.events:
+			batch = append(batch, event)
+			if len(batch) >= r.batch {
+				batch = r.flush(ctx, batch)
+			}
+		default:
+			r.flush(ctx, batch)
+			return
+		}
+	}
+}
+
+// flush записывает партию и возвращает её пустой для повторного использования.
+// При ошибке хранилища события партии теряются: статистика прослушиваний не критична.
+func (r *Recorder) flush(ctx context.Context, batch []entity.PlayEvent) []entity.PlayEvent {
+	if len(batch) == 0 {
+		return batch
+	}
+
+	if err := r.repo.SavePlays(ctx, batch); err != nil {
+		r.logger.Error("Play events save failed", zap.Int("count", len(batch)), zap.Error(err))
+	} else {
+		r.logger.Debug("Play events saved", zap.Int("count", len(batch)))
+	}
+
+	return batch[:0]
+}
@@ -0,0 +1,102 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// clock -- управляемое время для MemoryStore.
+type clock struct{ t time.Time }
+
+func (c *clock) now() time.Time          { return c.t }
+func (c *clock) advance(d time.Duration) { c.t = c.t.Add(d) }
+func newTestStore() (*MemoryStore, *clock) {
+	c := &clock{t: time.Now()}
+	s := NewMemoryStore()
+	s.now = c.now
+	return s, c
+}
+
+func take(t *testing.T, s *MemoryStore, key string, limit Limit) Result {
+	t.Helper()
+
+	res, err := s.Take(context.Background(), key, limit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res
+}
+
+func TestMemoryStoreBurst(t *testing.T) {
+	s, _ := newTestStore()
+	limit := Limit{Rate: 1, Burst: 3}
+
+	for i := range 3 {
+		res := take(t, s, "k", limit)
+		if !res.Allowed {
+			t.Fatalf("request %d rejected within burst", i+1)
+		}
+		if res.Remaining != 2-i || res.Limit != 3 {
+			t.Errorf("request %d: got remaining %d of %d, want %d of 3", i+1, res.Remaining, res.Limit, 2-i)
+		}
+	}
+
+	res := take(t, s, "k", limit)
+	if res.Allowed {
+		t.Fatal("request over burst allowed")
+	}
+	if res.RetryAfter != time.Second || res.Reset != 3*time.Second {
+		t.Errorf("got retry after %s, reset %s, want 1s, 3s", res.RetryAfter, res.Reset)
+	}
+
+	if !take(t, s, "other", limit).Allowed {
+		t.Error("another key shares the bucket")
+	}
+}
+
+func TestMemoryStoreRefill(t *testing.T) {
+	s, c := newTestStore()
+	limit := Limit{Rate: 2, Burst: 2}
+
+	take(t, s, "k", limit)
+	take(t, s, "k", limit)
+	if take(t, s, "k", limit).Allowed {
+		t.Fatal("empty bucket allowed a request")
+	}
+
+	c.advance(500 * time.Millisecond)
+	if !take(t, s, "k", limit).Allowed {
+		t.Fatal("token not refilled after 1/rate")
+	}
+	if take(t, s, "k", limit).Allowed {
+		t.Fatal("refill gave more than one token")
+	}
+
+	// Долгий простой наполняет корзину не больше, чем до burst.
+	c.advance(time.Hour)
+	for i := range 2 {
+		if !take(t, s, "k", limit).Allowed {
+			t.Fatalf("request %d rejected after refill", i+1)
+		}
+	}
+	if take(t, s, "k", limit).Allowed {
+		t.Error("bucket refilled above burst")
+	}
+}
+
+func TestMemoryStoreSweep(t *testing.T) {
+	s, c := newTestStore()
+	limit := Limit{Rate: 1, Burst: 1}
+
+	take(t, s, "idle", limit)
+	c.advance(2 * _sweepInterval)
+	take(t, s, "active", limit)
+
+	if _, ok := s.buckets["idle"]; ok {
+		t.Error("full bucket was not swept")
+	}
+	if _, ok := s.buckets["active"]; !ok {
+		t.Error("bucket in use was swept")
+	}
+}
@@ -0,0 +1,152 @@
+package ratelimit
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+)
+
+type (
+	// Limit -- параметры token bucket: Rate токенов в секунду, не больше Burst в запасе.
+	// Rate <= 0 -- без ограничений.
+	Limit struct {
+		Rate  float64
+		Burst int
+	}
+
+	// Result -- итог попытки взять токен.
+	// RetryAfter -- через сколько появится следующий токен (если запрос отклонён),
+	// Reset -- через сколько корзина наполнится целиком.
+	Result struct {
+		Allowed    bool
+		Limit      int
+		Remaining  int
+		RetryAfter time.Duration
+		Reset      time.Duration
+	}
+
+	// Store хранит корзины; key уже включает маршрут и клиента.
+	Store interface {
+		Take(ctx context.Context, key string, limit Limit) (Result, error)
+	}
+
+	Route struct {
+		Method  string
+		Pattern string
+		Limit   Limit
+	}
+
+	// Policy выбирает лимит для запроса: первый подходящий маршрут или Default для путей /api/.
+	Policy struct {
+		Routes  []Route
+		Default Limit
+	}
+)
+
+func (l Limit) Unlimited() bool {
+	return l.Rate <= 0
+}
+
+func (l Limit) burst() int {
+	if l.Burst < 1 {
+		return 1
+	}
+	return l.Burst
+}
+
+// NewPolicy собирает политику из ключей вида "POST /api/v1/songs". Маршруты с меньшим числом
+// параметров проверяются первыми, чтобы /songs/popular не перекрывался /songs/:name.
+func NewPolicy(routes map[string]Limit, def Limit) *Policy {
+	p := &Policy{Default: def}
+	for route, limit := range routes {
+		p.Routes = append(p.Routes, ParseRoute(route, limit))
+	}
+
+	sort.Slice(p.Routes, func(i, j int) bool {
+		pi, pj := params(p.Routes[i].Pattern), params(p.Routes[j].Pattern)
+		if pi != pj {
+			return pi < pj
+		}
+		return p.Routes[i].Pattern+p.Routes[i].Method < p.Routes[j].Pattern+p.Routes[j].Method
+	})
+
+	return p
+}
+
+// ParseRoute разбирает ключ вида "POST /api/v1/songs"; без метода маршрут подходит для любого.
+func ParseRoute(route string, limit Limit) Route {
+	method, pattern, ok := strings.Cut(strings.TrimSpace(route), " ")
+	if !ok {
+		return Route{Pattern: method, Limit: limit}
+	}
+	return Route{Method: strings.ToUpper(method), Pattern: strings.TrimSpace(pattern), Limit: limit}
+}
+
+// Match возвращает имя маршрута (для ключа корзины) и его лимит.
+// Пробы, метрики и swagger под Default не попадают.
+func (p *Policy) Match(method, path string) (string, Limit, bool) {
+	for _, route := range p.Routes {
+		if route.Method != "" && route.Method != method {
+			continue
+		}
+		if matchPattern(route.Pattern, path) {
+			return route.Method + " " + route.Pattern, route.Limit, true
+		}
+	}
+
+	if strings.HasPrefix(path, "/api/") && !p.Default.Unlimited() {
+		return "default", p.Default, true
+	}
+	return "", Limit{}, false
+}
+
+// matchPattern сравнивает путь с шаблоном в синтаксисе httprouter: ":name" -- один сегмент,
+// "*name" -- остаток пути.
+func matchPattern(pattern, path string) bool {
+	ps := strings.Split(strings.Trim(pattern, "/"), "/")
+	segs := strings.Split(strings.Trim(path, "/"), "/")
+
+	for i, p := range ps {
+		if strings.HasPrefix(p, "*") {
+			return true
+		}
+		if i >= len(segs) {
+			return false
+		}
+		if strings.HasPrefix(p, ":") {
+			continue
+		}
+		if p != segs[i] {
+			return false
+		}
+	}
+	return len(ps) == len(segs)
+}
+
+// result считает заголовки по остатку токенов после попытки.
+func result(allowed bool, tokens float64, limit Limit) Result {
+	burst := limit.burst()
+
+	res := Result{
+		Allowed:   allowed,
+		Limit:     burst,
+		Remaining: int(tokens),
+		Reset:     seconds((float64(burst) - tokens) / limit.Rate),
+	}
+	if !allowed {
+		res.RetryAfter = seconds((1 - tokens) / limit.Rate)
+	}
+	return res
+}
+
+func seconds(s float64) time.Duration {
+	if s <= 0 {
+		return 0
+	}
+	return time.Duration(s * float64(time.Second))
+}
+
+func params(pattern string) int {
+	return strings.Count(pattern, "/:") + strings.Count(pattern, "/*")
+}
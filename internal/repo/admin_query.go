@@ -0,0 +1,83 @@
+package repo
+
+// Названия сравниваются нормализованными (normalize_name): без пробелов по краям, с одиночными пробелами
+// и в нижнем регистре; сходство -- триграммное (pg_trgm), у совпадающих названий оно равно 1.
+// Пары ищутся оператором % по триграммному индексу, поэтому перед запросами порог
+// ставится в pg_trgm.similarity_threshold (на время транзакции).
+const (
+	querySetDuplicateThreshold = "SELECT set_config('pg_trgm.similarity_threshold', $1::text, true);"
+
+	// Песни сравниваются только в пределах одной группы.
+	queryDuplicateSongPairs = "WITH pairs AS (SELECT a.id AS a_id, a.\"name\" AS a_name, b.id AS b_id, b.\"name\" AS b_name, a.group_id," +
+		" similarity(normalize_name(a.\"name\"), normalize_name(b.\"name\")) AS sim" +
+		" FROM songs a JOIN songs b ON normalize_name(b.\"name\") % normalize_name(a.\"name\")" +
+		" AND b.group_id = a.group_id AND b.id > a.id AND b.deleted IS NULL" +
+		" WHERE a.deleted IS NULL)"
+
+	queryCountDuplicateSongs = queryDuplicateSongPairs + " SELECT COUNT(*) FROM pairs WHERE sim >= $1;"
+
+	queryGetDuplicateSongs = queryDuplicateSongPairs +
+		" SELECT p.a_id, p.a_name, p.b_id, p.b_name, g.\"name\", p.sim::FLOAT8 FROM pairs p JOIN music_groups g ON g.id = p.group_id" +
+		" WHERE p.sim >= $1 ORDER BY p.sim DESC, p.a_id, p.b_id LIMIT $2 OFFSET $3;"
+
+	queryDuplicateGroupPairs = "WITH pairs AS (SELECT a.id AS a_id, a.\"name\" AS a_name, b.id AS b_id, b.\"name\" AS b_name," +
+		" similarity(normalize_name(a.\"name\"), normalize_name(b.\"name\")) AS sim" +
+		" FROM music_groups a JOIN music_groups b ON normalize_name(b.\"name\") % normalize_name(a.\"name\") AND b.id > a.id)"
+
+	queryCountDuplicateGroups = queryDuplicateGroupPairs + " SELECT COUNT(*) FROM pairs WHERE sim >= $1;"
+
+	queryGetDuplicateGroups = queryDuplicateGroupPairs +
+		" SELECT a_id, a_name, b_id, b_name, '', sim::FLOAT8 FROM pairs" +
+		" WHERE sim >= $1 ORDER BY sim DESC, a_id, b_id LIMIT $2 OFFSET $3;"
+)
+
+// Слияние песен: $1 -- остающаяся песня, $2 -- сливаемые.
+const (
+	queryLockMergeSongs = "SELECT COUNT(*) FROM (SELECT id FROM songs WHERE (id = $1 OR id = ANY($2)) AND deleted IS NULL FOR UPDATE) l;"
+
+	// В плейлисте остаётся одна запись из сливаемых -- самая ранняя.
+	queryMergeDeletePlaylistEntries = "DELETE FROM playlist_entries e USING (SELECT playlist_id, song_id," +
+		" row_number() OVER (PARTITION BY playlist_id ORDER BY position) AS rn FROM playlist_entries WHERE song_id = $1 OR song_id = ANY($2)) d" +
+		" WHERE e.playlist_id = d.playlist_id AND e.song_id = d.song_id AND d.rn > 1;"
+
+	queryMergePlaylistEntries = "UPDATE playlist_entries SET song_id = $1 WHERE song_id = ANY($2);"
+
+	// Позиции плейлистов с остающейся песней снова идут 1..N без пропусков.
+	queryMergeRenumberPlaylists = "UPDATE playlist_entries e SET position = r.rn FROM (SELECT playlist_id, song_id," +
+		" row_number() OVER (PARTITION BY playlist_id ORDER BY position) AS rn FROM playlist_entries" +
+		" WHERE playlist_id IN (SELECT playlist_id FROM playlist_entries WHERE song_id = $1 OR song_id = ANY($2))) r" +
+		" WHERE e.playlist_id = r.playlist_id AND e.song_id = r.song_id AND e.position <> r.rn;"
+
+	queryMergePlays = "UPDATE song_plays SET song_id = $1 WHERE song_id = ANY($2);"
+
+	// Основной исполнитель остаётся у песни прежним, остальные исполнители объединяются.
+	queryMergeSongArtists = "INSERT INTO song_artists (song_id, group_id, role, position)" +
+		" SELECT $1, group_id, role, position FROM song_artists WHERE song_id = ANY($2) AND role <> 'primary' ON CONFLICT DO NOTHING;"
+
+	queryMergeSongGenres = "INSERT INTO song_genres (song_id, genre_id) SELECT $1, genre_id FROM song_genres WHERE song_id = ANY($2) ON CONFLICT DO NOTHING;"
+
+	// Переводы, которых нет у остающейся песни, берутся у самой старой из сливаемых.
+	queryMergeSongTranslations = "INSERT INTO song_translations (song_id, lang, \"text\")" +
+		" SELECT DISTINCT ON (lang) $1, lang, \"text\" FROM song_translations WHERE song_id = ANY($2) ORDER BY lang, song_id ON CONFLICT DO NOTHING;"
+
+	queryMergeSongTags = "UPDATE songs SET tags = ARRAY(SELECT DISTINCT t FROM songs s, unnest(s.tags) AS t WHERE s.id = $1 OR s.id = ANY($2) ORDER BY t) WHERE id = $1;"
+
+	queryMergeDeleteSongs = "UPDATE songs SET deleted = NOW() WHERE id = ANY($2) AND id <> $1 AND deleted IS NULL;"
+)
+
+// Слияние групп: $1 -- остающаяся группа, $2 -- сливаемые.
+const (
+	queryLockMergeGroups = "SELECT COUNT(*) FROM (SELECT id FROM music_groups WHERE id = $1 OR id = ANY($2) FOR UPDATE) l;"
+
+	queryMergeGroupSongs = "UPDATE songs SET group_id = $1 WHERE group_id = ANY($2);"
+
+	queryMergeGroupAlbums = "UPDATE albums SET group_id = $1 WHERE group_id = ANY($2);"
+
+	queryMergeGroupArtists = "INSERT INTO song_artists (song_id, group_id, role, position)" +
+		" SELECT song_id, $1, role, position FROM song_artists WHERE group_id = ANY($2) ON CONFLICT DO NOTHING;"
+
+	queryMergeDeleteGroupArtists = "DELETE FROM song_artists WHERE group_id = ANY($2) AND group_id <> $1;"
+
+	// У групп нет мягкого удаления: после переноса ссылок сливаемые группы удаляются.
+	queryMergeDeleteGroups = "DELETE FROM music_groups WHERE id = ANY($2) AND id <> $1;"
+)
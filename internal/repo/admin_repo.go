@@ -0,0 +1,137 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"go-rest-api/internal/entity"
+
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+)
+
+// GetDuplicates возвращает страницу пар вероятных дублей песен или групп со сходством не меньше threshold
+// и общее число таких пар; или возвращает ошибку.
+func (r *Repo) GetDuplicates(ctx context.Context, kind string, threshold float64, limit, offset int) (duplicates []entity.Duplicate, total int, err error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	countQuery, selectQuery := queryCountDuplicateSongs, queryGetDuplicateSongs
+	if kind == entity.DuplicateGroups {
+		countQuery, selectQuery = queryCountDuplicateGroups, queryGetDuplicateGroups
+	}
+
+	// Порог оператора % действует только внутри транзакции и не утекает в другие запросы пула.
+	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		r.log(ctx).Debug("Can't begin transaction", zap.Error(err))
+		return nil, 0, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, querySetDuplicateThreshold, threshold); err != nil {
+		r.log(ctx).Debug("Execute sql request error", zap.Error(err))
+		return nil, 0, err
+	}
+
+	if err = tx.QueryRowContext(ctx, countQuery, threshold).Scan(&total); err != nil {
+		r.log(ctx).Debug("Execute sql request error", zap.Error(err))
+		return nil, 0, err
+	}
+	if total == 0 {
+		return nil, 0, nil
+	}
+
+	rows, err := tx.QueryContext(ctx, selectQuery, threshold, limit, offset)
+	if err != nil {
+		r.log(ctx).Debug("Execute sql request error", zap.Error(err))
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var d entity.Duplicate
+		var group string
+		if err := rows.Scan(&d.First.ID, &d.First.Name, &d.Second.ID, &d.Second.Name, &group, &d.Similarity); err != nil {
+			r.log(ctx).Debug("Rows scan error", zap.Error(err))
+			return nil, 0, err
+		}
+		d.First.Group, d.Second.Group = group, group
+		duplicates = append(duplicates, d)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.log(ctx).Debug("Can't parse rows", zap.Error(err))
+		return nil, 0, err
+	}
+
+	return duplicates, total, nil
+}
+
+// MergeSongs сливает песни merge в песню keep в одной транзакции: ссылки на сливаемые песни
+// переносятся, а сами они удаляются. Возвращает false, если какой-то из песен нет; или возвращает ошибку.
+func (r *Repo) MergeSongs(ctx context.Context, keep int, merge []int) (bool, error) {
+	return r.merge(ctx, queryLockMergeSongs, []string{
+		queryMergeDeletePlaylistEntries,
+		queryMergePlaylistEntries,
+		queryMergeRenumberPlaylists,
+		queryMergePlays,
+		queryMergeSongArtists,
+		queryMergeSongGenres,
+		queryMergeSongTranslations,
+		queryMergeSongTags,
+		queryMergeDeleteSongs,
+	}, keep, merge)
+}
+
+// MergeGroups сливает группы merge в группу keep в одной транзакции: песни, альбомы и исполнители
+// переносятся, а сами группы удаляются. Возвращает false, если какой-то из групп нет; или возвращает ошибку.
+func (r *Repo) MergeGroups(ctx context.Context, keep int, merge []int) (bool, error) {
+	return r.merge(ctx, queryLockMergeGroups, []string{
+		queryMergeGroupSongs,
+		queryMergeGroupAlbums,
+		queryMergeGroupArtists,
+		queryMergeDeleteGroupArtists,
+		queryMergeDeleteGroups,
+	}, keep, merge)
+}
+
+// merge блокирует записи запросом lock и по порядку выполняет запросы слияния с аргументами (keep, merge).
+func (r *Repo) merge(ctx context.Context, lock string, queries []string, keep int, merge []int) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		r.log(ctx).Debug("Can't begin transaction", zap.Error(err))
+		return false, err
+	}
+	defer tx.Rollback()
+
+	ids := pq.Array(merge)
+
+	var locked int
+	if err := tx.QueryRowContext(ctx, lock, keep, ids).Scan(&locked); err != nil {
+		r.log(ctx).Debug("Execute sql request error", zap.Error(err))
+		return false, err
+	}
+	if locked != len(merge)+1 {
+		r.log(ctx).Debug("Merge records not found", zap.Int("keep", keep), zap.Ints("merge", merge))
+		return false, nil
+	}
+
+	for _, query := range queries {
+		if _, err := tx.ExecContext(ctx, query, keep, ids); err != nil {
+			r.log(ctx).Debug("Can't update field in table", zap.Error(err))
+			return false, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		r.log(ctx).Debug("Can't commit transaction", zap.Error(err))
+		return false, err
+	}
+
+	return true, nil
+}
@@ -0,0 +1,74 @@
+package repo
+
+import (
+	"fmt"
+	"strings"
+
+	"go-rest-api/internal/entity"
+)
+
+const (
+	queryFindAlbumID = "SELECT id FROM albums WHERE group_id = $1 AND title = $2 AND deleted IS NULL ORDER BY id LIMIT 1;"
+
+	queryCreateAlbum = "INSERT INTO albums (title, group_id, release_date, cover_link) VALUES ($1, $2, $3, $4) RETURNING id;"
+
+	queryGetAlbum = "SELECT a.id, a.title, g.\"name\", a.release_date, a.cover_link FROM albums a JOIN music_groups g ON g.id = a.group_id WHERE a.id = $1 AND a.deleted IS NULL;"
+
+	queryDeleteAlbum = "UPDATE albums SET deleted = NOW() WHERE id = $1 AND deleted IS NULL;"
+
+	queryGetAlbumTracks = "SELECT id, \"name\", disc_number, track_number FROM songs WHERE album_id = $1 AND deleted IS NULL ORDER BY disc_number, track_number, id;"
+
+	queryClearAlbumTracks = "UPDATE songs SET album_id = NULL, disc_number = NULL, track_number = NULL WHERE album_id = $1;"
+
+	querySetAlbumTrack = "UPDATE songs SET album_id = $1, disc_number = $2, track_number = $3 WHERE id = $4 AND deleted IS NULL;"
+)
+
+func (r *Repo) queryUpdateAlbum(album entity.AlbumDTO, id int) (string, []interface{}) {
+	var str []string
+	var args []interface{}
+	argIndex := 1
+
+	if album.Title != nil {
+		str = append(str, fmt.Sprintf("title = $%d", argIndex))
+		args = append(args, *album.Title)
+		argIndex++
+	}
+	if album.GroupID != nil {
+		str = append(str, fmt.Sprintf("group_id = $%d", argIndex))
+		args = append(args, *album.GroupID)
+		argIndex++
+	}
+	if album.ReleaseDate != nil {
+		str = append(str, fmt.Sprintf("release_date = $%d", argIndex))
+		args = append(args, *album.ReleaseDate)
+		argIndex++
+	}
+	if album.CoverLink != nil {
+		str = append(str, fmt.Sprintf("cover_link = $%d", argIndex))
+		args = append(args, *album.CoverLink)
+		argIndex++
+	}
+
+	if len(str) == 0 {
+		return "", nil
+	}
+
+	args = append(args, id)
+	return "UPDATE albums SET " + strings.Join(str, ", ") + fmt.Sprintf(" WHERE id = $%d AND deleted IS NULL;", argIndex), args
+}
+
+func (r *Repo) queryGetAlbums(groupID *int, limit, offset int) (string, string, []interface{}) {
+	from := " FROM albums a JOIN music_groups g ON g.id = a.group_id WHERE a.deleted IS NULL"
+	var args []interface{}
+
+	if groupID != nil {
+		from += " AND a.group_id = $1"
+		args = append(args, *groupID)
+	}
+
+	countQuery := "SELECT COUNT(*)" + from + ";"
+	selectQuery := "SELECT a.id, a.title, g.\"name\", a.release_date, a.cover_link" + from +
+		fmt.Sprintf(" ORDER BY a.id LIMIT %d OFFSET %d;", limit, offset)
+
+	return countQuery, selectQuery, args
+}
@@ -0,0 +1,286 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"go-rest-api/internal/entity"
+	"go-rest-api/internal/errs"
+
+	"go.uber.org/zap"
+)
+
+// FindAlbumID возвращает album id по группе и названию (0 -- если альбома нет) или ошибку.
+func (r *Repo) FindAlbumID(ctx context.Context, groupID int, title string) (id int, err error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	err = r.db.QueryRowContext(ctx, queryFindAlbumID, groupID, title).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		r.log(ctx).Debug("Request did not return value")
+		return 0, nil
+	}
+	if err != nil {
+		r.log(ctx).Debug("Execute sql request error", zap.Error(err))
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// CreateAlbum создаёт альбом и возвращает id; или возвращает ошибку.
+func (r *Repo) CreateAlbum(ctx context.Context, album entity.AlbumDTO) (id int, err error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if album.ReleaseDate != nil {
+		if err := isDate(*album.ReleaseDate); err != nil {
+			r.log(ctx).Debug("Wrong date format", zap.Error(err))
+			return 0, errs.ErrBadRequest
+		}
+	}
+
+	err = r.db.QueryRowContext(
+		ctx,
+		queryCreateAlbum,
+		album.Title,
+		album.GroupID,
+		album.ReleaseDate,
+		album.CoverLink,
+	).Scan(&id)
+	if err != nil {
+		r.log(ctx).Debug("Can't insert into DB", zap.Error(err))
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// GetAlbum возвращает альбом по id (nil -- если альбома нет) или ошибку.
+func (r *Repo) GetAlbum(ctx context.Context, id int) (*entity.Album, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	album, err := scanAlbum(r.db.QueryRowContext(ctx, queryGetAlbum, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		r.log(ctx).Debug("Request did not return value")
+		return nil, nil
+	}
+	if err != nil {
+		r.log(ctx).Debug("Execute sql request error", zap.Error(err))
+		return nil, err
+	}
+
+	return &album, nil
+}
+
+// GetAlbums возвращает страницу альбомов (опционально -- одной группы) и их общее число; или возвращает ошибку.
+func (r *Repo) GetAlbums(ctx context.Context, groupID *int, limit, offset int) (albums []entity.Album, total int, err error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	countQuery, selectQuery, args := r.queryGetAlbums(groupID, limit, offset)
+
+	if err = r.db.QueryRowContext(ctx, countQuery, args...).Scan(&total); err != nil {
+		r.log(ctx).Debug("Execute sql request error", zap.Error(err))
+		return nil, 0, err
+	}
+	if total == 0 {
+		return nil, 0, nil
+	}
+
+	rows, err := r.db.QueryContext(ctx, selectQuery, args...)
+	if err != nil {
+		r.log(ctx).Debug("Execute sql request error", zap.Error(err))
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		album, err := scanAlbum(rows)
+		if err != nil {
+			r.log(ctx).Debug("Rows scan error", zap.Error(err))
+			return nil, 0, err
+		}
+		albums = append(albums, album)
+	}
+
+	if err = rows.Err(); err != nil {
+		r.log(ctx).Debug("Can't parse rows", zap.Error(err))
+		return nil, 0, err
+	}
+
+	return albums, total, nil
+}
+
+// UpdateAlbum обновляет альбом по id и возвращает bool; или возвращает ошибку.
+func (r *Repo) UpdateAlbum(ctx context.Context, id int, album entity.AlbumDTO) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if album.ReleaseDate != nil {
+		if err := isDate(*album.ReleaseDate); err != nil {
+			r.log(ctx).Debug("Wrong date format", zap.Error(err))
+			return false, errs.ErrBadRequest
+		}
+	}
+
+	query, args := r.queryUpdateAlbum(album, id)
+	if query == "" {
+		errMsg := "query is empty"
+		r.log(ctx).Debug("Incorrect query", zap.Error(fmt.Errorf("%v", errMsg)))
+		return false, errs.ErrBadRequest
+	}
+
+	res, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		r.log(ctx).Debug("Can't update field in table", zap.Error(err))
+		return false, err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		r.log(ctx).Debug("Failed to get rows affected", zap.Error(err))
+		return false, err
+	}
+
+	if rows == 0 {
+		r.log(ctx).Debug("Album is not exist", zap.Int("album_id", id))
+	}
+
+	return rows > 0, nil
+}
+
+// DeleteAlbum помечает альбом удалённым и возвращает bool; или возвращает ошибку.
+func (r *Repo) DeleteAlbum(ctx context.Context, id int) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	res, err := r.db.ExecContext(ctx, queryDeleteAlbum, id)
+	if err != nil {
+		r.log(ctx).Debug("Can't update field in table", zap.Error(err))
+		return false, err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		r.log(ctx).Debug("Failed to get rows affected", zap.Error(err))
+		return false, err
+	}
+
+	if rows == 0 {
+		r.log(ctx).Debug("Album is not exist", zap.Int("album_id", id))
+	}
+
+	return rows > 0, nil
+}
+
+// GetAlbumTracks возвращает треки альбома в порядке диска и номера трека или ошибку.
+func (r *Repo) GetAlbumTracks(ctx context.Context, id int) ([]entity.Track, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, queryGetAlbumTracks, id)
+	if err != nil {
+		r.log(ctx).Debug("Execute sql request error", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	tracks := []entity.Track{}
+	for rows.Next() {
+		var track entity.Track
+		var disc, number sql.NullInt64
+
+		if err := rows.Scan(&track.SongID, &track.Name, &disc, &number); err != nil {
+			r.log(ctx).Debug("Rows scan error", zap.Error(err))
+			return nil, err
+		}
+		track.DiscNumber = int(disc.Int64)
+		track.TrackNumber = int(number.Int64)
+
+		tracks = append(tracks, track)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.log(ctx).Debug("Can't parse rows", zap.Error(err))
+		return nil, err
+	}
+
+	return tracks, nil
+}
+
+// SetAlbumTracks заменяет состав и порядок треков альбома; песни, не попавшие в список,
+// из альбома исключаются. Если какой-то песни нет, возвращает bad request.
+func (r *Repo) SetAlbumTracks(ctx context.Context, id int, tracks []entity.Track) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		r.log(ctx).Debug("Can't begin transaction", zap.Error(err))
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, queryClearAlbumTracks, id); err != nil {
+		r.log(ctx).Debug("Can't clear album tracks", zap.Error(err))
+		return err
+	}
+
+	for _, track := range tracks {
+		res, err := tx.ExecContext(ctx, querySetAlbumTrack, id, track.DiscNumber, track.TrackNumber, track.SongID)
+		if err != nil {
+			r.log(ctx).Debug("Can't set album track", zap.Error(err))
+			return err
+		}
+
+		rows, err := res.RowsAffected()
+		if err != nil {
+			r.log(ctx).Debug("Failed to get rows affected", zap.Error(err))
+			return err
+		}
+		if rows == 0 {
+			r.log(ctx).Debug("Song is not exist", zap.Int("song_id", track.SongID))
+			return errs.ErrBadRequest
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		r.log(ctx).Debug("Can't commit transaction", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanAlbum(row rowScanner) (entity.Album, error) {
+	var id int
+	var title, group string
+	var releaseDate, coverLink sql.NullString
+
+	if err := row.Scan(&id, &title, &group, &releaseDate, &coverLink); err != nil {
+		return entity.Album{}, err
+	}
+
+	album := entity.Album{
+		ID:    &id,
+		Title: &title,
+		Group: &group,
+	}
+	if releaseDate.Valid {
+		album.ReleaseDate = &releaseDate.String
+	}
+	if coverLink.Valid {
+		album.CoverLink = &coverLink.String
+	}
+
+	return album, nil
+}
@@ -5,20 +5,89 @@ import (
 	"strings"
 
 	"go-rest-api/internal/entity"
+
+	"github.com/lib/pq"
 )
 
 const (
 	queryFindGroupID = "SELECT id FROM music_groups WHERE \"name\" = $1;"
 
-	queryFindGroupName = "SELECT \"name\" FROM music_groups WHERE id = $1;"
-
-	queryCreateGroup = "INSERT INTO music_groups (\"name\") VALUES ($1) RETURNING id;"
+	// Upsert: при гонке двух созданий одной группы обе получают id одной записи.
+	queryCreateGroup = "INSERT INTO music_groups (\"name\") VALUES ($1)" +
+		" ON CONFLICT (\"name\") DO UPDATE SET \"name\" = EXCLUDED.\"name\" RETURNING id;"
 
-	querySaveNewSong = "INSERT INTO songs (\"name\", group_id, release_date, \"text\", \"link\") VALUES ($1, $2, $3, $4, $5);"
+	querySaveNewSong = "INSERT INTO songs (\"name\", group_id, release_date, \"text\", \"link\", album_id, disc_number, track_number, tags, lang) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NULLIF($10, '')) RETURNING id;"
 
 	queryDeleteSong = "UPDATE songs SET deleted = NOW() WHERE \"name\" = $1 AND deleted IS NULL;"
 
 	queryGetSongText = "SELECT \"text\" FROM songs WHERE \"name\" = $1 AND deleted IS NULL;"
+
+	queryFindSongID = "SELECT id FROM songs WHERE \"name\" = $1 AND deleted IS NULL ORDER BY id LIMIT 1;"
+
+	queryDeleteSections = "DELETE FROM song_sections WHERE song_id = $1;"
+
+	querySaveSection = "INSERT INTO song_sections (song_id, position, kind, label, lines) VALUES ($1, $2, $3, NULLIF($4, ''), $5);"
+
+	queryGetSections = "SELECT kind, COALESCE(label, ''), lines FROM song_sections WHERE song_id = $1 ORDER BY position;"
+
+	queryDeleteTimedLines = "DELETE FROM song_timed_lines WHERE song_id = $1;"
+
+	querySaveTimedLine = "INSERT INTO song_timed_lines (song_id, position, time_ms, \"text\") VALUES ($1, $2, $3, $4);"
+
+	queryGetTimedLines = "SELECT time_ms, \"text\" FROM song_timed_lines WHERE song_id = $1 ORDER BY position;"
+
+	queryDeleteArtists = "DELETE FROM song_artists WHERE song_id = $1;"
+
+	queryDeleteMainArtist = "DELETE FROM song_artists WHERE song_id = $1 AND role = 'primary' AND position = 0;"
+
+	querySaveArtist = "INSERT INTO song_artists (song_id, group_id, role, position) VALUES ($1, $2, $3, $4) ON CONFLICT DO NOTHING;"
+
+	queryDeleteGenres = "DELETE FROM song_genres WHERE song_id = $1;"
+
+	// Неизвестный жанр не вставит строку -- это проверяется по RowsAffected.
+	querySaveGenre = "INSERT INTO song_genres (song_id, genre_id) SELECT $1, id FROM genres WHERE \"name\" = $2 ON CONFLICT DO NOTHING;"
+
+	queryGetGenres = "SELECT \"name\" FROM genres ORDER BY \"name\";"
+
+	queryGetSongLang = "SELECT id, COALESCE(lang, '') FROM songs WHERE \"name\" = $1 AND deleted IS NULL ORDER BY id LIMIT 1;"
+
+	queryGetTranslationLangs = "SELECT lang FROM song_translations WHERE song_id = $1 ORDER BY lang;"
+
+	queryGetTranslation = "SELECT \"text\" FROM song_translations WHERE song_id = $1 AND lang = $2;"
+
+	querySaveTranslation = "INSERT INTO song_translations (song_id, lang, \"text\") VALUES ($1, $2, $3) ON CONFLICT (song_id, lang) DO UPDATE SET \"text\" = EXCLUDED.\"text\";"
+
+	queryDeleteTranslation = "DELETE FROM song_translations WHERE song_id = $1 AND lang = $2;"
+
+	// Сначала ни разу не проверенные, затем самые давние.
+	queryGetLinksToCheck = "SELECT id, \"link\" FROM songs WHERE deleted IS NULL AND \"link\" <> '' AND (link_checked IS NULL OR link_checked < $1) ORDER BY link_checked NULLS FIRST, id LIMIT $2;"
+
+	// Если ссылку успели поменять, результат проверки старой ссылки не записывается.
+	querySaveLinkCheck = "UPDATE songs SET link_status = $3, link_status_code = NULLIF($4, 0), link_checked = $5 WHERE id = $1 AND \"link\" = $2;"
+
+	queryGetDeletedStats = "SELECT (SELECT COUNT(*) FROM songs WHERE deleted IS NOT NULL), (SELECT COUNT(*) FROM albums WHERE deleted IS NOT NULL), (SELECT COUNT(*) FROM playlists WHERE deleted IS NOT NULL);"
+
+	// Кандидаты -- песни той же группы и песни с похожим текстом (% по триграммному индексу);
+	// остальной каталог не оценивается. Оценка -- взвешенная сумма: та же группа (0 или 1),
+	// близость даты выпуска (1 / (1 + разница в годах)) и триграммное сходство текстов (pg_trgm).
+	// Песни с нулевой оценкой не выдаются и не считаются.
+	queryScoredSimilarSongs = "WITH src AS (SELECT id, group_id, to_date(release_date, 'DD.MM.YYYY') AS released, song_lyrics(\"text\") AS lyrics" +
+		" FROM songs WHERE \"name\" = $1 AND deleted IS NULL ORDER BY id LIMIT 1)," +
+		" candidates AS (SELECT s.id FROM src, songs s WHERE s.group_id = src.group_id AND s.id <> src.id AND s.deleted IS NULL" +
+		" UNION SELECT s.id FROM src, songs s WHERE song_lyrics(s.\"text\") % src.lyrics AND s.id <> src.id AND s.deleted IS NULL)," +
+		" scored AS (SELECT s.id, s.\"name\", s.group_id, s.release_date," +
+		" ($2::FLOAT8 * (s.group_id = src.group_id)::INT" +
+		" + $3::FLOAT8 / (1 + abs(to_date(s.release_date, 'DD.MM.YYYY') - src.released) / 365.0)" +
+		" + $4::FLOAT8 * similarity(song_lyrics(s.\"text\"), src.lyrics))::FLOAT8 AS score" +
+		" FROM src, candidates c JOIN songs s ON s.id = c.id)"
+
+	queryCountSimilarSongs = queryScoredSimilarSongs + " SELECT COUNT(*) FROM scored WHERE score > 0;"
+
+	queryGetSimilarSongs = queryScoredSimilarSongs +
+		" SELECT sc.\"name\", g.\"name\", sc.release_date, sc.score FROM scored sc JOIN music_groups g ON g.id = sc.group_id" +
+		" WHERE sc.score > 0 ORDER BY sc.score DESC, sc.id LIMIT $5 OFFSET $6;"
+
+	queryFindSearchLang = "SELECT EXISTS (SELECT 1 FROM pg_ts_config WHERE cfgname = $1);"
 )
 
 func (r *Repo) queryUpdateSong(song entity.SongDTO, name string) (string, []interface{}) {
@@ -43,48 +112,177 @@ func (r *Repo) queryUpdateSong(song entity.SongDTO, name string) (string, []inte
 	}
 	if song.Text != nil {
 		str = append(str, fmt.Sprintf("\"text\" = $%d", argIndex))
-		args = append(args, "{"+strings.Join(*song.Text, ",")+"}")
+		args = append(args, pq.Array(*song.Text))
 		argIndex++
 	}
 	if song.Link != nil {
-		str = append(str, fmt.Sprintf("\"link\" = $%d", argIndex))
+		// Новая ссылка ещё не проверена.
+		str = append(str, fmt.Sprintf("\"link\" = $%d, link_status = 'unchecked', link_status_code = NULL, link_checked = NULL", argIndex))
 		args = append(args, *song.Link)
 		argIndex++
 	}
+	if song.Tags != nil {
+		str = append(str, fmt.Sprintf("tags = $%d", argIndex))
+		args = append(args, pq.Array(*song.Tags))
+		argIndex++
+	}
+	if song.Lang != nil {
+		str = append(str, fmt.Sprintf("lang = NULLIF($%d, '')", argIndex))
+		args = append(args, *song.Lang)
+		argIndex++
+	}
 
 	if len(str) == 0 {
-		return "", nil
+		if song.Artists == nil && song.Genres == nil {
+			return "", nil
+		}
+		// Меняются только связанные таблицы: поля песни не трогаем, но id нужен.
+		str = append(str, "id = id")
 	}
 
 	where := fmt.Sprintf("\"name\" = $%d", argIndex)
 	args = append(args, name)
-	return "UPDATE songs SET " + strings.Join(str, ", ") + " WHERE " + where + " AND deleted IS NULL;", args
+	return "UPDATE songs SET " + strings.Join(str, ", ") + " WHERE " + where + " AND deleted IS NULL RETURNING id, group_id;", args
 }
 
 func (r *Repo) queryGetFilteredSongs(song entity.FilterSongDTO) (string, []interface{}) {
-	baseQuery := "SELECT \"name\", group_id, release_date, \"text\", \"link\" FROM songs"
+	baseQuery := "SELECT s.id, s.\"name\", g.\"name\", s.release_date, s.\"text\", s.\"link\", a.title, s.disc_number, s.track_number," +
+		" (SELECT json_agg(json_build_object('name', ag.\"name\", 'role', sa.role) ORDER BY sa.position, sa.role)" +
+		" FROM song_artists sa JOIN music_groups ag ON ag.id = sa.group_id WHERE sa.song_id = s.id)," +
+		" ARRAY(SELECT gn.\"name\" FROM song_genres sg JOIN genres gn ON gn.id = sg.genre_id WHERE sg.song_id = s.id ORDER BY gn.\"name\"), s.tags, COALESCE(s.lang, '')," +
+		" s.link_status, COALESCE(to_char(s.link_checked, 'YYYY-MM-DD\"T\"HH24:MI:SS'), '')" +
+		" FROM songs s JOIN music_groups g ON g.id = s.group_id" +
+		" LEFT JOIN albums a ON a.id = s.album_id AND a.deleted IS NULL"
+	where, args := r.filterSongs(song, "s.")
+
+	order := " ORDER BY s.id;"
+	if song.Popularity != nil {
+		order = " ORDER BY " + fmt.Sprintf(querySongPopularity, len(args)+1) + " DESC, s.id;"
+		args = append(args, song.Popularity.Seconds())
+	}
+
+	return baseQuery + " WHERE " + where + order, args
+}
+
+// filterSongs собирает условие WHERE по фильтру; prefix -- алиас таблицы songs.
+func (r *Repo) filterSongs(song entity.FilterSongDTO, prefix string) (string, []interface{}) {
 	var str []string
 	var args []interface{}
 	argIndex := 1
 
 	if song.Name != nil {
-		str = append(str, fmt.Sprintf("\"name\" = $%d", argIndex))
+		str = append(str, fmt.Sprintf("%s\"name\" = $%d", prefix, argIndex))
 		args = append(args, *song.Name)
 		argIndex++
 	}
 	if song.GroupID != nil {
-		str = append(str, fmt.Sprintf("group_id = $%d", argIndex))
+		str = append(str, fmt.Sprintf("%sgroup_id = $%d", prefix, argIndex))
 		args = append(args, *song.GroupID)
 		argIndex++
 	}
+	if song.ArtistID != nil {
+		str = append(str, fmt.Sprintf("%sid IN (SELECT song_id FROM song_artists WHERE group_id = $%d)", prefix, argIndex))
+		args = append(args, *song.ArtistID)
+		argIndex++
+	}
 	if song.ReleaseDate != nil {
-		str = append(str, fmt.Sprintf("release_date = $%d", argIndex))
+		str = append(str, fmt.Sprintf("%srelease_date = $%d", prefix, argIndex))
 		args = append(args, *song.ReleaseDate)
+		argIndex++
+	}
+	if song.Album != nil {
+		str = append(str, fmt.Sprintf("%salbum_id IN (SELECT id FROM albums WHERE title = $%d AND deleted IS NULL)", prefix, argIndex))
+		args = append(args, *song.Album)
+		argIndex++
+	}
+	if song.AlbumID != nil {
+		str = append(str, fmt.Sprintf("%salbum_id = $%d", prefix, argIndex))
+		args = append(args, *song.AlbumID)
+		argIndex++
+	}
+	if song.Genre != nil {
+		str = append(str, fmt.Sprintf("%sid IN (SELECT sg.song_id FROM song_genres sg JOIN genres gn ON gn.id = sg.genre_id WHERE gn.\"name\" = $%d)", prefix, argIndex))
+		args = append(args, *song.Genre)
+		argIndex++
+	}
+	if song.Tag != nil {
+		str = append(str, fmt.Sprintf("%stags @> ARRAY[$%d]::TEXT[]", prefix, argIndex))
+		args = append(args, *song.Tag)
+		argIndex++
+	}
+	if song.LinkStatus != nil {
+		str = append(str, fmt.Sprintf("%slink_status = $%d", prefix, argIndex))
+		args = append(args, *song.LinkStatus)
 	}
 
-	if len(str) == 0 {
-		return baseQuery + " WHERE deleted IS NULL;", nil
+	str = append(str, prefix+"deleted IS NULL")
+	return strings.Join(str, " AND "), args
+}
+
+// querySongFacets считает песни по жанрам, тегам, группам и годам выпуска
+// в пределах фильтра; строки -- (facet, value, count).
+func (r *Repo) querySongFacets(song entity.FilterSongDTO) (string, []interface{}) {
+	where, args := r.filterSongs(song, "s.")
+
+	query := "WITH filtered AS (SELECT s.id, s.group_id, s.release_date, s.tags FROM songs s WHERE " + where + ")" +
+		" SELECT 'genre'::TEXT, gn.\"name\", COUNT(*) FROM filtered f JOIN song_genres sg ON sg.song_id = f.id JOIN genres gn ON gn.id = sg.genre_id GROUP BY gn.\"name\"" +
+		" UNION ALL SELECT 'tag', t.tag, COUNT(*) FROM filtered f, unnest(f.tags) AS t(tag) GROUP BY t.tag" +
+		" UNION ALL SELECT 'group', g.\"name\", COUNT(*) FROM filtered f JOIN music_groups g ON g.id = f.group_id GROUP BY g.\"name\"" +
+		" UNION ALL SELECT 'year', right(f.release_date, 4), COUNT(*) FROM filtered f GROUP BY right(f.release_date, 4)" +
+		" ORDER BY 1, 3 DESC, 2;"
+
+	return query, args
+}
+
+// querySongStats собирает статистику по отфильтрованным песням:
+//   - summary: число песен и среднее число куплетов (по секциям verse, а без секций -- по куплетам text);
+//   - counts: строки (facet, value, count) по группам, годам и десятилетиям выпуска;
+//   - recent: последние добавленные песни.
+func (r *Repo) querySongStats(song entity.FilterSongDTO, recent int) (string, string, string, []interface{}) {
+	where, args := r.filterSongs(song, "s.")
+
+	summaryQuery := "SELECT COUNT(*), COALESCE(AVG(CASE WHEN sc.sections > 0 THEN sc.verses ELSE cardinality(s.\"text\") END), 0)::FLOAT8" +
+		" FROM songs s LEFT JOIN LATERAL (SELECT COUNT(*) AS sections, COUNT(*) FILTER (WHERE ss.kind = 'verse') AS verses" +
+		" FROM song_sections ss WHERE ss.song_id = s.id) sc ON TRUE" +
+		" WHERE " + where + ";"
+
+	countsQuery := "WITH filtered AS (SELECT s.group_id, right(s.release_date, 4) AS year FROM songs s WHERE " + where + ")" +
+		" SELECT 'group'::TEXT, g.\"name\", COUNT(*) FROM filtered f JOIN music_groups g ON g.id = f.group_id GROUP BY g.\"name\"" +
+		" UNION ALL SELECT 'year', f.year, COUNT(*) FROM filtered f GROUP BY f.year" +
+		" UNION ALL SELECT 'decade', left(f.year, 3) || '0s', COUNT(*) FROM filtered f GROUP BY left(f.year, 3)" +
+		" ORDER BY 1, 3 DESC, 2;"
+
+	recentQuery := "SELECT s.\"name\", g.\"name\", to_char(s.created, 'YYYY-MM-DD\"T\"HH24:MI:SS')" +
+		" FROM songs s JOIN music_groups g ON g.id = s.group_id" +
+		" WHERE " + where +
+		fmt.Sprintf(" ORDER BY s.created DESC, s.id DESC LIMIT %d;", recent)
+
+	return summaryQuery, countsQuery, recentQuery, args
+}
+
+// querySearchSongs ищет песни по тексту запроса; если lang пуст, каждая песня
+// разбирает запрос своей конфигурацией search_lang.
+func (r *Repo) querySearchSongs(q, lang string, limit, offset int) (string, string, []interface{}) {
+	from := " FROM songs s JOIN music_groups g ON g.id = s.group_id"
+	args := []interface{}{q}
+	tsquery := "websearch_to_tsquery(s.search_lang, $1)"
+	where := " WHERE s.deleted IS NULL"
+
+	if lang != "" {
+		args = append(args, lang)
+		tsquery = "websearch_to_tsquery($2::regconfig, $1)"
+		where += " AND s.search_lang = $2::regconfig"
 	}
 
-	return baseQuery + " WHERE " + strings.Join(str, " AND ") + " AND deleted IS NULL;", args
+	from += ", LATERAL " + tsquery + " AS query"
+	where += " AND s.search @@ query"
+
+	countQuery := "SELECT COUNT(*)" + from + where + ";"
+
+	selectQuery := "SELECT s.\"name\", g.\"name\", s.release_date, ts_rank(s.search, query) AS rank," +
+		" ts_headline(s.search_lang, array_to_string(s.\"text\", ' / '), query, 'MaxFragments=2, MinWords=3, MaxWords=12, StartSel=<b>, StopSel=</b>')" +
+		from + where +
+		fmt.Sprintf(" ORDER BY rank DESC, s.id LIMIT %d OFFSET %d;", limit, offset)
+
+	return countQuery, selectQuery, args
 }
@@ -3,90 +3,152 @@ package repo
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
-	"strings"
+	"iter"
 	"time"
 
 	"go-rest-api/internal/entity"
 	"go-rest-api/internal/errs"
 	"go-rest-api/pkg/logger"
+	"go-rest-api/pkg/postgres"
 
+	"github.com/lib/pq"
 	"go.uber.org/zap"
 )
 
+// Repo пишет и читает через основной пул db; самые частые чтения
+// (поиск группы, текст песни, списки песен) идут через reader на реплики.
 type Repo struct {
-	ctx    context.Context
-	logger *logger.Logger
-	db     *sql.DB
+	logger  *logger.Logger
+	db      *postgres.DB
+	cluster *postgres.Cluster
 }
 
-func New(ctx context.Context, db *sql.DB) *Repo {
+func New(ctx context.Context, cluster *postgres.Cluster) *Repo {
 	return &Repo{
-		ctx:    ctx,
-		logger: logger.FromContext(ctx),
-		db:     db,
+		logger:  logger.FromContext(ctx),
+		db:      cluster.Primary(),
+		cluster: cluster,
 	}
 }
 
+// reader -- пул для чтения, которое может отставать от записи в других запросах.
+func (r *Repo) reader(ctx context.Context) *sql.DB {
+	return r.cluster.Reader(ctx)
+}
+
+// log -- логгер текущего запроса (request_id, trace_id).
+func (r *Repo) log(ctx context.Context) *logger.Logger {
+	return logger.Request(ctx, r.logger)
+}
+
 // FindGroupID возвращает group id или ошибку.
-func (r *Repo) FindGroupID(group string) (id int, err error) {
-	ctx, cancel := context.WithTimeout(r.ctx, 5*time.Second)
+func (r *Repo) FindGroupID(ctx context.Context, group string) (id int, err error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
-	err = r.db.QueryRowContext(ctx, queryFindGroupID, group).Scan(&id)
+	err = r.reader(ctx).QueryRowContext(ctx, queryFindGroupID, group).Scan(&id)
 	if errors.Is(err, sql.ErrNoRows) {
-		r.logger.Debug("Request did not return value")
+		r.log(ctx).Debug("Request did not return value")
 		return 0, nil
 	}
 	if err != nil {
-		r.logger.Debug("Execute sql request error", zap.Error(err))
+		r.log(ctx).Debug("Execute sql request error", zap.Error(err))
 		return 0, err
 	}
 
 	return id, nil
 }
 
-// CreateGroup создаёт новую запись о группе в БД и возвращает id; или возвращает ощибку.
-func (r *Repo) CreateGroup(group string) (id int, err error) {
-	ctx, cancel := context.WithTimeout(r.ctx, 5*time.Second)
+// CreateGroup создаёт группу, если её ещё нет, и возвращает id; или возвращает ощибку.
+// Запрос идёт в primary, поэтому отставание реплик не приводит к дубликатам.
+func (r *Repo) CreateGroup(ctx context.Context, group string) (id int, err error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
 	err = r.db.QueryRowContext(ctx, queryCreateGroup, group).Scan(&id)
 	if errors.Is(err, sql.ErrNoRows) {
-		r.logger.Debug("Request did not return value")
+		r.log(ctx).Debug("Request did not return value")
 		return 0, nil
 	}
 	if err != nil {
-		r.logger.Debug("Execute sql request error", zap.Error(err))
+		r.log(ctx).Debug("Execute sql request error", zap.Error(err))
 		return 0, err
 	}
 
 	return id, nil
 }
 
-// CreateSong сохраняет песню и возвращает nil; или возвращает ошибку.
-func (r *Repo) CreateSong(song entity.SongDTO) error {
-	ctx, cancel := context.WithTimeout(r.ctx, 5*time.Second)
+// CreateSong сохраняет песню вместе с секциями текста и возвращает nil; или возвращает ошибку.
+func (r *Repo) CreateSong(ctx context.Context, song entity.SongDTO) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
 	if err := isDate(*song.ReleaseDate); err != nil {
-		r.logger.Debug("Wrong date format", zap.Error(err))
+		r.log(ctx).Debug("Wrong date format", zap.Error(err))
 		return errs.ErrBadRequest
 	}
 
-	text := "{" + strings.Join(*song.Text, ",") + "}"
+	tags := []string{}
+	if song.Tags != nil {
+		tags = *song.Tags
+	}
+
+	var lang string
+	if song.Lang != nil {
+		lang = *song.Lang
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		r.log(ctx).Debug("Can't begin transaction", zap.Error(err))
+		return err
+	}
+	defer tx.Rollback()
 
-	if _, err := r.db.ExecContext(
+	var id int
+	if err := tx.QueryRowContext(
 		ctx,
 		querySaveNewSong,
 		song.Name,
 		song.GroupID,
 		song.ReleaseDate,
-		text,
+		pq.Array(*song.Text),
 		song.Link,
-	); err != nil {
-		r.logger.Debug("Can't insert into DB", zap.Error(err))
+		song.AlbumID,
+		song.DiscNumber,
+		song.TrackNumber,
+		pq.Array(tags),
+		lang,
+	).Scan(&id); err != nil {
+		r.log(ctx).Debug("Can't insert into DB", zap.Error(err))
+		return err
+	}
+
+	if song.Sections != nil {
+		if err := r.saveSections(ctx, tx, id, *song.Sections); err != nil {
+			return err
+		}
+	}
+
+	var artists []entity.ArtistDTO
+	if song.Artists != nil {
+		artists = *song.Artists
+	}
+	if err := r.saveArtists(ctx, tx, id, *song.GroupID, artists); err != nil {
+		return err
+	}
+
+	if song.Genres != nil {
+		if err := r.saveGenres(ctx, tx, id, *song.Genres); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		r.log(ctx).Debug("Can't commit transaction", zap.Error(err))
 		return err
 	}
 
@@ -94,37 +156,37 @@ func (r *Repo) CreateSong(song entity.SongDTO) error {
 }
 
 // DeleteSong удаляет песню по переданному song name и возвращает bool; или возвращает ошибку.
-func (r *Repo) DeleteSong(name string) (bool, error) {
-	ctx, cancel := context.WithTimeout(r.ctx, 5*time.Second)
+func (r *Repo) DeleteSong(ctx context.Context, name string) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
 	res, err := r.db.ExecContext(ctx, queryDeleteSong, name)
 	if err != nil {
-		r.logger.Debug("Can't update field in table", zap.Error(err))
+		r.log(ctx).Debug("Can't update field in table", zap.Error(err))
 		return false, errs.ErrBadRequest
 	}
 
 	rows, err := res.RowsAffected()
 	if err != nil {
-		r.logger.Debug("Failed to get rows affected", zap.Error(err))
+		r.log(ctx).Debug("Failed to get rows affected", zap.Error(err))
 		return false, err
 	}
 
 	if rows == 0 {
-		r.logger.Debug("Song is not exist", zap.String("song_name", name))
+		r.log(ctx).Debug("Song is not exist", zap.String("song_name", name))
 	}
 
 	return rows > 0, nil
 }
 
 // UpdateSong обновляет песню по переданному song name и данным и возвращает bool; или возвращает ошибку.
-func (r *Repo) UpdateSong(name string, song entity.SongDTO) (bool, error) {
-	ctx, cancel := context.WithTimeout(r.ctx, 5*time.Second)
+func (r *Repo) UpdateSong(ctx context.Context, name string, song entity.SongDTO) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
 	if song.ReleaseDate != nil {
 		if err := isDate(*song.ReleaseDate); err != nil {
-			r.logger.Debug("Wrong date format", zap.Error(err))
+			r.log(ctx).Debug("Wrong date format", zap.Error(err))
 			return false, errs.ErrBadRequest
 		}
 	}
@@ -132,136 +194,877 @@ func (r *Repo) UpdateSong(name string, song entity.SongDTO) (bool, error) {
 	query, args := r.queryUpdateSong(song, name)
 	if query == "" {
 		errMsg := "query is empty"
-		r.logger.Debug("Incorrect query", zap.Error(fmt.Errorf("%v", errMsg)))
+		r.log(ctx).Debug("Incorrect query", zap.Error(fmt.Errorf("%v", errMsg)))
 		return false, fmt.Errorf("%v", errMsg)
 	}
 
-	res, err := r.db.ExecContext(ctx, query, args...)
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
-		r.logger.Debug("Can't update field in table", zap.Error(err))
+		r.log(ctx).Debug("Can't begin transaction", zap.Error(err))
 		return false, err
 	}
+	defer tx.Rollback()
 
-	rows, err := res.RowsAffected()
+	rows, err := tx.QueryContext(ctx, query, args...)
 	if err != nil {
-		r.logger.Debug("Failed to get rows affected", zap.Error(err))
+		r.log(ctx).Debug("Can't update field in table", zap.Error(err))
 		return false, err
 	}
 
-	if rows == 0 {
-		r.logger.Debug("Song is not exist", zap.String("song_name", name))
+	type updated struct{ id, groupID int }
+	var songs []updated
+	for rows.Next() {
+		var u updated
+		if err := rows.Scan(&u.id, &u.groupID); err != nil {
+			rows.Close()
+			r.log(ctx).Debug("Rows scan error", zap.Error(err))
+			return false, err
+		}
+		songs = append(songs, u)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		r.log(ctx).Debug("Can't parse rows", zap.Error(err))
+		return false, err
 	}
 
-	return rows > 0, nil
+	if len(songs) == 0 {
+		r.log(ctx).Debug("Song is not exist", zap.String("song_name", name))
+		return false, nil
+	}
+
+	for _, u := range songs {
+		if song.Sections != nil {
+			if err := r.saveSections(ctx, tx, u.id, *song.Sections); err != nil {
+				return false, err
+			}
+		}
+		if song.TimedLines != nil {
+			if err := r.saveTimedLines(ctx, tx, u.id, *song.TimedLines); err != nil {
+				return false, err
+			}
+		}
+		if song.Artists != nil {
+			if err := r.saveArtists(ctx, tx, u.id, u.groupID, *song.Artists); err != nil {
+				return false, err
+			}
+		} else if song.GroupID != nil {
+			if err := r.saveMainArtist(ctx, tx, u.id, u.groupID); err != nil {
+				return false, err
+			}
+		}
+		if song.Genres != nil {
+			if err := r.saveGenres(ctx, tx, u.id, *song.Genres); err != nil {
+				return false, err
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		r.log(ctx).Debug("Can't commit transaction", zap.Error(err))
+		return false, err
+	}
+
+	return true, nil
 }
 
 // GetSongText по song name находит песню и возвращает текст; или возвращает ошибку.
-func (r *Repo) GetSongText(name string) (t []string, err error) {
-	ctx, cancel := context.WithTimeout(r.ctx, 5*time.Second)
+func (r *Repo) GetSongText(ctx context.Context, name string) (t []string, err error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
-	var text []byte
-	err = r.db.QueryRowContext(ctx, queryGetSongText, name).Scan(&text)
+	err = r.reader(ctx).QueryRowContext(ctx, queryGetSongText, name).Scan(pq.Array(&t))
 	if errors.Is(err, sql.ErrNoRows) {
-		r.logger.Debug("Request did not return value")
+		r.log(ctx).Debug("Request did not return value")
 		return nil, nil
 	}
 	if err != nil {
-		r.logger.Debug("Execute sql request error", zap.Error(err))
+		r.log(ctx).Debug("Execute sql request error", zap.Error(err))
 		return nil, err
 	}
 
-	str := strings.Trim(string(text), "{}")
-	t = strings.Split(str, ",")
-
 	return t, nil
 }
 
-// GetFilteredSongs возвращает отфильтрованный список песен или ошибку.
-func (r *Repo) GetFilteredSongs(song entity.FilterSongDTO) (songs []entity.Song, err error) {
-	ctx, cancel := context.WithTimeout(r.ctx, 5*time.Second)
+// GetSongSections по song name возвращает секции текста по порядку; nil -- если песни нет; или возвращает ошибку.
+func (r *Repo) GetSongSections(ctx context.Context, name string) ([]entity.Section, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
-	if song.ReleaseDate != nil {
-		if err = isDate(*song.ReleaseDate); err != nil {
-			r.logger.Debug("Wrong date format", zap.Error(err))
-			return nil, errs.ErrBadRequest
+	var id int
+	err := r.db.QueryRowContext(ctx, queryFindSongID, name).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		r.log(ctx).Debug("Request did not return value")
+		return nil, nil
+	}
+	if err != nil {
+		r.log(ctx).Debug("Execute sql request error", zap.Error(err))
+		return nil, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, queryGetSections, id)
+	if err != nil {
+		r.log(ctx).Debug("Execute sql request error", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	sections := []entity.Section{}
+	for rows.Next() {
+		var section entity.Section
+		if err := rows.Scan(&section.Type, &section.Label, pq.Array(&section.Lines)); err != nil {
+			r.log(ctx).Debug("Rows scan error", zap.Error(err))
+			return nil, err
 		}
+		sections = append(sections, section)
 	}
 
-	query, args := r.queryGetFilteredSongs(song)
+	if err := rows.Err(); err != nil {
+		r.log(ctx).Debug("Can't parse rows", zap.Error(err))
+		return nil, err
+	}
+
+	return sections, nil
+}
+
+// GetSongTimedLines по song name возвращает синхронизированные строки текста; nil -- если песни нет; или возвращает ошибку.
+func (r *Repo) GetSongTimedLines(ctx context.Context, name string) ([]entity.TimedLine, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
 
-	var rows *sql.Rows
-	if args == nil {
-		rows, err = r.db.QueryContext(ctx, query)
-	} else {
-		rows, err = r.db.QueryContext(ctx, query, args...)
+	var id int
+	err := r.db.QueryRowContext(ctx, queryFindSongID, name).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		r.log(ctx).Debug("Request did not return value")
+		return nil, nil
+	}
+	if err != nil {
+		r.log(ctx).Debug("Execute sql request error", zap.Error(err))
+		return nil, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, queryGetTimedLines, id)
+	if err != nil {
+		r.log(ctx).Debug("Execute sql request error", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	lines := []entity.TimedLine{}
+	for rows.Next() {
+		var line entity.TimedLine
+		if err := rows.Scan(&line.TimeMS, &line.Text); err != nil {
+			r.log(ctx).Debug("Rows scan error", zap.Error(err))
+			return nil, err
+		}
+		lines = append(lines, line)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.log(ctx).Debug("Can't parse rows", zap.Error(err))
+		return nil, err
+	}
+
+	return lines, nil
+}
+
+// GetSongTranslations по song name возвращает языки текста: первым -- оригинал
+// (язык может быть не указан), затем переводы; nil -- если песни нет; или возвращает ошибку.
+func (r *Repo) GetSongTranslations(ctx context.Context, name string) ([]entity.Translation, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var id int
+	var lang string
+	err := r.db.QueryRowContext(ctx, queryGetSongLang, name).Scan(&id, &lang)
+	if errors.Is(err, sql.ErrNoRows) {
+		r.log(ctx).Debug("Request did not return value")
+		return nil, nil
+	}
+	if err != nil {
+		r.log(ctx).Debug("Execute sql request error", zap.Error(err))
+		return nil, err
 	}
+
+	rows, err := r.db.QueryContext(ctx, queryGetTranslationLangs, id)
 	if err != nil {
-		r.logger.Debug("Execute sql request error", zap.Error(err))
+		r.log(ctx).Debug("Execute sql request error", zap.Error(err))
 		return nil, err
 	}
 	defer rows.Close()
 
+	translations := []entity.Translation{{Lang: lang, Original: true}}
 	for rows.Next() {
-		var song entity.SongDTO
-		var text []byte
+		var translation entity.Translation
+		if err := rows.Scan(&translation.Lang); err != nil {
+			r.log(ctx).Debug("Rows scan error", zap.Error(err))
+			return nil, err
+		}
+		translations = append(translations, translation)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.log(ctx).Debug("Can't parse rows", zap.Error(err))
+		return nil, err
+	}
+
+	return translations, nil
+}
+
+// GetSongTranslation по song name и языку возвращает перевод текста; nil -- если песни или перевода нет; или возвращает ошибку.
+func (r *Repo) GetSongTranslation(ctx context.Context, name, lang string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var id int
+	err := r.db.QueryRowContext(ctx, queryFindSongID, name).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		r.log(ctx).Debug("Request did not return value")
+		return nil, nil
+	}
+	if err != nil {
+		r.log(ctx).Debug("Execute sql request error", zap.Error(err))
+		return nil, err
+	}
+
+	var text []string
+	err = r.db.QueryRowContext(ctx, queryGetTranslation, id, lang).Scan(pq.Array(&text))
+	if errors.Is(err, sql.ErrNoRows) {
+		r.log(ctx).Debug("Request did not return value")
+		return nil, nil
+	}
+	if err != nil {
+		r.log(ctx).Debug("Execute sql request error", zap.Error(err))
+		return nil, err
+	}
+
+	return text, nil
+}
+
+// SetSongTranslation сохраняет (или заменяет) перевод текста песни и возвращает bool; или возвращает ошибку.
+func (r *Repo) SetSongTranslation(ctx context.Context, name, lang string, text []string) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var id int
+	err := r.db.QueryRowContext(ctx, queryFindSongID, name).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		r.log(ctx).Debug("Song is not exist", zap.String("song_name", name))
+		return false, nil
+	}
+	if err != nil {
+		r.log(ctx).Debug("Execute sql request error", zap.Error(err))
+		return false, err
+	}
+
+	if _, err := r.db.ExecContext(ctx, querySaveTranslation, id, lang, pq.Array(text)); err != nil {
+		r.log(ctx).Debug("Can't insert translation", zap.Error(err))
+		return false, err
+	}
+
+	return true, nil
+}
+
+// DeleteSongTranslation удаляет перевод текста песни и возвращает bool; или возвращает ошибку.
+func (r *Repo) DeleteSongTranslation(ctx context.Context, name, lang string) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var id int
+	err := r.db.QueryRowContext(ctx, queryFindSongID, name).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		r.log(ctx).Debug("Song is not exist", zap.String("song_name", name))
+		return false, nil
+	}
+	if err != nil {
+		r.log(ctx).Debug("Execute sql request error", zap.Error(err))
+		return false, err
+	}
 
+	res, err := r.db.ExecContext(ctx, queryDeleteTranslation, id, lang)
+	if err != nil {
+		r.log(ctx).Debug("Can't delete translation", zap.Error(err))
+		return false, err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		r.log(ctx).Debug("Failed to get rows affected", zap.Error(err))
+		return false, err
+	}
+
+	if rows == 0 {
+		r.log(ctx).Debug("Translation is not exist", zap.String("song_name", name), zap.String("lang", lang))
+	}
+
+	return rows > 0, nil
+}
+
+// SearchSongs ищет песни полнотекстовым поиском и возвращает страницу результатов и их общее число; или возвращает ошибку.
+func (r *Repo) SearchSongs(ctx context.Context, q, lang string, limit, offset int) (results []entity.SearchResult, total int, err error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if lang != "" {
+		var exists bool
+		if err = r.db.QueryRowContext(ctx, queryFindSearchLang, lang).Scan(&exists); err != nil {
+			r.log(ctx).Debug("Execute sql request error", zap.Error(err))
+			return nil, 0, err
+		}
+		if !exists {
+			r.log(ctx).Debug("Unknown text search configuration", zap.String("lang", lang))
+			return nil, 0, errs.ErrBadRequest
+		}
+	}
+
+	countQuery, selectQuery, args := r.querySearchSongs(q, lang, limit, offset)
+
+	if err = r.db.QueryRowContext(ctx, countQuery, args...).Scan(&total); err != nil {
+		r.log(ctx).Debug("Execute sql request error", zap.Error(err))
+		return nil, 0, err
+	}
+	if total == 0 {
+		return nil, 0, nil
+	}
+
+	rows, err := r.db.QueryContext(ctx, selectQuery, args...)
+	if err != nil {
+		r.log(ctx).Debug("Execute sql request error", zap.Error(err))
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var result entity.SearchResult
 		if err := rows.Scan(
-			&song.Name,
-			&song.GroupID,
-			&song.ReleaseDate,
-			&text,
-			&song.Link,
+			&result.Name,
+			&result.Group,
+			&result.ReleaseDate,
+			&result.Rank,
+			&result.Headline,
 		); err != nil {
-			r.logger.Debug("Rows scan error", zap.Error(err))
-			return nil, err
+			r.log(ctx).Debug("Rows scan error", zap.Error(err))
+			return nil, 0, err
 		}
+		results = append(results, result)
+	}
+
+	if err = rows.Err(); err != nil {
+		r.log(ctx).Debug("Can't parse rows", zap.Error(err))
+		return nil, 0, err
+	}
 
-		group, err := r.findGroupName(*song.GroupID)
+	return results, total, nil
+}
+
+// GetFilteredSongs возвращает отфильтрованный список песен или ошибку.
+func (r *Repo) GetFilteredSongs(ctx context.Context, song entity.FilterSongDTO) (songs []entity.Song, err error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	for s, err := range r.streamSongs(ctx, song) {
 		if err != nil {
-			r.logger.Debug("Can't find group name", zap.Error(err))
 			return nil, err
 		}
+		songs = append(songs, s)
+	}
 
-		str := strings.Trim(string(text), "{}")
-		t := strings.Split(str, ",")
+	return songs, nil
+}
 
-		s := entity.Song{
-			Name:        song.Name,
-			Group:       &group,
-			ReleaseDate: song.ReleaseDate,
-			Text:        &t,
-			Link:        song.Link,
+// StreamSongs возвращает итератор по отфильтрованным песням, не накапливая их в памяти.
+// Ошибка запроса или чтения строк приходит последним элементом итератора.
+func (r *Repo) StreamSongs(ctx context.Context, song entity.FilterSongDTO) iter.Seq2[entity.Song, error] {
+	// Без таймаута: выгрузка всего каталога может идти дольше 5 секунд.
+	return r.streamSongs(ctx, song)
+}
+
+func (r *Repo) streamSongs(ctx context.Context, song entity.FilterSongDTO) iter.Seq2[entity.Song, error] {
+	return func(yield func(entity.Song, error) bool) {
+		if song.ReleaseDate != nil {
+			if err := isDate(*song.ReleaseDate); err != nil {
+				r.log(ctx).Debug("Wrong date format", zap.Error(err))
+				yield(entity.Song{}, errs.ErrBadRequest)
+				return
+			}
 		}
 
-		songs = append(songs, s)
+		query, args := r.queryGetFilteredSongs(song)
+
+		rows, err := r.reader(ctx).QueryContext(ctx, query, args...)
+		if err != nil {
+			r.log(ctx).Debug("Execute sql request error", zap.Error(err))
+			yield(entity.Song{}, err)
+			return
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var id int
+			var name, group, releaseDate, link string
+			var text []string
+			var album sql.NullString
+			var disc, track sql.NullInt64
+			var artistsJSON []byte
+			var lang, linkStatus, linkChecked string
+			genres, tags := []string{}, []string{}
+
+			if err := rows.Scan(
+				&id, &name, &group, &releaseDate, pq.Array(&text), &link, &album, &disc, &track,
+				&artistsJSON, pq.Array(&genres), pq.Array(&tags), &lang, &linkStatus, &linkChecked,
+			); err != nil {
+				r.log(ctx).Debug("Rows scan error", zap.Error(err))
+				yield(entity.Song{}, err)
+				return
+			}
+
+			artists := []entity.Artist{}
+			if artistsJSON != nil {
+				if err := json.Unmarshal(artistsJSON, &artists); err != nil {
+					r.log(ctx).Debug("Can't decode song artists", zap.Error(err))
+					yield(entity.Song{}, err)
+					return
+				}
+			}
+
+			s := entity.Song{
+				ID:          &id,
+				Name:        &name,
+				Group:       &group,
+				Artists:     &artists,
+				ReleaseDate: &releaseDate,
+				Text:        &text,
+				Link:        &link,
+				Genres:      &genres,
+				Tags:        &tags,
+				LinkStatus:  &linkStatus,
+			}
+			if album.Valid {
+				s.Album = &album.String
+			}
+			if lang != "" {
+				s.Lang = &lang
+			}
+			if linkChecked != "" {
+				s.LinkChecked = &linkChecked
+			}
+			if disc.Valid {
+				n := int(disc.Int64)
+				s.DiscNumber = &n
+			}
+			if track.Valid {
+				n := int(track.Int64)
+				s.TrackNumber = &n
+			}
+
+			if !yield(s, nil) {
+				return
+			}
+		}
+
+		if err := rows.Err(); err != nil {
+			r.log(ctx).Debug("Can't parse rows", zap.Error(err))
+			yield(entity.Song{}, err)
+		}
+	}
+}
+
+// GetSongFacets возвращает число песен по жанрам, тегам, группам и годам выпуска в пределах фильтра; или возвращает ошибку.
+func (r *Repo) GetSongFacets(ctx context.Context, song entity.FilterSongDTO) (entity.SongFacets, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if song.ReleaseDate != nil {
+		if err := isDate(*song.ReleaseDate); err != nil {
+			r.log(ctx).Debug("Wrong date format", zap.Error(err))
+			return entity.SongFacets{}, errs.ErrBadRequest
+		}
 	}
 
-	if err = rows.Err(); err != nil {
-		r.logger.Debug("Can't parse rows", zap.Error(err))
+	query, args := r.querySongFacets(song)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		r.log(ctx).Debug("Execute sql request error", zap.Error(err))
+		return entity.SongFacets{}, err
+	}
+	defer rows.Close()
+
+	facets := entity.SongFacets{
+		Genres: []entity.FacetCount{},
+		Tags:   []entity.FacetCount{},
+		Groups: []entity.FacetCount{},
+		Years:  []entity.FacetCount{},
+	}
+	for rows.Next() {
+		var facet string
+		var count entity.FacetCount
+		if err := rows.Scan(&facet, &count.Value, &count.Count); err != nil {
+			r.log(ctx).Debug("Rows scan error", zap.Error(err))
+			return entity.SongFacets{}, err
+		}
+
+		switch facet {
+		case "genre":
+			facets.Genres = append(facets.Genres, count)
+		case "tag":
+			facets.Tags = append(facets.Tags, count)
+		case "group":
+			facets.Groups = append(facets.Groups, count)
+		case "year":
+			facets.Years = append(facets.Years, count)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		r.log(ctx).Debug("Can't parse rows", zap.Error(err))
+		return entity.SongFacets{}, err
+	}
+
+	return facets, nil
+}
+
+// GetSongStats возвращает статистику по отфильтрованным песням и число удалённых сущностей; или возвращает ошибку.
+// Все запросы выполняются в одной read-only транзакции, чтобы цифры были согласованы между собой.
+func (r *Repo) GetSongStats(ctx context.Context, song entity.FilterSongDTO, recent int) (entity.SongStats, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if song.ReleaseDate != nil {
+		if err := isDate(*song.ReleaseDate); err != nil {
+			r.log(ctx).Debug("Wrong date format", zap.Error(err))
+			return entity.SongStats{}, errs.ErrBadRequest
+		}
+	}
+
+	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		r.log(ctx).Debug("Can't begin transaction", zap.Error(err))
+		return entity.SongStats{}, err
+	}
+	defer tx.Rollback()
+
+	summaryQuery, countsQuery, recentQuery, args := r.querySongStats(song, recent)
+
+	stats := entity.SongStats{
+		Groups:  []entity.FacetCount{},
+		Years:   []entity.FacetCount{},
+		Decades: []entity.FacetCount{},
+		Recent:  []entity.RecentSong{},
+	}
+
+	if err := tx.QueryRowContext(ctx, summaryQuery, args...).Scan(&stats.Songs, &stats.AverageVerses); err != nil {
+		r.log(ctx).Debug("Execute sql request error", zap.Error(err))
+		return entity.SongStats{}, err
+	}
+
+	rows, err := tx.QueryContext(ctx, countsQuery, args...)
+	if err != nil {
+		r.log(ctx).Debug("Execute sql request error", zap.Error(err))
+		return entity.SongStats{}, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var facet string
+		var count entity.FacetCount
+		if err := rows.Scan(&facet, &count.Value, &count.Count); err != nil {
+			r.log(ctx).Debug("Rows scan error", zap.Error(err))
+			return entity.SongStats{}, err
+		}
+
+		switch facet {
+		case "group":
+			stats.Groups = append(stats.Groups, count)
+		case "year":
+			stats.Years = append(stats.Years, count)
+		case "decade":
+			stats.Decades = append(stats.Decades, count)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		r.log(ctx).Debug("Can't parse rows", zap.Error(err))
+		return entity.SongStats{}, err
+	}
+
+	rows, err = tx.QueryContext(ctx, recentQuery, args...)
+	if err != nil {
+		r.log(ctx).Debug("Execute sql request error", zap.Error(err))
+		return entity.SongStats{}, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var song entity.RecentSong
+		if err := rows.Scan(&song.Name, &song.Group, &song.Created); err != nil {
+			r.log(ctx).Debug("Rows scan error", zap.Error(err))
+			return entity.SongStats{}, err
+		}
+		stats.Recent = append(stats.Recent, song)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.log(ctx).Debug("Can't parse rows", zap.Error(err))
+		return entity.SongStats{}, err
+	}
+
+	if err := tx.QueryRowContext(ctx, queryGetDeletedStats).Scan(
+		&stats.Deleted.Songs,
+		&stats.Deleted.Albums,
+		&stats.Deleted.Playlists,
+	); err != nil {
+		r.log(ctx).Debug("Execute sql request error", zap.Error(err))
+		return entity.SongStats{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		r.log(ctx).Debug("Can't commit transaction", zap.Error(err))
+		return entity.SongStats{}, err
+	}
+
+	return stats, nil
+}
+
+// GetSimilarSongs возвращает страницу песен, упорядоченных по сходству с песней name, и общее число
+// похожих песен (с ненулевой оценкой); или возвращает ошибку.
+func (r *Repo) GetSimilarSongs(ctx context.Context, name string, weights entity.SimilarWeights, limit, offset int) (songs []entity.SimilarSong, total int, err error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if err = r.db.QueryRowContext(ctx, queryCountSimilarSongs, name, weights.Group, weights.Release, weights.Lyrics).Scan(&total); err != nil {
+		r.log(ctx).Debug("Execute sql request error", zap.Error(err))
+		return nil, 0, err
+	}
+	if total == 0 {
+		return nil, 0, nil
+	}
+
+	rows, err := r.db.QueryContext(ctx, queryGetSimilarSongs, name, weights.Group, weights.Release, weights.Lyrics, limit, offset)
+	if err != nil {
+		r.log(ctx).Debug("Execute sql request error", zap.Error(err))
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var song entity.SimilarSong
+		if err := rows.Scan(&song.Name, &song.Group, &song.ReleaseDate, &song.Score); err != nil {
+			r.log(ctx).Debug("Rows scan error", zap.Error(err))
+			return nil, 0, err
+		}
+		songs = append(songs, song)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.log(ctx).Debug("Can't parse rows", zap.Error(err))
+		return nil, 0, err
+	}
+
+	return songs, total, nil
+}
+
+// GetGenres возвращает словарь жанров по алфавиту или ошибку.
+func (r *Repo) GetGenres(ctx context.Context) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, queryGetGenres)
+	if err != nil {
+		r.log(ctx).Debug("Execute sql request error", zap.Error(err))
 		return nil, err
 	}
+	defer rows.Close()
+
+	genres := []string{}
+	for rows.Next() {
+		var genre string
+		if err := rows.Scan(&genre); err != nil {
+			r.log(ctx).Debug("Rows scan error", zap.Error(err))
+			return nil, err
+		}
+		genres = append(genres, genre)
+	}
 
-	return songs, nil
+	if err := rows.Err(); err != nil {
+		r.log(ctx).Debug("Can't parse rows", zap.Error(err))
+		return nil, err
+	}
+
+	return genres, nil
 }
 
-// findGroupName возвращает group name или ошибку.
-func (r *Repo) findGroupName(id int) (name string, err error) {
-	ctx, cancel := context.WithTimeout(r.ctx, 5*time.Second)
+// GetLinksToCheck возвращает до limit ссылок, которые не проверялись с момента before; или возвращает ошибку.
+func (r *Repo) GetLinksToCheck(ctx context.Context, before time.Time, limit int) ([]entity.LinkCheck, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
-	err = r.db.QueryRowContext(ctx, queryFindGroupName, id).Scan(&name)
-	if errors.Is(err, sql.ErrNoRows) {
-		r.logger.Debug("Request did not return value")
-		return "", nil
+	rows, err := r.db.QueryContext(ctx, queryGetLinksToCheck, before, limit)
+	if err != nil {
+		r.log(ctx).Debug("Execute sql request error", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	var links []entity.LinkCheck
+	for rows.Next() {
+		var link entity.LinkCheck
+		if err := rows.Scan(&link.SongID, &link.Link); err != nil {
+			r.log(ctx).Debug("Rows scan error", zap.Error(err))
+			return nil, err
+		}
+		links = append(links, link)
 	}
+
+	if err := rows.Err(); err != nil {
+		r.log(ctx).Debug("Can't parse rows", zap.Error(err))
+		return nil, err
+	}
+
+	return links, nil
+}
+
+// SaveLinkChecks записывает результаты проверки ссылок одной транзакцией; или возвращает ошибку.
+func (r *Repo) SaveLinkChecks(ctx context.Context, links []entity.LinkCheck) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
-		r.logger.Debug("Execute sql request error", zap.Error(err))
-		return "", err
+		r.log(ctx).Debug("Can't begin transaction", zap.Error(err))
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, link := range links {
+		if _, err := tx.ExecContext(
+			ctx,
+			querySaveLinkCheck,
+			link.SongID,
+			link.Link,
+			link.Status,
+			link.StatusCode,
+			link.CheckedAt,
+		); err != nil {
+			r.log(ctx).Debug("Can't update field in table", zap.Error(err))
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		r.log(ctx).Debug("Can't commit transaction", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// saveSections заменяет секции текста песни в рамках транзакции.
+func (r *Repo) saveSections(ctx context.Context, tx *sql.Tx, songID int, sections []entity.Section) error {
+	if _, err := tx.ExecContext(ctx, queryDeleteSections, songID); err != nil {
+		r.log(ctx).Debug("Can't delete sections", zap.Error(err))
+		return err
 	}
 
-	return name, nil
+	for i, section := range sections {
+		if _, err := tx.ExecContext(
+			ctx,
+			querySaveSection,
+			songID,
+			i+1,
+			section.Type,
+			section.Label,
+			pq.Array(section.Lines),
+		); err != nil {
+			r.log(ctx).Debug("Can't insert section", zap.Error(err))
+			return err
+		}
+	}
+
+	return nil
+}
+
+// saveTimedLines заменяет синхронизированные строки текста песни в рамках транзакции.
+func (r *Repo) saveTimedLines(ctx context.Context, tx *sql.Tx, songID int, lines []entity.TimedLine) error {
+	if _, err := tx.ExecContext(ctx, queryDeleteTimedLines, songID); err != nil {
+		r.log(ctx).Debug("Can't delete timed lines", zap.Error(err))
+		return err
+	}
+
+	for i, line := range lines {
+		if _, err := tx.ExecContext(ctx, querySaveTimedLine, songID, i+1, line.TimeMS, line.Text); err != nil {
+			r.log(ctx).Debug("Can't insert timed line", zap.Error(err))
+			return err
+		}
+	}
+
+	return nil
+}
+
+// saveArtists заменяет исполнителей песни в рамках транзакции; основная группа
+// песни всегда идёт первой с ролью primary.
+func (r *Repo) saveArtists(ctx context.Context, tx *sql.Tx, songID, groupID int, artists []entity.ArtistDTO) error {
+	if _, err := tx.ExecContext(ctx, queryDeleteArtists, songID); err != nil {
+		r.log(ctx).Debug("Can't delete artists", zap.Error(err))
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, querySaveArtist, songID, groupID, entity.RolePrimary, 0); err != nil {
+		r.log(ctx).Debug("Can't insert artist", zap.Error(err))
+		return err
+	}
+
+	for i, artist := range artists {
+		if _, err := tx.ExecContext(ctx, querySaveArtist, songID, artist.GroupID, artist.Role, i+1); err != nil {
+			r.log(ctx).Debug("Can't insert artist", zap.Error(err))
+			return err
+		}
+	}
+
+	return nil
+}
+
+// saveMainArtist синхронизирует основную группу песни с её строкой primary в song_artists.
+func (r *Repo) saveMainArtist(ctx context.Context, tx *sql.Tx, songID, groupID int) error {
+	if _, err := tx.ExecContext(ctx, queryDeleteMainArtist, songID); err != nil {
+		r.log(ctx).Debug("Can't delete artist", zap.Error(err))
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, querySaveArtist, songID, groupID, entity.RolePrimary, 0); err != nil {
+		r.log(ctx).Debug("Can't insert artist", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// saveGenres заменяет жанры песни в рамках транзакции; жанр не из словаря -- bad request.
+func (r *Repo) saveGenres(ctx context.Context, tx *sql.Tx, songID int, genres []string) error {
+	if _, err := tx.ExecContext(ctx, queryDeleteGenres, songID); err != nil {
+		r.log(ctx).Debug("Can't delete genres", zap.Error(err))
+		return err
+	}
+
+	for _, genre := range genres {
+		res, err := tx.ExecContext(ctx, querySaveGenre, songID, genre)
+		if err != nil {
+			r.log(ctx).Debug("Can't insert genre", zap.Error(err))
+			return err
+		}
+
+		rows, err := res.RowsAffected()
+		if err != nil {
+			r.log(ctx).Debug("Failed to get rows affected", zap.Error(err))
+			return err
+		}
+		if rows == 0 {
+			r.log(ctx).Debug("Unknown genre", zap.String("genre", genre))
+			return errs.ErrBadRequest
+		}
+	}
+
+	return nil
 }
 
 // isDate проверяет, что формат даты (DD.MM.YYYY) был указан верно.
@@ -0,0 +1,80 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"testing"
+	"time"
+
+	"go-rest-api/pkg/postgres"
+
+	"go.uber.org/zap"
+)
+
+// blockingConnector -- база, в которой каждый запрос висит, пока не отменён его контекст.
+// started сообщает, что запрос дошёл до драйвера.
+type blockingConnector struct {
+	started chan struct{}
+}
+
+func (c blockingConnector) Connect(context.Context) (driver.Conn, error) {
+	return blockingConn(c), nil
+}
+
+func (c blockingConnector) Driver() driver.Driver { return nil }
+
+type blockingConn blockingConnector
+
+func (c blockingConn) QueryContext(ctx context.Context, _ string, _ []driver.NamedValue) (driver.Rows, error) {
+	c.started <- struct{}{}
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func (blockingConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (blockingConn) Close() error                        { return nil }
+func (blockingConn) Begin() (driver.Tx, error)           { return nil, errors.New("not supported") }
+
+func TestQueryCanceled(t *testing.T) {
+	tests := []struct {
+		name string
+		call func(ctx context.Context, r *Repo) error
+	}{
+		{"read", func(ctx context.Context, r *Repo) error {
+			_, err := r.SongExists(ctx, "Uprising")
+			return err
+		}},
+		{"write", func(ctx context.Context, r *Repo) error {
+			_, err := r.CreateGroup(ctx, "Muse")
+			return err
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			connector := blockingConnector{started: make(chan struct{}, 1)}
+			db := sql.OpenDB(connector)
+			defer db.Close()
+
+			r := &Repo{logger: zap.NewNop(), db: &postgres.DB{DB: db}}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			done := make(chan error, 1)
+			go func() { done <- tt.call(ctx, r) }()
+
+			<-connector.started
+			cancel()
+
+			select {
+			case err := <-done:
+				if !errors.Is(err, context.Canceled) {
+					t.Fatalf("got error %v, want %v", err, context.Canceled)
+				}
+			case <-time.After(time.Second):
+				t.Fatal("query was not aborted by cancel")
+			}
+		})
+	}
+}
@@ -0,0 +1,20 @@
+package repo
+
+const (
+	// id песни определяется при записи события; события песен, удалённых до записи партии, отбрасываются.
+	querySavePlays = "INSERT INTO song_plays (song_id, kind, created)" +
+		" SELECT s.id, e.kind, e.created FROM unnest($1::INT[], $2::TEXT[], $3::TIMESTAMPTZ[]) AS e(song_id, kind, created)" +
+		" JOIN songs s ON s.id = e.song_id AND s.deleted IS NULL;"
+
+	queryCountPopularSongs = "SELECT COUNT(DISTINCT p.song_id) FROM song_plays p JOIN songs s ON s.id = p.song_id" +
+		" WHERE p.created >= NOW() - make_interval(secs => $1) AND s.deleted IS NULL;"
+
+	queryGetPopularSongs = "SELECT s.\"name\", g.\"name\", s.release_date," +
+		" COUNT(*) FILTER (WHERE p.kind = 'play'), COUNT(*) FILTER (WHERE p.kind = 'read')" +
+		" FROM song_plays p JOIN songs s ON s.id = p.song_id JOIN music_groups g ON g.id = s.group_id" +
+		" WHERE p.created >= NOW() - make_interval(secs => $1) AND s.deleted IS NULL" +
+		" GROUP BY s.id, g.\"name\" ORDER BY COUNT(*) DESC, s.id LIMIT $2 OFFSET $3;"
+
+	// Популярность песни для сортировки списков: прослушивания и чтения за окно.
+	querySongPopularity = "(SELECT COUNT(*) FROM song_plays p WHERE p.song_id = s.id AND p.created >= NOW() - make_interval(secs => $%d))"
+)
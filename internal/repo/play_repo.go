@@ -0,0 +1,101 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"go-rest-api/internal/entity"
+
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+)
+
+// SavePlays записывает партию событий одним запросом; или возвращает ошибку.
+func (r *Repo) SavePlays(ctx context.Context, events []entity.PlayEvent) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	ids := make([]int, len(events))
+	kinds := make([]string, len(events))
+	created := make([]string, len(events))
+	for i, event := range events {
+		ids[i] = event.SongID
+		kinds[i] = event.Kind
+		created[i] = event.At.Format(time.RFC3339Nano)
+	}
+
+	if _, err := r.db.ExecContext(ctx, querySavePlays, pq.Array(ids), pq.Array(kinds), pq.Array(created)); err != nil {
+		r.log(ctx).Debug("Can't insert into DB", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// FindSongID возвращает id песни, если она есть в хранилище и не удалена (0 -- если нет); или возвращает ошибку.
+func (r *Repo) FindSongID(ctx context.Context, name string) (id int, err error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	err = r.db.QueryRowContext(ctx, queryFindSongID, name).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		r.log(ctx).Debug("Request did not return value")
+		return 0, nil
+	}
+	if err != nil {
+		r.log(ctx).Debug("Execute sql request error", zap.Error(err))
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// SongExists проверяет, что песня есть в хранилище и не удалена; или возвращает ошибку.
+func (r *Repo) SongExists(ctx context.Context, name string) (bool, error) {
+	id, err := r.FindSongID(ctx, name)
+	if err != nil {
+		return false, err
+	}
+
+	return id != 0, nil
+}
+
+// GetPopularSongs возвращает страницу песен, упорядоченных по числу прослушиваний и чтений за окно,
+// и общее число таких песен; или возвращает ошибку.
+func (r *Repo) GetPopularSongs(ctx context.Context, window time.Duration, limit, offset int) (songs []entity.PopularSong, total int, err error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if err = r.db.QueryRowContext(ctx, queryCountPopularSongs, window.Seconds()).Scan(&total); err != nil {
+		r.log(ctx).Debug("Execute sql request error", zap.Error(err))
+		return nil, 0, err
+	}
+	if total == 0 {
+		return nil, 0, nil
+	}
+
+	rows, err := r.db.QueryContext(ctx, queryGetPopularSongs, window.Seconds(), limit, offset)
+	if err != nil {
+		r.log(ctx).Debug("Execute sql request error", zap.Error(err))
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var song entity.PopularSong
+		if err := rows.Scan(&song.Name, &song.Group, &song.ReleaseDate, &song.Plays, &song.Reads); err != nil {
+			r.log(ctx).Debug("Rows scan error", zap.Error(err))
+			return nil, 0, err
+		}
+		songs = append(songs, song)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.log(ctx).Debug("Can't parse rows", zap.Error(err))
+		return nil, 0, err
+	}
+
+	return songs, total, nil
+}
@@ -0,0 +1,65 @@
+package repo
+
+import (
+	"fmt"
+	"strings"
+
+	"go-rest-api/internal/entity"
+)
+
+const (
+	queryCreatePlaylist = "INSERT INTO playlists (\"name\", description) VALUES ($1, $2) RETURNING id;"
+
+	queryGetPlaylist = "SELECT p.id, p.\"name\", p.description, to_char(p.created, 'YYYY-MM-DD\"T\"HH24:MI:SS'), (SELECT COUNT(*) FROM playlist_entries e WHERE e.playlist_id = p.id) FROM playlists p WHERE p.id = $1 AND p.deleted IS NULL;"
+
+	queryCountPlaylists = "SELECT COUNT(*) FROM playlists WHERE deleted IS NULL;"
+
+	queryGetPlaylists = "SELECT p.id, p.\"name\", p.description, to_char(p.created, 'YYYY-MM-DD\"T\"HH24:MI:SS'), (SELECT COUNT(*) FROM playlist_entries e WHERE e.playlist_id = p.id) FROM playlists p WHERE p.deleted IS NULL ORDER BY p.id LIMIT $1 OFFSET $2;"
+
+	queryDeletePlaylist = "UPDATE playlists SET deleted = NOW() WHERE id = $1 AND deleted IS NULL;"
+
+	queryLockPlaylist = "SELECT id FROM playlists WHERE id = $1 AND deleted IS NULL FOR UPDATE;"
+
+	queryCountPlaylistEntries = "SELECT COUNT(*) FROM playlist_entries WHERE playlist_id = $1;"
+
+	// Удалённые песни остаются в плейлисте, но помечаются недоступными.
+	queryGetPlaylistEntries = "SELECT e.position, s.id, s.\"name\", g.\"name\", s.deleted IS NULL FROM playlist_entries e JOIN songs s ON s.id = e.song_id JOIN music_groups g ON g.id = s.group_id WHERE e.playlist_id = $1 ORDER BY e.position LIMIT $2 OFFSET $3;"
+
+	queryGetPlaylistSongIDs = "SELECT song_id FROM playlist_entries WHERE playlist_id = $1;"
+
+	queryFindPlaylistEntry = "SELECT position FROM playlist_entries WHERE playlist_id = $1 AND song_id = $2;"
+
+	queryFindActiveSong = "SELECT EXISTS (SELECT 1 FROM songs WHERE id = $1 AND deleted IS NULL);"
+
+	queryShiftPlaylistEntries = "UPDATE playlist_entries SET position = position + $2 WHERE playlist_id = $1 AND position BETWEEN $3 AND $4;"
+
+	queryAddPlaylistEntry = "INSERT INTO playlist_entries (playlist_id, song_id, position) VALUES ($1, $2, $3);"
+
+	querySetPlaylistEntryPosition = "UPDATE playlist_entries SET position = $3 WHERE playlist_id = $1 AND song_id = $2;"
+
+	queryDeletePlaylistEntry = "DELETE FROM playlist_entries WHERE playlist_id = $1 AND song_id = $2;"
+)
+
+func (r *Repo) queryUpdatePlaylist(playlist entity.Playlist, id int) (string, []interface{}) {
+	var str []string
+	var args []interface{}
+	argIndex := 1
+
+	if playlist.Name != nil {
+		str = append(str, fmt.Sprintf("\"name\" = $%d", argIndex))
+		args = append(args, *playlist.Name)
+		argIndex++
+	}
+	if playlist.Description != nil {
+		str = append(str, fmt.Sprintf("description = $%d", argIndex))
+		args = append(args, *playlist.Description)
+		argIndex++
+	}
+
+	if len(str) == 0 {
+		return "", nil
+	}
+
+	args = append(args, id)
+	return "UPDATE playlists SET " + strings.Join(str, ", ") + fmt.Sprintf(" WHERE id = $%d AND deleted IS NULL;", argIndex), args
+}
@@ -0,0 +1,450 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"go-rest-api/internal/entity"
+	"go-rest-api/internal/errs"
+
+	"go.uber.org/zap"
+)
+
+// CreatePlaylist создаёт плейлист и возвращает id; или возвращает ошибку.
+func (r *Repo) CreatePlaylist(ctx context.Context, playlist entity.Playlist) (id int, err error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	err = r.db.QueryRowContext(ctx, queryCreatePlaylist, playlist.Name, playlist.Description).Scan(&id)
+	if err != nil {
+		r.log(ctx).Debug("Can't insert into DB", zap.Error(err))
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// GetPlaylist возвращает плейлист по id (nil -- если плейлиста нет) или ошибку.
+func (r *Repo) GetPlaylist(ctx context.Context, id int) (*entity.Playlist, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	playlist, err := scanPlaylist(r.db.QueryRowContext(ctx, queryGetPlaylist, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		r.log(ctx).Debug("Request did not return value")
+		return nil, nil
+	}
+	if err != nil {
+		r.log(ctx).Debug("Execute sql request error", zap.Error(err))
+		return nil, err
+	}
+
+	return &playlist, nil
+}
+
+// GetPlaylists возвращает страницу плейлистов и их общее число; или возвращает ошибку.
+func (r *Repo) GetPlaylists(ctx context.Context, limit, offset int) (playlists []entity.Playlist, total int, err error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if err = r.db.QueryRowContext(ctx, queryCountPlaylists).Scan(&total); err != nil {
+		r.log(ctx).Debug("Execute sql request error", zap.Error(err))
+		return nil, 0, err
+	}
+	if total == 0 {
+		return nil, 0, nil
+	}
+
+	rows, err := r.db.QueryContext(ctx, queryGetPlaylists, limit, offset)
+	if err != nil {
+		r.log(ctx).Debug("Execute sql request error", zap.Error(err))
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		playlist, err := scanPlaylist(rows)
+		if err != nil {
+			r.log(ctx).Debug("Rows scan error", zap.Error(err))
+			return nil, 0, err
+		}
+		playlists = append(playlists, playlist)
+	}
+
+	if err = rows.Err(); err != nil {
+		r.log(ctx).Debug("Can't parse rows", zap.Error(err))
+		return nil, 0, err
+	}
+
+	return playlists, total, nil
+}
+
+// UpdatePlaylist обновляет плейлист по id и возвращает bool; или возвращает ошибку.
+func (r *Repo) UpdatePlaylist(ctx context.Context, id int, playlist entity.Playlist) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	query, args := r.queryUpdatePlaylist(playlist, id)
+	if query == "" {
+		errMsg := "query is empty"
+		r.log(ctx).Debug("Incorrect query", zap.Error(fmt.Errorf("%v", errMsg)))
+		return false, errs.ErrBadRequest
+	}
+
+	res, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		r.log(ctx).Debug("Can't update field in table", zap.Error(err))
+		return false, err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		r.log(ctx).Debug("Failed to get rows affected", zap.Error(err))
+		return false, err
+	}
+
+	if rows == 0 {
+		r.log(ctx).Debug("Playlist is not exist", zap.Int("playlist_id", id))
+	}
+
+	return rows > 0, nil
+}
+
+// DeletePlaylist помечает плейлист удалённым и возвращает bool; или возвращает ошибку.
+func (r *Repo) DeletePlaylist(ctx context.Context, id int) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	res, err := r.db.ExecContext(ctx, queryDeletePlaylist, id)
+	if err != nil {
+		r.log(ctx).Debug("Can't update field in table", zap.Error(err))
+		return false, err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		r.log(ctx).Debug("Failed to get rows affected", zap.Error(err))
+		return false, err
+	}
+
+	if rows == 0 {
+		r.log(ctx).Debug("Playlist is not exist", zap.Int("playlist_id", id))
+	}
+
+	return rows > 0, nil
+}
+
+// GetPlaylistEntries возвращает страницу записей плейлиста по порядку и их общее число; или возвращает ошибку.
+func (r *Repo) GetPlaylistEntries(ctx context.Context, id, limit, offset int) (entries []entity.PlaylistEntry, total int, err error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if err = r.db.QueryRowContext(ctx, queryCountPlaylistEntries, id).Scan(&total); err != nil {
+		r.log(ctx).Debug("Execute sql request error", zap.Error(err))
+		return nil, 0, err
+	}
+	if total == 0 {
+		return nil, 0, nil
+	}
+
+	rows, err := r.db.QueryContext(ctx, queryGetPlaylistEntries, id, limit, offset)
+	if err != nil {
+		r.log(ctx).Debug("Execute sql request error", zap.Error(err))
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var entry entity.PlaylistEntry
+		if err := rows.Scan(&entry.Position, &entry.SongID, &entry.Name, &entry.Group, &entry.Available); err != nil {
+			r.log(ctx).Debug("Rows scan error", zap.Error(err))
+			return nil, 0, err
+		}
+		entries = append(entries, entry)
+	}
+
+	if err = rows.Err(); err != nil {
+		r.log(ctx).Debug("Can't parse rows", zap.Error(err))
+		return nil, 0, err
+	}
+
+	return entries, total, nil
+}
+
+/*
+AddPlaylistEntry добавляет песню в плейлист на позицию position (0 или больше длины
+плейлиста -- в конец) и возвращает итоговую позицию; или возвращает ошибку:
+not found -- если плейлиста нет, bad request -- если песни нет или она удалена,
+conflict -- если песня уже есть в плейлисте.
+*/
+func (r *Repo) AddPlaylistEntry(ctx context.Context, id, songID, position int) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		r.log(ctx).Debug("Can't begin transaction", zap.Error(err))
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	size, err := r.lockPlaylist(ctx, tx, id)
+	if err != nil {
+		return 0, err
+	}
+
+	var exists bool
+	if err := tx.QueryRowContext(ctx, queryFindActiveSong, songID).Scan(&exists); err != nil {
+		r.log(ctx).Debug("Execute sql request error", zap.Error(err))
+		return 0, err
+	}
+	if !exists {
+		r.log(ctx).Debug("Song is not exist", zap.Int("song_id", songID))
+		return 0, errs.ErrBadRequest
+	}
+
+	if _, err := findEntryPosition(ctx, tx, id, songID); err == nil {
+		r.log(ctx).Debug("Song is already in playlist", zap.Int("playlist_id", id), zap.Int("song_id", songID))
+		return 0, errs.ErrConflict
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		r.log(ctx).Debug("Execute sql request error", zap.Error(err))
+		return 0, err
+	}
+
+	if position <= 0 || position > size {
+		position = size + 1
+	}
+
+	if _, err := tx.ExecContext(ctx, queryShiftPlaylistEntries, id, 1, position, size); err != nil {
+		r.log(ctx).Debug("Can't shift playlist entries", zap.Error(err))
+		return 0, err
+	}
+	if _, err := tx.ExecContext(ctx, queryAddPlaylistEntry, id, songID, position); err != nil {
+		r.log(ctx).Debug("Can't insert into DB", zap.Error(err))
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		r.log(ctx).Debug("Can't commit transaction", zap.Error(err))
+		return 0, err
+	}
+
+	return position, nil
+}
+
+// MovePlaylistEntry переносит песню плейлиста на позицию position (больше длины -- в конец),
+// сдвигая записи между старой и новой позицией; возвращает итоговую позицию или ошибку.
+func (r *Repo) MovePlaylistEntry(ctx context.Context, id, songID, position int) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		r.log(ctx).Debug("Can't begin transaction", zap.Error(err))
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	size, err := r.lockPlaylist(ctx, tx, id)
+	if err != nil {
+		return 0, err
+	}
+
+	current, err := findEntryPosition(ctx, tx, id, songID)
+	if errors.Is(err, sql.ErrNoRows) {
+		r.log(ctx).Debug("Song is not in playlist", zap.Int("playlist_id", id), zap.Int("song_id", songID))
+		return 0, errs.ErrNotFound
+	}
+	if err != nil {
+		r.log(ctx).Debug("Execute sql request error", zap.Error(err))
+		return 0, err
+	}
+
+	position = max(min(position, size), 1)
+	if position == current {
+		return position, nil
+	}
+
+	if position < current {
+		_, err = tx.ExecContext(ctx, queryShiftPlaylistEntries, id, 1, position, current-1)
+	} else {
+		_, err = tx.ExecContext(ctx, queryShiftPlaylistEntries, id, -1, current+1, position)
+	}
+	if err != nil {
+		r.log(ctx).Debug("Can't shift playlist entries", zap.Error(err))
+		return 0, err
+	}
+
+	if _, err := tx.ExecContext(ctx, querySetPlaylistEntryPosition, id, songID, position); err != nil {
+		r.log(ctx).Debug("Can't update field in table", zap.Error(err))
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		r.log(ctx).Debug("Can't commit transaction", zap.Error(err))
+		return 0, err
+	}
+
+	return position, nil
+}
+
+// DeletePlaylistEntry убирает песню из плейлиста и закрывает пропуск в позициях; или возвращает ошибку.
+func (r *Repo) DeletePlaylistEntry(ctx context.Context, id, songID int) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		r.log(ctx).Debug("Can't begin transaction", zap.Error(err))
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := r.lockPlaylist(ctx, tx, id); err != nil {
+		return err
+	}
+
+	current, err := findEntryPosition(ctx, tx, id, songID)
+	if errors.Is(err, sql.ErrNoRows) {
+		r.log(ctx).Debug("Song is not in playlist", zap.Int("playlist_id", id), zap.Int("song_id", songID))
+		return errs.ErrNotFound
+	}
+	if err != nil {
+		r.log(ctx).Debug("Execute sql request error", zap.Error(err))
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, queryDeletePlaylistEntry, id, songID); err != nil {
+		r.log(ctx).Debug("Can't delete from table", zap.Error(err))
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, queryShiftPlaylistEntries, id, -1, current+1, math.MaxInt32); err != nil {
+		r.log(ctx).Debug("Can't shift playlist entries", zap.Error(err))
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		r.log(ctx).Debug("Can't commit transaction", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// SetPlaylistOrder задаёт новый порядок песен плейлиста. Список должен содержать
+// ровно те песни, что уже есть в плейлисте; иначе возвращает bad request.
+func (r *Repo) SetPlaylistOrder(ctx context.Context, id int, songIDs []int) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		r.log(ctx).Debug("Can't begin transaction", zap.Error(err))
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := r.lockPlaylist(ctx, tx, id); err != nil {
+		return err
+	}
+
+	rows, err := tx.QueryContext(ctx, queryGetPlaylistSongIDs, id)
+	if err != nil {
+		r.log(ctx).Debug("Execute sql request error", zap.Error(err))
+		return err
+	}
+
+	current := make(map[int]bool)
+	for rows.Next() {
+		var songID int
+		if err := rows.Scan(&songID); err != nil {
+			rows.Close()
+			r.log(ctx).Debug("Rows scan error", zap.Error(err))
+			return err
+		}
+		current[songID] = true
+	}
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		r.log(ctx).Debug("Can't parse rows", zap.Error(err))
+		return err
+	}
+
+	if len(songIDs) != len(current) {
+		r.log(ctx).Debug("Playlist order does not match entries", zap.Int("playlist_id", id))
+		return errs.ErrBadRequest
+	}
+	for _, songID := range songIDs {
+		if !current[songID] {
+			r.log(ctx).Debug("Song is not in playlist", zap.Int("playlist_id", id), zap.Int("song_id", songID))
+			return errs.ErrBadRequest
+		}
+	}
+
+	for i, songID := range songIDs {
+		if _, err := tx.ExecContext(ctx, querySetPlaylistEntryPosition, id, songID, i+1); err != nil {
+			r.log(ctx).Debug("Can't update field in table", zap.Error(err))
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		r.log(ctx).Debug("Can't commit transaction", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// lockPlaylist блокирует плейлист до конца транзакции, чтобы параллельные правки
+// не перепутали позиции, и возвращает число его записей (not found -- если плейлиста нет).
+func (r *Repo) lockPlaylist(ctx context.Context, tx *sql.Tx, id int) (size int, err error) {
+	err = tx.QueryRowContext(ctx, queryLockPlaylist, id).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		r.log(ctx).Debug("Playlist is not exist", zap.Int("playlist_id", id))
+		return 0, errs.ErrNotFound
+	}
+	if err != nil {
+		r.log(ctx).Debug("Execute sql request error", zap.Error(err))
+		return 0, err
+	}
+
+	if err = tx.QueryRowContext(ctx, queryCountPlaylistEntries, id).Scan(&size); err != nil {
+		r.log(ctx).Debug("Execute sql request error", zap.Error(err))
+		return 0, err
+	}
+
+	return size, nil
+}
+
+func findEntryPosition(ctx context.Context, tx *sql.Tx, id, songID int) (position int, err error) {
+	err = tx.QueryRowContext(ctx, queryFindPlaylistEntry, id, songID).Scan(&position)
+	return position, err
+}
+
+func scanPlaylist(row rowScanner) (entity.Playlist, error) {
+	var id, size int
+	var name, created string
+	var description sql.NullString
+
+	if err := row.Scan(&id, &name, &description, &created, &size); err != nil {
+		return entity.Playlist{}, err
+	}
+
+	playlist := entity.Playlist{
+		ID:      &id,
+		Name:    &name,
+		Size:    &size,
+		Created: &created,
+	}
+	if description.Valid {
+		playlist.Description = &description.String
+	}
+
+	return playlist, nil
+}
@@ -0,0 +1,385 @@
+package catalog
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"go-rest-api/internal/entity"
+	"go-rest-api/internal/errs"
+)
+
+const (
+	FormatNDJSON = "ndjson"
+	FormatCSV    = "csv"
+
+	ContentTypeNDJSON = "application/x-ndjson"
+	ContentTypeCSV    = "text/csv"
+)
+
+// header -- колонки CSV. Списки (text, artists, genres, tags) хранятся в ячейке JSON-массивом:
+// куплеты сами могут содержать пустые строки, поэтому разделитель внутри ячейки не подходит.
+var header = []string{"group", "song", "release_date", "text", "link", "artists", "genres", "tags", "lang"}
+
+type (
+	Reader interface {
+		// Read возвращает очередную строку каталога; io.EOF -- строк больше нет.
+		Read() (entity.ImportSong, error)
+	}
+
+	Writer interface {
+		Write(entity.ImportSong) error
+		Flush() error
+	}
+
+	// RowError -- ошибка разбора одной строки; чтение можно продолжить.
+	RowError struct {
+		Err error
+	}
+)
+
+func (e *RowError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *RowError) Unwrap() error {
+	return e.Err
+}
+
+// FormatByContentType возвращает формат по заголовку Content-Type или пустую строку.
+func FormatByContentType(contentType string) string {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	switch strings.TrimSpace(strings.ToLower(mediaType)) {
+	case ContentTypeNDJSON, "application/ndjson", "application/jsonl":
+		return FormatNDJSON
+	case ContentTypeCSV, "application/csv":
+		return FormatCSV
+	}
+	return ""
+}
+
+// ContentType возвращает Content-Type для формата.
+func ContentType(format string) string {
+	if format == FormatCSV {
+		return ContentTypeCSV
+	}
+	return ContentTypeNDJSON
+}
+
+// NewReader создаёт построчный reader для формата или возвращает ошибку.
+func NewReader(format string, r io.Reader) (Reader, error) {
+	switch format {
+	case FormatNDJSON:
+		return &ndjsonReader{scanner: newScanner(r)}, nil
+
+	case FormatCSV:
+		cr := csv.NewReader(r)
+		cr.TrimLeadingSpace = true
+
+		cols, err := cr.Read()
+		if err != nil {
+			return nil, fmt.Errorf("read csv header: %w", err)
+		}
+
+		index := make(map[string]int, len(cols))
+		for i, col := range cols {
+			index[strings.TrimSpace(strings.ToLower(col))] = i
+		}
+		for _, col := range header[:2] {
+			if _, ok := index[col]; !ok {
+				return nil, fmt.Errorf("csv header: missing column %q", col)
+			}
+		}
+
+		cr.FieldsPerRecord = len(cols)
+		return &csvReader{reader: cr, index: index}, nil
+
+	default:
+		return nil, fmt.Errorf("unknown format %q", format)
+	}
+}
+
+// NewWriter создаёт построчный writer для формата или возвращает ошибку.
+func NewWriter(format string, w io.Writer) (Writer, error) {
+	switch format {
+	case FormatNDJSON:
+		bw := bufio.NewWriter(w)
+		return &ndjsonWriter{buf: bw, enc: json.NewEncoder(bw)}, nil
+
+	case FormatCSV:
+		cw := csv.NewWriter(w)
+		if err := cw.Write(header); err != nil {
+			return nil, err
+		}
+		return &csvWriter{writer: cw}, nil
+
+	default:
+		return nil, fmt.Errorf("unknown format %q", format)
+	}
+}
+
+// FromSong приводит песню к строке каталога.
+func FromSong(song entity.Song) entity.ImportSong {
+	var row entity.ImportSong
+	if song.Group != nil {
+		row.Group = *song.Group
+	}
+	if song.Name != nil {
+		row.Name = *song.Name
+	}
+	if song.ReleaseDate != nil {
+		row.ReleaseDate = *song.ReleaseDate
+	}
+	if song.Text != nil {
+		row.Text = *song.Text
+	}
+	if song.Link != nil {
+		row.Link = *song.Link
+	}
+	if song.Artists != nil {
+		row.Artists = *song.Artists
+	}
+	if song.Genres != nil {
+		row.Genres = *song.Genres
+	}
+	if song.Tags != nil {
+		row.Tags = *song.Tags
+	}
+	if song.Lang != nil {
+		row.Lang = *song.Lang
+	}
+	return row
+}
+
+type ndjsonReader struct {
+	scanner *bufio.Scanner
+}
+
+func (r *ndjsonReader) Read() (row entity.ImportSong, err error) {
+	for r.scanner.Scan() {
+		line := strings.TrimSpace(r.scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		if err = json.Unmarshal([]byte(line), &row); err != nil {
+			return entity.ImportSong{}, &RowError{Err: err}
+		}
+		return row, nil
+	}
+
+	if err = r.scanner.Err(); err != nil {
+		return entity.ImportSong{}, err
+	}
+	return entity.ImportSong{}, io.EOF
+}
+
+type csvReader struct {
+	reader *csv.Reader
+	index  map[string]int
+}
+
+func (r *csvReader) Read() (entity.ImportSong, error) {
+	record, err := r.reader.Read()
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		return entity.ImportSong{}, &RowError{Err: err}
+	}
+	if err != nil {
+		return entity.ImportSong{}, err
+	}
+
+	field := func(name string) string {
+		if i, ok := r.index[name]; ok {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	row := entity.ImportSong{
+		Group:       field("group"),
+		Name:        field("song"),
+		ReleaseDate: field("release_date"),
+		Link:        field("link"),
+		Lang:        field("lang"),
+	}
+
+	lists := []struct {
+		name string
+		dst  any
+	}{
+		{"text", &row.Text},
+		{"artists", &row.Artists},
+		{"genres", &row.Genres},
+		{"tags", &row.Tags},
+	}
+	for _, list := range lists {
+		cell := field(list.name)
+		if cell == "" {
+			continue
+		}
+		if err := json.Unmarshal([]byte(cell), list.dst); err != nil {
+			return entity.ImportSong{}, &RowError{Err: fmt.Errorf("column %s: %w", list.name, err)}
+		}
+	}
+
+	return row, nil
+}
+
+type ndjsonWriter struct {
+	buf *bufio.Writer
+	enc *json.Encoder
+}
+
+func (w *ndjsonWriter) Write(row entity.ImportSong) error {
+	return w.enc.Encode(row)
+}
+
+func (w *ndjsonWriter) Flush() error {
+	return w.buf.Flush()
+}
+
+type csvWriter struct {
+	writer *csv.Writer
+}
+
+func (w *csvWriter) Write(row entity.ImportSong) error {
+	cells := make([]string, 4)
+	for i, list := range []any{row.Text, row.Artists, row.Genres, row.Tags} {
+		var err error
+		if cells[i], err = jsonCell(list); err != nil {
+			return err
+		}
+	}
+	text, artists, genres, tags := cells[0], cells[1], cells[2], cells[3]
+
+	return w.writer.Write([]string{
+		row.Group,
+		row.Name,
+		row.ReleaseDate,
+		text,
+		row.Link,
+		artists,
+		genres,
+		tags,
+		row.Lang,
+	})
+}
+
+// jsonCell кодирует список в CSV-ячейку; пустой список -- пустая ячейка.
+func jsonCell(list any) (string, error) {
+	b, err := json.Marshal(list)
+	if err != nil {
+		return "", err
+	}
+	if s := string(b); s != "null" && s != "[]" {
+		return s, nil
+	}
+	return "", nil
+}
+
+func (w *csvWriter) Flush() error {
+	w.writer.Flush()
+	return w.writer.Error()
+}
+
+// newScanner создаёт scanner с буфером, достаточным для длинного текста песни.
+func newScanner(r io.Reader) *bufio.Scanner {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	return scanner
+}
+
+const (
+	StatusImported = "imported"
+	StatusSkipped  = "skipped"
+	StatusFailed   = "failed"
+)
+
+var ErrTooManyRows = errors.New("too many rows in batch")
+
+// Import читает строки из r и передаёт каждую в fn, собирая отчёт по строкам.
+// limit ограничивает размер пакета (0 -- без ограничения).
+// Пакет сначала читается целиком: слишком большой или нечитаемый пакет отклоняется
+// до импорта первой строки, а не на середине, когда часть строк уже сохранена.
+func Import(r Reader, limit int, fn func(entity.ImportSong) (bool, error)) (entity.ImportReport, error) {
+	report := entity.ImportReport{Results: []entity.ImportResult{}}
+
+	type readRow struct {
+		row entity.ImportSong
+		err error
+	}
+	var rows []readRow
+
+	for {
+		row, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		var rowErr *RowError
+		if err != nil && !errors.As(err, &rowErr) {
+			return report, err
+		}
+
+		if limit > 0 && len(rows) == limit {
+			return report, ErrTooManyRows
+		}
+		rows = append(rows, readRow{row: row, err: err})
+	}
+
+	for i, read := range rows {
+		row, err := read.row, read.err
+		rowNum := i + 1
+
+		result := entity.ImportResult{
+			Row:   rowNum,
+			Group: row.Group,
+			Name:  row.Name,
+		}
+
+		if err == nil {
+			var imported bool
+			imported, err = fn(row)
+			if imported {
+				result.Status = StatusImported
+			} else if err == nil {
+				result.Status = StatusSkipped
+			}
+		}
+
+		if err != nil {
+			result.Status = StatusFailed
+			result.Error = rowErrorMsg(err)
+		}
+
+		report.Total++
+		switch result.Status {
+		case StatusImported:
+			report.Imported++
+		case StatusFailed:
+			report.Failed++
+		}
+		report.Results = append(report.Results, result)
+	}
+
+	return report, nil
+}
+
+// rowErrorMsg не отдаёт наружу текст внутренних ошибок (например, от БД).
+func rowErrorMsg(err error) string {
+	var appErr *errs.AppError
+	var rowErr *RowError
+	switch {
+	case errors.As(err, &rowErr):
+		return "malformed row: " + rowErr.Error()
+	case errors.As(err, &appErr):
+		return appErr.Msg
+	default:
+		return errs.ErrInternal.Msg
+	}
+}
@@ -0,0 +1,267 @@
+package catalog
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"go-rest-api/internal/entity"
+	"go-rest-api/internal/errs"
+)
+
+func TestRoundTrip(t *testing.T) {
+	rows := []entity.ImportSong{
+		{
+			Group:       "Muse",
+			Name:        "Uprising",
+			ReleaseDate: "16.07.2009",
+			// Куплет с пустой строкой внутри, запятыми и кавычками.
+			Text: []string{"Paranoia is in bloom,\n\nThe PR transmissions will resume", `They'll try to "push" drugs`},
+			Link: "https://example.com/uprising",
+			Artists: []entity.Artist{
+				{Name: "Muse", Role: entity.RolePrimary},
+				{Name: "Matthew Bellamy", Role: entity.RoleLyricist},
+			},
+			Genres: []string{"rock"},
+			Tags:   []string{"2000s", "live"},
+			Lang:   "en",
+		},
+		{Group: "Кино", Name: "Группа крови"},
+	}
+
+	for _, format := range []string{FormatNDJSON, FormatCSV} {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+			w, err := NewWriter(format, &buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, row := range rows {
+				if err := w.Write(row); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := w.Flush(); err != nil {
+				t.Fatal(err)
+			}
+
+			r, err := NewReader(format, &buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []entity.ImportSong
+			for {
+				row, err := r.Read()
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+				got = append(got, row)
+			}
+
+			if !reflect.DeepEqual(got, rows) {
+				t.Errorf("round trip changed rows:\ngot  %+v\nwant %+v", got, rows)
+			}
+		})
+	}
+}
+
+func TestFromSong(t *testing.T) {
+	name, group, date, link, lang := "Uprising", "Muse", "16.07.2009", "https://example.com/uprising", "en"
+	text := []string{"Paranoia is in bloom"}
+	artists := []entity.Artist{{Name: "Muse", Role: entity.RolePrimary}}
+	genres, tags := []string{"rock"}, []string{"live"}
+
+	got := FromSong(entity.Song{
+		Name: &name, Group: &group, ReleaseDate: &date, Text: &text, Link: &link,
+		Artists: &artists, Genres: &genres, Tags: &tags, Lang: &lang,
+	})
+
+	want := entity.ImportSong{
+		Group: group, Name: name, ReleaseDate: date, Text: text, Link: link,
+		Artists: artists, Genres: genres, Tags: tags, Lang: lang,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestImportMalformedRows(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		input  string
+		status []string
+	}{
+		{
+			"ndjson invalid json", FormatNDJSON,
+			"{\"group\":\"Muse\",\"song\":\"a\"}\n{\"group\":\n\n{\"group\":\"Muse\",\"song\":\"b\"}\n",
+			[]string{StatusImported, StatusFailed, StatusImported},
+		},
+		{
+			"ndjson wrong field type", FormatNDJSON,
+			"{\"group\":\"Muse\",\"song\":\"a\",\"text\":\"not an array\"}\n",
+			[]string{StatusFailed},
+		},
+		{
+			"csv wrong field count", FormatCSV,
+			"group,song\nMuse,a\nMuse,b,extra\nMuse,c\n",
+			[]string{StatusImported, StatusFailed, StatusImported},
+		},
+		{
+			"csv text is not a json array", FormatCSV,
+			"group,song,text\nMuse,a,plain text\nMuse,b,\"[\"\"verse\"\"]\"\n",
+			[]string{StatusFailed, StatusImported},
+		},
+		{
+			"csv bad artists", FormatCSV,
+			"group,song,artists\nMuse,a,\"[{\"\"name\"\":1}]\"\n",
+			[]string{StatusFailed},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := NewReader(tt.format, strings.NewReader(tt.input))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			report, err := Import(r, 0, func(entity.ImportSong) (bool, error) { return true, nil })
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var status []string
+			for _, result := range report.Results {
+				status = append(status, result.Status)
+				if result.Status == StatusFailed && !strings.HasPrefix(result.Error, "malformed row: ") {
+					t.Errorf("row %d: got error %q, want malformed row", result.Row, result.Error)
+				}
+			}
+			if !reflect.DeepEqual(status, tt.status) {
+				t.Errorf("got statuses %v, want %v", status, tt.status)
+			}
+		})
+	}
+}
+
+func TestNewReaderRejectsBadCSVHeader(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"empty", ""},
+		{"missing song", "group,text\nMuse,x\n"},
+		{"unterminated quote", "\"group,song\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewReader(FormatCSV, strings.NewReader(tt.input)); err == nil {
+				t.Error("bad header accepted")
+			}
+		})
+	}
+}
+
+func TestImportTooManyRows(t *testing.T) {
+	input := strings.Repeat("{\"group\":\"Muse\",\"song\":\"a\"}\n", 3)
+
+	tests := []struct {
+		name  string
+		limit int
+		err   error
+	}{
+		{"over limit", 2, ErrTooManyRows},
+		{"at limit", 3, nil},
+		{"no limit", 0, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := NewReader(FormatNDJSON, strings.NewReader(input))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			calls := 0
+			_, err = Import(r, tt.limit, func(entity.ImportSong) (bool, error) {
+				calls++
+				return true, nil
+			})
+
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("got error %v, want %v", err, tt.err)
+			}
+			if tt.err != nil && calls != 0 {
+				t.Errorf("%d rows imported from a rejected batch", calls)
+			}
+		})
+	}
+}
+
+func TestRowErrorMsg(t *testing.T) {
+	internal := errors.New("pq: duplicate key value violates unique constraint \"songs_pkey\"")
+
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"malformed row", &RowError{Err: errors.New("unexpected end of JSON input")}, "malformed row: unexpected end of JSON input"},
+		{"app error", errs.NewAppError(nil, "missing song name"), "missing song name"},
+		{"app error hides cause", errs.NewAppError(internal, "invalid link"), "invalid link"},
+		{"wrapped app error", fmt.Errorf("import: %w", errs.ErrBadRequest), errs.ErrBadRequest.Msg},
+		{"internal error", internal, errs.ErrInternal.Msg},
+		{"wrapped internal error", fmt.Errorf("save song: %w", internal), errs.ErrInternal.Msg},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := rowErrorMsg(tt.err); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestImportReportsRowErrors(t *testing.T) {
+	input := "{\"group\":\"Muse\",\"song\":\"a\"}\n{\"group\":\"Muse\",\"song\":\"b\"}\n{\"group\":\"Muse\",\"song\":\"c\"}\n"
+	r, err := NewReader(FormatNDJSON, strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := Import(r, 0, func(row entity.ImportSong) (bool, error) {
+		switch row.Name {
+		case "a":
+			return true, nil
+		case "b":
+			return false, nil
+		default:
+			return false, errors.New("connection reset by peer")
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := entity.ImportReport{
+		Total: 3, Imported: 1, Failed: 1,
+		Results: []entity.ImportResult{
+			{Row: 1, Group: "Muse", Name: "a", Status: StatusImported},
+			{Row: 2, Group: "Muse", Name: "b", Status: StatusSkipped},
+			{Row: 3, Group: "Muse", Name: "c", Status: StatusFailed, Error: errs.ErrInternal.Msg},
+		},
+	}
+	if !reflect.DeepEqual(report, want) {
+		t.Errorf("got %+v, want %+v", report, want)
+	}
+}
@@ -11,6 +11,8 @@ import (
 	"go-rest-api/internal/errs"
 	rw "go-rest-api/internal/transport/http/v1/handler"
 	"go-rest-api/pkg/logger"
+	"go-rest-api/pkg/postgres"
+	"go-rest-api/pkg/requestid"
 
 	"go.uber.org/zap"
 )
@@ -18,8 +20,18 @@ import (
 type appHandler func(w http.ResponseWriter, r *http.Request) *errs.AppError
 
 func Wrap(ctx context.Context, h appHandler) http.HandlerFunc {
+	return wrap(ctx, h, true)
+}
+
+// WrapStream -- Wrap для больших тел (пакетный импорт): тело не буферизуется и не пишется в лог,
+// хендлер читает его потоком сам.
+func WrapStream(ctx context.Context, h appHandler) http.HandlerFunc {
+	return wrap(ctx, h, false)
+}
+
+func wrap(ctx context.Context, h appHandler, logBody bool) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		logger := logger.FromContext(ctx)
+		logger := logger.Request(r.Context(), logger.FromContext(ctx))
 		apiKey := config.FromContext(ctx).App.ApiKey
 
 		w.Header().Set("Content-Type", "application/json")
@@ -31,58 +43,71 @@ func Wrap(ctx context.Context, h appHandler) http.HandlerFunc {
 
 		default:
 			logger.Error("Unauthorized access", zap.String("api_key", key))
-			w.WriteHeader(http.StatusUnauthorized)
-			json.NewEncoder(w).Encode(rw.Wrap(errs.ErrUnauthorized))
+			writeError(w, r, http.StatusUnauthorized, errs.ErrUnauthorized)
 			return
 		}
 
-		bodyBytes, err := io.ReadAll(r.Body)
-		if err != nil {
-			logger.Error("Failed to read request body", zap.Error(err))
-			w.WriteHeader(http.StatusBadRequest)
-			json.NewEncoder(w).Encode(rw.Wrap(errs.ErrIncorrectBody))
-			return
-		}
-		r.Body = io.NopCloser(bytes.NewBuffer(bodyBytes))
-
-		logger.Info("Handling request",
+		fields := []zap.Field{
 			zap.String("remote_addr", r.RemoteAddr),
 			zap.String("method", r.Method),
 			zap.String("url", r.URL.String()),
-			zap.String("body", string(bodyBytes)))
+		}
+		if logBody {
+			bodyBytes, err := io.ReadAll(r.Body)
+			if err != nil {
+				logger.Error("Failed to read request body", zap.Error(err))
+				writeError(w, r, http.StatusBadRequest, errs.ErrIncorrectBody)
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewBuffer(bodyBytes))
+			fields = append(fields, zap.String("body", string(bodyBytes)))
+		}
+		// После записи в этом запросе чтения идут в основную базу, а не на реплики.
+		r = r.WithContext(postgres.WithSession(r.Context()))
+
+		logger.Info("Handling request", fields...)
 
 		if err := h(w, r); err != nil {
 			switch err {
 			// 400
 			case errs.ErrBadRequest:
 				logger.Error("Bad request error", zap.Error(err))
-				w.WriteHeader(http.StatusBadRequest)
-				json.NewEncoder(w).Encode(rw.Wrap(err))
+				writeError(w, r, http.StatusBadRequest, err)
 
 			// 401
 			case errs.ErrUnauthorized:
 				logger.Error("Unauthorized error", zap.Error(err))
-				w.WriteHeader(http.StatusUnauthorized)
-				json.NewEncoder(w).Encode(rw.Wrap(err))
+				writeError(w, r, http.StatusUnauthorized, err)
 
 			// 404
 			case errs.ErrNotFound:
 				logger.Error("Not found error", zap.Error(err))
-				w.WriteHeader(http.StatusNotFound)
-				json.NewEncoder(w).Encode(rw.Wrap(err))
+				writeError(w, r, http.StatusNotFound, err)
+
+			// 409
+			case errs.ErrConflict:
+				logger.Error("Conflict error", zap.Error(err))
+				writeError(w, r, http.StatusConflict, err)
 
 			// 500
 			case errs.ErrInternal:
 				logger.Error("Internal error", zap.Error(err))
-				w.WriteHeader(http.StatusInternalServerError)
-				json.NewEncoder(w).Encode(rw.Wrap(err))
+				writeError(w, r, http.StatusInternalServerError, err)
 
 			// ***
 			default:
 				logger.Error("Unexpected error", zap.Error(err))
-				w.WriteHeader(http.StatusInternalServerError)
-				json.NewEncoder(w).Encode(rw.Wrap(errs.ErrInternal))
+				writeError(w, r, http.StatusInternalServerError, errs.ErrInternal)
 			}
 		}
 	}
 }
+
+// writeError отвечает ошибкой; в тело добавляется request_id, чтобы клиент мог сослаться на запрос.
+func writeError(w http.ResponseWriter, r *http.Request, status int, err *errs.AppError) {
+	resp := rw.Wrap(err).(rw.Response)
+	resp.RequestID = requestid.FromContext(r.Context())
+
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,141 @@
+package middleware
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"math"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-rest-api/internal/errs"
+	"go-rest-api/internal/ratelimit"
+	"go-rest-api/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// ClientFunc возвращает ключ клиента, по которому считается лимит.
+type ClientFunc func(r *http.Request) string
+
+// ParseProxies разбирает список доверенных прокси: CIDR или отдельные адреса.
+func ParseProxies(proxies []string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(proxies))
+	for _, proxy := range proxies {
+		proxy = strings.TrimSpace(proxy)
+		if proxy == "" {
+			continue
+		}
+		if strings.Contains(proxy, "/") {
+			prefix, err := netip.ParsePrefix(proxy)
+			if err != nil {
+				return nil, err
+			}
+			prefixes = append(prefixes, prefix.Masked())
+			continue
+		}
+		addr, err := netip.ParseAddr(proxy)
+		if err != nil {
+			return nil, err
+		}
+		prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+	return prefixes, nil
+}
+
+// ClientIP -- клиент по IP. X-Forwarded-For учитывается, только если соединение пришло
+// от доверенного прокси: адреса перебираются справа налево, и клиентом считается первый
+// недоверенный. Левые записи заголовка клиент может подделать, поэтому они не берутся на веру.
+func ClientIP(trusted []netip.Prefix) ClientFunc {
+	isTrusted := func(addr netip.Addr) bool {
+		for _, prefix := range trusted {
+			if prefix.Contains(addr.Unmap()) {
+				return true
+			}
+		}
+		return false
+	}
+
+	return func(r *http.Request) string {
+		host, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			host = r.RemoteAddr
+		}
+
+		remote, err := netip.ParseAddr(host)
+		if err != nil || !isTrusted(remote) {
+			return "ip:" + host
+		}
+
+		hops := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			hop, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+			if err != nil {
+				break
+			}
+			if !isTrusted(hop) {
+				return "ip:" + hop.Unmap().String()
+			}
+		}
+		return "ip:" + host
+	}
+}
+
+// ClientAPIKey -- клиент по API-ключу, но только если ключ верный: иначе каждый новый
+// мусорный Authorization получал бы свою полную корзину. Без верного ключа -- byIP.
+func ClientAPIKey(apiKey string, byIP ClientFunc) ClientFunc {
+	sum := sha256.Sum256([]byte(apiKey))
+	bucket := "key:" + hex.EncodeToString(sum[:8])
+
+	return func(r *http.Request) string {
+		key := r.Header.Get("Authorization")
+		if apiKey == "" || subtle.ConstantTimeCompare([]byte(key), []byte(apiKey)) != 1 {
+			return byIP(r)
+		}
+		return bucket
+	}
+}
+
+// RateLimit ограничивает частоту запросов по маршрутам из policy. Каждый ответ получает заголовки
+// RateLimit-*, отклонённый -- 429 и Retry-After. Если хранилище недоступно, запрос пропускается.
+func RateLimit(ctx context.Context, store ratelimit.Store, policy *ratelimit.Policy, client ClientFunc, next http.Handler) http.HandlerFunc {
+	base := logger.FromContext(ctx)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		route, limit, ok := policy.Match(r.Method, r.URL.Path)
+		if !ok || limit.Unlimited() {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		res, err := store.Take(r.Context(), route+"|"+client(r), limit)
+		if err != nil {
+			logger.Request(r.Context(), base).Warn("Rate limit store failed, request allowed", zap.Error(err))
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Set("RateLimit-Limit", strconv.Itoa(res.Limit))
+		w.Header().Set("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+		w.Header().Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(res.Reset)))
+
+		if !res.Allowed {
+			logger.Request(r.Context(), base).Warn("Rate limit exceeded", zap.String("route", route))
+			w.Header().Set("Retry-After", strconv.Itoa(max(1, ceilSeconds(res.RetryAfter))))
+			w.Header().Set("Content-Type", "application/json")
+			writeError(w, r, http.StatusTooManyRequests, errs.ErrTooManyRequests)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	}
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,98 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestClientIP(t *testing.T) {
+	trusted, err := ParseProxies([]string{"10.0.0.0/8", " 192.168.1.1 ", ""})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		remote string
+		xff    []string
+		want   string
+	}{
+		{"direct", "203.0.113.7:5000", nil, "ip:203.0.113.7"},
+		{"spoofed header from untrusted peer", "203.0.113.7:5000", []string{"198.51.100.1"}, "ip:203.0.113.7"},
+		{"trusted proxy", "10.0.0.1:5000", []string{"198.51.100.1"}, "ip:198.51.100.1"},
+		{"proxy chain", "10.0.0.1:5000", []string{"198.51.100.1, 192.168.1.1, 10.1.2.3"}, "ip:198.51.100.1"},
+		{"spoofed left entries", "10.0.0.1:5000", []string{"1.1.1.1, 2.2.2.2, 198.51.100.1, 10.1.2.3"}, "ip:198.51.100.1"},
+		{"several headers", "10.0.0.1:5000", []string{"1.1.1.1", "198.51.100.1, 10.1.2.3"}, "ip:198.51.100.1"},
+		{"spoofed trusted address", "10.0.0.1:5000", []string{"10.9.9.9, 198.51.100.1"}, "ip:198.51.100.1"},
+		{"only trusted hops", "10.0.0.1:5000", []string{"10.1.2.3"}, "ip:10.0.0.1"},
+		{"garbage hop", "10.0.0.1:5000", []string{"198.51.100.1, not-an-ip"}, "ip:10.0.0.1"},
+		{"no header", "10.0.0.1:5000", nil, "ip:10.0.0.1"},
+		{"ipv4-mapped trusted peer", "[::ffff:10.0.0.1]:5000", []string{"::ffff:198.51.100.1"}, "ip:198.51.100.1"},
+		{"ipv6 client", "10.0.0.1:5000", []string{"2001:db8::1"}, "ip:2001:db8::1"},
+		{"remote without port", "203.0.113.7", nil, "ip:203.0.113.7"},
+	}
+
+	client := ClientIP(trusted)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/api/v1/songs", nil)
+			r.RemoteAddr = tt.remote
+			for _, v := range tt.xff {
+				r.Header.Add("X-Forwarded-For", v)
+			}
+
+			if got := client(r); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseProxiesRejectsGarbage(t *testing.T) {
+	for _, proxy := range []string{"10.0.0.0/33", "proxy.local"} {
+		if _, err := ParseProxies([]string{proxy}); err == nil {
+			t.Errorf("%q accepted", proxy)
+		}
+	}
+}
+
+func TestClientAPIKey(t *testing.T) {
+	const apiKey = "s3cret-key"
+	sum := sha256.Sum256([]byte(apiKey))
+	keyBucket := "key:" + hex.EncodeToString(sum[:8])
+
+	byIP := ClientIP(nil)
+
+	tests := []struct {
+		name   string
+		apiKey string
+		header string
+		want   string
+	}{
+		{"valid key", apiKey, apiKey, keyBucket},
+		{"wrong key", apiKey, "guess", "ip:203.0.113.7"},
+		{"missing key", apiKey, "", "ip:203.0.113.7"},
+		{"auth disabled", "", "", "ip:203.0.113.7"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/api/v1/songs", nil)
+			r.RemoteAddr = "203.0.113.7:5000"
+			if tt.header != "" {
+				r.Header.Set("Authorization", tt.header)
+			}
+
+			got := ClientAPIKey(tt.apiKey, byIP)(r)
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+			if tt.apiKey != "" && strings.Contains(got, tt.apiKey) {
+				t.Error("bucket key contains the API key")
+			}
+		})
+	}
+}
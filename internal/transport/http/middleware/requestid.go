@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"go-rest-api/pkg/logger"
+	"go-rest-api/pkg/requestid"
+
+	"go.uber.org/zap"
+)
+
+// RequestID принимает X-Request-ID клиента или генерирует новый, возвращает его в ответе
+// и кладёт в контекст запроса вместе с логгером, к которому он привязан.
+func RequestID(ctx context.Context, next http.Handler) http.HandlerFunc {
+	base := logger.FromContext(ctx)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(requestid.Header)
+		if !requestid.Valid(id) {
+			id = requestid.New()
+		}
+		w.Header().Set(requestid.Header, id)
+
+		reqCtx := requestid.ToContext(r.Context(), id)
+		reqCtx = logger.ToContext(reqCtx, base.With(zap.String("request_id", id)))
+
+		next.ServeHTTP(w, r.WithContext(reqCtx))
+	}
+}
@@ -0,0 +1,155 @@
+package http_v1_handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"go-rest-api/internal/entity"
+	"go-rest-api/internal/errs"
+
+	"go.uber.org/zap"
+)
+
+type AdminUsecase interface {
+	GetDuplicates(context.Context, string, float64, int) (entity.Content, error)
+	MergeSongs(context.Context, entity.Merge) error
+	MergeGroups(context.Context, entity.Merge) error
+}
+
+// GetDuplicates godoc
+//
+//	@Summary		Likely duplicate songs or groups.
+//	@Description	Names are compared normalized (trimmed, single-spaced, case-insensitive) and by trigram similarity.
+//	@Description	Songs are compared within the same group only.
+//	@Tags			Admin
+//	@Accept			json
+//	@Produce		json
+//	@Param			kind		query		string														false	"records to compare"		Enums(songs, groups)	default(songs)
+//	@Param			threshold	query		number														false	"minimal name similarity"	minimum(0)				maximum(1)	default(0.6)
+//	@Param			page		query		int															false	"page"						minimum(1)
+//	@Success		200			{object}	Response{content=entity.Content{items=[]entity.Duplicate}}	"Success"
+//	@Failure		400			{object}	Response													"Bad Request"
+//	@Failure		401			{object}	Response													"Unauthorized"
+//	@Failure		404			{object}	Response													"Not Found"
+//	@Failure		500			{object}	Response													"Internal Server Error"
+//	@Router			/admin/duplicates [get]
+func (h *Handler) GetDuplicates(w http.ResponseWriter, r *http.Request) *errs.AppError {
+	pageID, err := validatePage(r.URL.Query().Get("page"))
+	if err != nil {
+		h.log(r).Error("Invalid page id", zap.Error(err))
+		return errs.ErrBadRequest
+	}
+
+	var threshold float64
+	if t := r.URL.Query().Get("threshold"); t != "" {
+		threshold, err = strconv.ParseFloat(t, 64)
+		if err != nil || threshold <= 0 {
+			h.log(r).Error("Invalid threshold", zap.String("threshold", t), zap.Error(err))
+			return errs.ErrBadRequest
+		}
+	}
+
+	kind := r.URL.Query().Get("kind")
+
+	content, err := h.usecase.GetDuplicates(r.Context(), kind, threshold, pageID)
+	if err != nil {
+		h.log(r).Error("Failed get duplicates", zap.String("kind", kind), zap.Error(err))
+		if errors.Is(err, errs.ErrNotFound) {
+			return errs.ErrNotFound
+		}
+		if errors.Is(err, errs.ErrBadRequest) {
+			return errs.ErrBadRequest
+		}
+		return errs.ErrInternal
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(Wrap(content))
+	h.log(r).Info("Duplicates find successfully", zap.String("kind", kind))
+	return nil
+}
+
+// MergeSongs godoc
+//
+//	@Summary		Merge duplicate songs.
+//	@Description	Playlist entries and plays move to the kept song; it also gains the other songs' artists, genres,
+//	@Description	tags and missing translations. The merged songs are deleted. Everything happens atomically.
+//	@Tags			Admin
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		entity.Merge	true	"kept song id and ids of songs to merge into it"
+//	@Success		200		{object}	Response		"Success"
+//	@Failure		400		{object}	Response		"Bad Request"
+//	@Failure		401		{object}	Response		"Unauthorized"
+//	@Failure		404		{object}	Response		"Not Found"
+//	@Failure		500		{object}	Response		"Internal Server Error"
+//	@Router			/admin/songs/merge [post]
+func (h *Handler) MergeSongs(w http.ResponseWriter, r *http.Request) *errs.AppError {
+	var merge entity.Merge
+	if err := json.NewDecoder(r.Body).Decode(&merge); err != nil {
+		h.log(r).Error("Invalid request payload", zap.Error(err))
+		return errs.ErrBadRequest
+	}
+	defer r.Body.Close()
+
+	if err := h.usecase.MergeSongs(r.Context(), merge); err != nil {
+		h.log(r).Error("Failed merge songs", zap.Int("keep", merge.Keep), zap.Error(err))
+		return mergeError(err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(Wrap(nil))
+	h.log(r).Info("Songs merged successfully", zap.Int("keep", merge.Keep), zap.Ints("merge", merge.Merge))
+	return nil
+}
+
+// MergeGroups godoc
+//
+//	@Summary		Merge duplicate groups.
+//	@Description	Songs, albums and artist credits move to the kept group; the merged groups are deleted.
+//	@Description	Everything happens atomically.
+//	@Tags			Admin
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		entity.Merge	true	"kept group id and ids of groups to merge into it"
+//	@Success		200		{object}	Response		"Success"
+//	@Failure		400		{object}	Response		"Bad Request"
+//	@Failure		401		{object}	Response		"Unauthorized"
+//	@Failure		404		{object}	Response		"Not Found"
+//	@Failure		500		{object}	Response		"Internal Server Error"
+//	@Router			/admin/groups/merge [post]
+func (h *Handler) MergeGroups(w http.ResponseWriter, r *http.Request) *errs.AppError {
+	var merge entity.Merge
+	if err := json.NewDecoder(r.Body).Decode(&merge); err != nil {
+		h.log(r).Error("Invalid request payload", zap.Error(err))
+		return errs.ErrBadRequest
+	}
+	defer r.Body.Close()
+
+	if err := h.usecase.MergeGroups(r.Context(), merge); err != nil {
+		h.log(r).Error("Failed merge groups", zap.Int("keep", merge.Keep), zap.Error(err))
+		return mergeError(err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(Wrap(nil))
+	h.log(r).Info("Groups merged successfully", zap.Int("keep", merge.Keep), zap.Ints("merge", merge.Merge))
+	return nil
+}
+
+func mergeError(err error) *errs.AppError {
+	switch {
+	case errors.Is(err, errs.ErrNotFound):
+		return errs.ErrNotFound
+	case errors.Is(err, errs.ErrBadRequest):
+		return errs.ErrBadRequest
+	default:
+		return errs.ErrInternal
+	}
+}
@@ -0,0 +1,316 @@
+package http_v1_handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"go-rest-api/internal/entity"
+	"go-rest-api/internal/errs"
+
+	"github.com/julienschmidt/httprouter"
+	"go.uber.org/zap"
+)
+
+type AlbumUsecase interface {
+	AddAlbum(context.Context, entity.Album) (int, error)
+	GetAlbum(context.Context, int) (entity.Album, error)
+	GetAlbums(context.Context, string, int) (entity.Content, error)
+	UpdateAlbum(context.Context, int, entity.Album) (bool, error)
+	DeleteAlbum(context.Context, int) (bool, error)
+	GetAlbumTracks(context.Context, int) ([]entity.Track, error)
+	SetAlbumTracks(context.Context, int, []entity.Track) error
+}
+
+// GetAlbums godoc
+//
+//	@Summary	Get albums.
+//	@Tags		Albums
+//	@Accept		json
+//	@Produce	json
+//	@Param		group	query		string													false	"album group"
+//	@Param		page	query		int														false	"page"	minimum(1)
+//	@Success	200		{object}	Response{content=entity.Content{items=[]entity.Album}}	"Success"
+//	@Failure	400		{object}	Response												"Bad Request"
+//	@Failure	401		{object}	Response												"Unauthorized"
+//	@Failure	404		{object}	Response												"Not Found"
+//	@Failure	500		{object}	Response												"Internal Server Error"
+//	@Router		/albums [get]
+func (h *Handler) GetAlbums(w http.ResponseWriter, r *http.Request) *errs.AppError {
+	pageID, err := validatePage(r.URL.Query().Get("page"))
+	if err != nil {
+		h.log(r).Error("Invalid page id", zap.Error(err))
+		return errs.ErrBadRequest
+	}
+
+	content, err := h.usecase.GetAlbums(r.Context(), r.URL.Query().Get("group"), pageID)
+	if err != nil {
+		h.log(r).Error("Failed get albums", zap.Error(err))
+		if errors.Is(err, errs.ErrNotFound) {
+			return errs.ErrNotFound
+		}
+		return errs.ErrInternal
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(Wrap(content))
+	h.log(r).Info("Albums find successfully")
+	return nil
+}
+
+// GetAlbum godoc
+//
+//	@Summary	Get album.
+//	@Tags		Albums
+//	@Accept		json
+//	@Produce	json
+//	@Param		id	path		int									true	"album id"
+//	@Success	200	{object}	ResponseAlbum{album=entity.Album}	"Success"
+//	@Failure	400	{object}	Response							"Bad Request"
+//	@Failure	401	{object}	Response							"Unauthorized"
+//	@Failure	404	{object}	Response							"Not Found"
+//	@Failure	500	{object}	Response							"Internal Server Error"
+//	@Router		/albums/{id} [get]
+func (h *Handler) GetAlbum(w http.ResponseWriter, r *http.Request) *errs.AppError {
+	id, err := albumIDFromPath(r)
+	if err != nil {
+		h.log(r).Error("Validation failed", zap.Error(err))
+		return errs.ErrBadRequest
+	}
+
+	album, err := h.usecase.GetAlbum(r.Context(), id)
+	if err != nil {
+		h.log(r).Error("Failed get album", zap.Int("album_id", id), zap.Error(err))
+		if errors.Is(err, errs.ErrNotFound) {
+			return errs.ErrNotFound
+		}
+		return errs.ErrInternal
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(Wrap(album))
+	h.log(r).Info("Album find successfully", zap.Int("album_id", id))
+	return nil
+}
+
+// AddAlbum godoc
+//
+//	@Summary	Adding a new album.
+//	@Tags		Albums
+//	@Accept		json
+//	@Produce	json
+//	@Param		request	body		entity.Album						true	"json"
+//	@Success	201		{object}	ResponseAlbum{album=entity.Album}	"Success"
+//	@Failure	400		{object}	Response							"Bad Request"
+//	@Failure	401		{object}	Response							"Unauthorized"
+//	@Failure	500		{object}	Response							"Internal Server Error"
+//	@Router		/albums [post]
+func (h *Handler) AddAlbum(w http.ResponseWriter, r *http.Request) *errs.AppError {
+	var album entity.Album
+	if err := json.NewDecoder(r.Body).Decode(&album); err != nil {
+		h.log(r).Error("Invalid request payload", zap.Error(err))
+		return errs.ErrBadRequest
+	}
+	defer r.Body.Close()
+
+	id, err := h.usecase.AddAlbum(r.Context(), album)
+	if err != nil {
+		h.log(r).Error("Failed to add new album", zap.Error(err))
+		if errors.Is(err, errs.ErrBadRequest) {
+			return errs.ErrBadRequest
+		}
+		return errs.ErrInternal
+	}
+	album.ID = &id
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(Wrap(album))
+	h.log(r).Info("Album added successfully", zap.Int("album_id", id))
+	return nil
+}
+
+// UpdateAlbum godoc
+//
+//	@Summary	Update album.
+//	@Tags		Albums
+//	@Accept		json
+//	@Produce	json
+//	@Param		id		path		int				true	"album id"
+//	@Param		request	body		entity.Album	true	"json"
+//	@Success	200		{object}	Response		"Success"
+//	@Failure	400		{object}	Response		"Bad Request"
+//	@Failure	401		{object}	Response		"Unauthorized"
+//	@Failure	404		{object}	Response		"Not Found"
+//	@Failure	500		{object}	Response		"Internal Server Error"
+//	@Router		/albums/{id} [put]
+func (h *Handler) UpdateAlbum(w http.ResponseWriter, r *http.Request) *errs.AppError {
+	id, err := albumIDFromPath(r)
+	if err != nil {
+		h.log(r).Error("Validation failed", zap.Error(err))
+		return errs.ErrBadRequest
+	}
+
+	var album entity.Album
+	if err := json.NewDecoder(r.Body).Decode(&album); err != nil {
+		h.log(r).Error("Invalid request payload", zap.Error(err))
+		return errs.ErrBadRequest
+	}
+	defer r.Body.Close()
+
+	isUpdated, err := h.usecase.UpdateAlbum(r.Context(), id, album)
+	if err != nil {
+		h.log(r).Error("Failed update album", zap.Int("album_id", id), zap.Error(err))
+		if errors.Is(err, errs.ErrBadRequest) {
+			return errs.ErrBadRequest
+		}
+		return errs.ErrInternal
+	}
+
+	if !isUpdated {
+		h.log(r).Error("Album not found", zap.Int("album_id", id))
+		return errs.ErrNotFound
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(Wrap(nil))
+	h.log(r).Info("Album updated successfully", zap.Int("album_id", id))
+	return nil
+}
+
+// DeleteAlbum godoc
+//
+//	@Summary	Delete album.
+//	@Tags		Albums
+//	@Accept		json
+//	@Produce	json
+//	@Param		id	path		int			true	"album id"
+//	@Success	200	{object}	Response	"Success"
+//	@Failure	400	{object}	Response	"Bad Request"
+//	@Failure	401	{object}	Response	"Unauthorized"
+//	@Failure	404	{object}	Response	"Not Found"
+//	@Failure	500	{object}	Response	"Internal Server Error"
+//	@Router		/albums/{id} [delete]
+func (h *Handler) DeleteAlbum(w http.ResponseWriter, r *http.Request) *errs.AppError {
+	id, err := albumIDFromPath(r)
+	if err != nil {
+		h.log(r).Error("Validation failed", zap.Error(err))
+		return errs.ErrBadRequest
+	}
+
+	isDeleted, err := h.usecase.DeleteAlbum(r.Context(), id)
+	if err != nil {
+		h.log(r).Error("Failed delete album", zap.Int("album_id", id), zap.Error(err))
+		return errs.ErrInternal
+	}
+
+	if !isDeleted {
+		h.log(r).Error("Album not found", zap.Int("album_id", id))
+		return errs.ErrNotFound
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(Wrap(nil))
+	h.log(r).Info("Album deleted successfully", zap.Int("album_id", id))
+	return nil
+}
+
+// GetAlbumTracks godoc
+//
+//	@Summary	Get album tracks ordered by disc and track number.
+//	@Tags		Albums
+//	@Accept		json
+//	@Produce	json
+//	@Param		id	path		int														true	"album id"
+//	@Success	200	{object}	Response{content=entity.Content{items=[]entity.Track}}	"Success"
+//	@Failure	400	{object}	Response												"Bad Request"
+//	@Failure	401	{object}	Response												"Unauthorized"
+//	@Failure	404	{object}	Response												"Not Found"
+//	@Failure	500	{object}	Response												"Internal Server Error"
+//	@Router		/albums/{id}/tracks [get]
+func (h *Handler) GetAlbumTracks(w http.ResponseWriter, r *http.Request) *errs.AppError {
+	id, err := albumIDFromPath(r)
+	if err != nil {
+		h.log(r).Error("Validation failed", zap.Error(err))
+		return errs.ErrBadRequest
+	}
+
+	tracks, err := h.usecase.GetAlbumTracks(r.Context(), id)
+	if err != nil {
+		h.log(r).Error("Failed get album tracks", zap.Int("album_id", id), zap.Error(err))
+		if errors.Is(err, errs.ErrNotFound) {
+			return errs.ErrNotFound
+		}
+		return errs.ErrInternal
+	}
+
+	content := entity.Content{
+		CurrentPage: 1,
+		TotalPage:   1,
+		TotalItems:  len(tracks),
+		Items:       tracks,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(Wrap(content))
+	h.log(r).Info("Album tracks find successfully", zap.Int("album_id", id))
+	return nil
+}
+
+// SetAlbumTracks godoc
+//
+//	@Summary		Set album tracks and their order.
+//	@Description	Replaces the album track list: songs missing from the request leave the album. disc_number defaults to 1.
+//	@Tags			Albums
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		int				true	"album id"
+//	@Param			request	body		[]entity.Track	true	"ordered tracks"
+//	@Success		200		{object}	Response		"Success"
+//	@Failure		400		{object}	Response		"Bad Request"
+//	@Failure		401		{object}	Response		"Unauthorized"
+//	@Failure		404		{object}	Response		"Not Found"
+//	@Failure		500		{object}	Response		"Internal Server Error"
+//	@Router			/albums/{id}/tracks [put]
+func (h *Handler) SetAlbumTracks(w http.ResponseWriter, r *http.Request) *errs.AppError {
+	id, err := albumIDFromPath(r)
+	if err != nil {
+		h.log(r).Error("Validation failed", zap.Error(err))
+		return errs.ErrBadRequest
+	}
+
+	var tracks []entity.Track
+	if err := json.NewDecoder(r.Body).Decode(&tracks); err != nil {
+		h.log(r).Error("Invalid request payload", zap.Error(err))
+		return errs.ErrBadRequest
+	}
+	defer r.Body.Close()
+
+	if err := h.usecase.SetAlbumTracks(r.Context(), id, tracks); err != nil {
+		h.log(r).Error("Failed set album tracks", zap.Int("album_id", id), zap.Error(err))
+		if errors.Is(err, errs.ErrNotFound) {
+			return errs.ErrNotFound
+		}
+		if errors.Is(err, errs.ErrBadRequest) {
+			return errs.ErrBadRequest
+		}
+		return errs.ErrInternal
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(Wrap(nil))
+	h.log(r).Info("Album tracks updated successfully", zap.Int("album_id", id))
+	return nil
+}
+
+func albumIDFromPath(r *http.Request) (int, error) {
+	params := httprouter.ParamsFromContext(r.Context())
+	return validateID(params.ByName("id"))
+}
@@ -1,19 +1,75 @@
 package http_v1_handler
 
 import (
+	"net/http"
+	"strings"
+	"time"
+
 	"go-rest-api/internal/entity"
 	"go-rest-api/internal/errs"
 )
 
+// Импорт, выгрузка и NDJSON-листинг не укладываются в серверные read/write_timeout
+// (они рассчитаны на обычные запросы), поэтому продлевают дедлайны соединения сами.
+const (
+	// _streamWriteTimeout -- сколько может идти одна запись потока; дедлайн сдвигается при каждой записи.
+	_streamWriteTimeout = 30 * time.Second
+	// _importTimeout -- на чтение пакета импорта и его обработку вместе с обогащением.
+	_importTimeout = 10 * time.Minute
+)
+
 type (
 	Response struct {
 		Description string `json:"description"`
+		RequestID   string `json:"request_id,omitempty"`
 	}
 
 	ResponseContent struct {
 		Description string         `json:"description"`
 		Content     entity.Content `json:"content"`
 	}
+
+	ResponseAlbum struct {
+		Description string       `json:"description"`
+		Album       entity.Album `json:"album"`
+	}
+
+	ResponsePlaylist struct {
+		Description string          `json:"description"`
+		Playlist    entity.Playlist `json:"playlist"`
+	}
+
+	ResponsePlaylistEntry struct {
+		Description string                  `json:"description"`
+		Entry       entity.NewPlaylistEntry `json:"entry"`
+	}
+
+	ResponseFacets struct {
+		Description string            `json:"description"`
+		Facets      entity.SongFacets `json:"facets"`
+	}
+
+	ResponseStats struct {
+		Description string           `json:"description"`
+		Stats       entity.SongStats `json:"stats"`
+	}
+
+	ResponseReport struct {
+		Description string              `json:"description"`
+		Report      entity.ImportReport `json:"report"`
+	}
+
+	// streamWriter откладывает отправку заголовков до первой записи,
+	// чтобы ошибку до начала выгрузки можно было вернуть обычным ответом.
+	// Каждая запись продлевает дедлайн записи соединения на _streamWriteTimeout:
+	// поток обрывается, только если клиент или база надолго замолчали.
+	streamWriter struct {
+		http.ResponseWriter
+		rc          *http.ResponseController
+		contentType string
+		filename    string
+		started     bool
+	}
 )
 
 func Wrap(i interface{}) interface{} {
@@ -21,6 +77,42 @@ func Wrap(i interface{}) interface{} {
 	case nil:
 		return Response{Description: "ok"}
 
+	case entity.Album:
+		return ResponseAlbum{
+			Description: "ok",
+			Album:       v,
+		}
+
+	case entity.Playlist:
+		return ResponsePlaylist{
+			Description: "ok",
+			Playlist:    v,
+		}
+
+	case entity.NewPlaylistEntry:
+		return ResponsePlaylistEntry{
+			Description: "ok",
+			Entry:       v,
+		}
+
+	case entity.SongFacets:
+		return ResponseFacets{
+			Description: "ok",
+			Facets:      v,
+		}
+
+	case entity.SongStats:
+		return ResponseStats{
+			Description: "ok",
+			Stats:       v,
+		}
+
+	case entity.ImportReport:
+		return ResponseReport{
+			Description: "ok",
+			Report:      v,
+		}
+
 	case entity.Content:
 		return ResponseContent{
 			Description: "ok",
@@ -31,3 +123,54 @@ func Wrap(i interface{}) interface{} {
 		return Response{Description: v.(*errs.AppError).Msg}
 	}
 }
+
+func newStreamWriter(w http.ResponseWriter, contentType, filename string) *streamWriter {
+	return &streamWriter{
+		ResponseWriter: w,
+		rc:             http.NewResponseController(w),
+		contentType:    contentType,
+		filename:       filename,
+	}
+}
+
+func (sw *streamWriter) Write(p []byte) (int, error) {
+	sw.extend()
+	sw.begin()
+	return sw.ResponseWriter.Write(p)
+}
+
+// extend сдвигает дедлайн записи; если соединение этого не умеет, действует серверный.
+func (sw *streamWriter) extend() {
+	if sw.rc != nil {
+		sw.rc.SetWriteDeadline(time.Now().Add(_streamWriteTimeout))
+	}
+}
+
+// begin отправляет заголовки ответа, если они ещё не отправлены.
+func (sw *streamWriter) begin() {
+	if sw.started {
+		return
+	}
+	sw.started = true
+
+	sw.Header().Set("Content-Type", sw.contentType)
+	if sw.filename != "" {
+		sw.Header().Set("Content-Disposition", "attachment; filename=\""+sw.filename+"\"")
+	}
+	sw.WriteHeader(http.StatusOK)
+}
+
+// etagMatch проверяет, есть ли etag в заголовке If-None-Match (список через запятую или *).
+// Сравнение слабое: префикс W/ не учитывается.
+func etagMatch(header, etag string) bool {
+	if header == "" {
+		return false
+	}
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if tag == "*" || tag == etag {
+			return true
+		}
+	}
+	return false
+}
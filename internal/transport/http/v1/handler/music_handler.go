@@ -1,28 +1,71 @@
 package http_v1_handler
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"iter"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"go-rest-api/internal/entity"
 	"go-rest-api/internal/errs"
+	"go-rest-api/internal/transport/catalog"
+	"go-rest-api/internal/transport/lrc"
 	"go-rest-api/pkg/logger"
 
 	"github.com/julienschmidt/httprouter"
 	"go.uber.org/zap"
+	"golang.org/x/text/language"
 )
 
+const (
+	_importBatchLimit = 1000
+	_importBodyLimit  = 64 << 20
+)
+
+// reservedSongNames -- сегменты /songs/{name}, занятые статическими путями (см. songSubroutes в
+// http_v1_route): песню с таким названием нельзя было бы получить, поэтому такие названия не принимаются.
+var reservedSongNames = map[string]bool{
+	"facets":  true,
+	"popular": true,
+}
+
+// _statsMaxAge -- сколько секунд клиент может не перезапрашивать статистику.
+// Ответ private: запрос авторизован ключом API, общие кэши его хранить не должны.
+const _statsMaxAge = 60
+
 type (
 	Usecase interface {
-		AddSong(entity.NewSong) error
-		DeleteSong(string) (bool, error)
-		UpdateSong(string, entity.Song) (bool, error)
-		GetSongText(string, int) (entity.Content, error)
-		GetFilteredSongs(entity.FilterSong, int) (entity.Content, error)
+		AlbumUsecase
+		PlaylistUsecase
+		TranslationUsecase
+		PlayUsecase
+		AdminUsecase
+
+		AddSong(context.Context, entity.NewSong) error
+		DeleteSong(context.Context, string) (bool, error)
+		UpdateSong(context.Context, string, entity.Song) (bool, error)
+		GetSongText(context.Context, string, []string, int) (entity.Content, error)
+		GetSongTextAligned(context.Context, string, string, string, int) (entity.Content, error)
+		GetSongLyrics(context.Context, string, string, int, int) (entity.Content, error)
+		SetSongTimedLines(context.Context, string, []entity.TimedLine) (bool, error)
+		GetSongTimedLines(context.Context, string) ([]entity.TimedLine, error)
+		GetSongTimedLyrics(context.Context, string, int, int) (entity.Content, error)
+		GetFilteredSongs(context.Context, entity.FilterSong, int) (entity.Content, error)
+		ImportSong(context.Context, entity.ImportSong, bool) (bool, error)
+		StreamSongs(context.Context, entity.FilterSong) (iter.Seq2[entity.Song, error], error)
+		SearchSongs(context.Context, string, string, int) (entity.Content, error)
+		GetSongFacets(context.Context, entity.FilterSong) (entity.SongFacets, error)
+		GetSongStats(context.Context, entity.FilterSong) (entity.SongStats, error)
+		GetSimilarSongs(context.Context, string, int) (entity.Content, error)
+		GetGenres(context.Context) ([]string, error)
 	}
 
 	Handler struct {
@@ -40,106 +83,127 @@ func New(ctx context.Context, usecase Usecase) *Handler {
 	}
 }
 
+// log -- логгер запроса (request_id, trace_id).
+func (h *Handler) log(r *http.Request) *logger.Logger {
+	return logger.Request(r.Context(), h.logger)
+}
+
 // GetFilteredSongs godoc
 //
-//	@Summary	Get filtered songs.
-//	@Tags		Songs
-//	@Accept		json
-//	@Produce	json
-//	@Param		name			query		string												false	"song name"
-//	@Param		group			query		string												false	"song group"
-//	@Param		release_date	query		string												false	"song release date"
-//	@Param		page			query		int													false	"page"	minimum(1)
-//	@Success	200				{object}	Response{content=entity.Content{items=entity.Song}}	"Success"
-//	@Failure	400				{object}	Response											"Bad Request"
-//	@Failure	401				{object}	Response											"Unauthorized"
-//	@Failure	404				{object}	Response											"Not Found"
-//	@Failure	500				{object}	Response											"Internal Server Error"
-//	@Router		/songs [get]
+//	@Summary		Get filtered songs.
+//	@Description	With "Accept: application/x-ndjson" all matching songs are streamed one per line, without paging.
+//	@Tags			Songs
+//	@Accept			json
+//	@Produce		json,application/x-ndjson
+//	@Param			name			query		string												false	"song name"
+//	@Param			group			query		string												false	"song group"
+//	@Param			artist			query		string												false	"any song artist (primary, featured, composer, lyricist)"
+//	@Param			release_date	query		string												false	"song release date"
+//	@Param			album			query		string												false	"album title"
+//	@Param			album_id		query		int													false	"album id"
+//	@Param			genre			query		string												false	"genre from the vocabulary"
+//	@Param			tag				query		string												false	"free-form tag"
+//	@Param			link_status		query		string												false	"link check status"										Enums(unchecked, ok, broken)
+//	@Param			sort			query		string												false	"order: id (default) or popularity within the window"	Enums(id, popularity)
+//	@Param			window			query		string												false	"popularity window for sort=popularity: 7d (default), 30d, 12h"
+//	@Param			page			query		int													false	"page"	minimum(1)
+//	@Success		200				{object}	Response{content=entity.Content{items=entity.Song}}	"Success"
+//	@Failure		400				{object}	Response											"Bad Request"
+//	@Failure		401				{object}	Response											"Unauthorized"
+//	@Failure		404				{object}	Response											"Not Found"
+//	@Failure		500				{object}	Response											"Internal Server Error"
+//	@Router			/songs [get]
 func (h *Handler) GetFilteredSongs(w http.ResponseWriter, r *http.Request) *errs.AppError {
 	page := r.URL.Query().Get("page")
 	pageID, err := validatePage(page)
 	if err != nil {
-		h.logger.Error("Invalid page id", zap.Error(err))
+		h.log(r).Error("Invalid page id", zap.Error(err))
 		return errs.ErrBadRequest
 	}
 
-	var namePtr, groupPtr, releaseDatePTR *string
-	name := r.URL.Query().Get("name")
-	group := r.URL.Query().Get("group")
-	releaseDate := r.URL.Query().Get("release_date")
-
-	if name != "" {
-		namePtr = &name
-	}
-	if group != "" {
-		groupPtr = &group
-	}
-	if releaseDate != "" {
-		releaseDatePTR = &releaseDate
+	filter, err := filterFromQuery(r)
+	if err != nil {
+		h.log(r).Error("Invalid filter", zap.Error(err))
+		return errs.ErrBadRequest
 	}
 
-	filter := entity.FilterSong{
-		Name:        namePtr,
-		Group:       groupPtr,
-		ReleaseDate: releaseDatePTR,
+	if acceptsNDJSON(r) {
+		return h.streamFilteredSongs(w, r, filter)
 	}
 
-	content, err := h.usecase.GetFilteredSongs(filter, pageID)
+	content, err := h.usecase.GetFilteredSongs(r.Context(), filter, pageID)
 	if err != nil {
-		h.logger.Error("Failed get filtered songs", zap.Error(err))
+		h.log(r).Error("Failed get filtered songs", zap.Error(err))
 		if errors.Is(err, errs.ErrNotFound) {
 			return errs.ErrNotFound
 		}
+		if errors.Is(err, errs.ErrBadRequest) {
+			return errs.ErrBadRequest
+		}
 		return errs.ErrInternal
 	}
 
 	c := entity.Content{}
 	if content == c {
-		h.logger.Error("Songs not found", zap.Error(err))
+		h.log(r).Error("Songs not found", zap.Error(err))
 		return errs.ErrNotFound
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(Wrap(content))
-	h.logger.Info("Songs find successfully")
+	h.log(r).Info("Songs find successfully")
 	return nil
 }
 
 // GetSongText godoc
 //
-//	@Summary	Get song text with couplet pagination.
-//	@Tags		Songs
-//	@Accept		json
-//	@Produce	json
-//	@Param		name	path		string													true	"song name"
-//	@Param		page	query		int														false	"page"	minimum(1)
-//	@Success	200		{object}	Response{content=entity.Content{items=entity.Couplet}}	"Success"
-//	@Failure	400		{object}	Response												"Bad Request"
-//	@Failure	401		{object}	Response												"Unauthorized"
-//	@Failure	404		{object}	Response												"Not Found"
-//	@Failure	500		{object}	Response												"Internal Server Error"
-//	@Router		/songs/{name} [get]
+//	@Summary		Get song text with couplet pagination.
+//	@Description	The language is taken from lang or Accept-Language; without a matching translation the original is returned.
+//	@Description	With two languages (lang=ru,en) each page holds the couplet in both languages side by side.
+//	@Tags			Songs
+//	@Accept			json
+//	@Produce		json
+//	@Param			name			path		string													true	"song name"
+//	@Param			lang			query		string													false	"BCP-47 language tag, or two comma-separated tags for side-by-side text"
+//	@Param			Accept-Language	header		string													false	"preferred languages, used when lang is not set"
+//	@Param			page			query		int														false	"page"	minimum(1)
+//	@Success		200				{object}	Response{content=entity.Content{items=entity.Couplet}}	"Success"
+//	@Failure		400				{object}	Response												"Bad Request"
+//	@Failure		401				{object}	Response												"Unauthorized"
+//	@Failure		404				{object}	Response												"Not Found"
+//	@Failure		500				{object}	Response												"Internal Server Error"
+//	@Router			/songs/{name} [get]
 func (h *Handler) GetSongText(w http.ResponseWriter, r *http.Request) *errs.AppError {
 	params := httprouter.ParamsFromContext(r.Context())
 	name := params.ByName("name")
 
 	if err := validateName(name); err != nil {
-		h.logger.Error("Validation failed", zap.Error(err))
+		h.log(r).Error("Validation failed", zap.Error(err))
 		return errs.ErrBadRequest
 	}
 
 	page := r.URL.Query().Get("page")
 	pageID, err := validatePage(page)
 	if err != nil {
-		h.logger.Error("Invalid page id", zap.String("song_name", name), zap.Error(err))
+		h.log(r).Error("Invalid page id", zap.String("song_name", name), zap.Error(err))
+		return errs.ErrBadRequest
+	}
+
+	langs, aligned, err := songLangs(r)
+	if err != nil {
+		h.log(r).Error("Invalid language", zap.String("song_name", name), zap.Error(err))
 		return errs.ErrBadRequest
 	}
 
-	content, err := h.usecase.GetSongText(name, pageID)
+	var content entity.Content
+	if aligned {
+		content, err = h.usecase.GetSongTextAligned(r.Context(), name, langs[0], langs[1], pageID)
+	} else {
+		content, err = h.usecase.GetSongText(r.Context(), name, langs, pageID)
+	}
 	if err != nil {
-		h.logger.Error("Failed get song", zap.String("song_name", name), zap.Error(err))
+		h.log(r).Error("Failed get song", zap.String("song_name", name), zap.Error(err))
 		if errors.Is(err, errs.ErrNotFound) {
 			return errs.ErrNotFound
 		}
@@ -148,14 +212,220 @@ func (h *Handler) GetSongText(w http.ResponseWriter, r *http.Request) *errs.AppE
 
 	c := entity.Content{}
 	if content == c {
-		h.logger.Error("Song not found", zap.String("song_name", name), zap.Error(err))
+		h.log(r).Error("Song not found", zap.String("song_name", name), zap.Error(err))
 		return errs.ErrNotFound
 	}
 
 	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Vary", "Accept-Language")
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(Wrap(content))
-	h.logger.Info("Song find successfully", zap.String("song_name", name))
+	h.log(r).Info("Song find successfully", zap.String("song_name", name))
+	return nil
+}
+
+// GetSongLyrics godoc
+//
+//	@Summary		Get song lyrics by sections.
+//	@Description	Without "lines" one section is returned per page; with "lines" pages hold N lines grouped by their sections.
+//	@Tags			Songs
+//	@Accept			json
+//	@Produce		json
+//	@Param			name	path		string													true	"song name"
+//	@Param			section	query		string													false	"section type"		Enums(verse, chorus, bridge, outro)
+//	@Param			lines	query		int														false	"lines per page"	minimum(1)
+//	@Param			page	query		int														false	"page"				minimum(1)
+//	@Success		200		{object}	Response{content=entity.Content{items=entity.Section}}	"Success"
+//	@Failure		400		{object}	Response												"Bad Request"
+//	@Failure		401		{object}	Response												"Unauthorized"
+//	@Failure		404		{object}	Response												"Not Found"
+//	@Failure		500		{object}	Response												"Internal Server Error"
+//	@Router			/songs/{name}/lyrics [get]
+func (h *Handler) GetSongLyrics(w http.ResponseWriter, r *http.Request) *errs.AppError {
+	params := httprouter.ParamsFromContext(r.Context())
+	name := params.ByName("name")
+
+	if err := validateName(name); err != nil {
+		h.log(r).Error("Validation failed", zap.Error(err))
+		return errs.ErrBadRequest
+	}
+
+	pageID, err := validatePage(r.URL.Query().Get("page"))
+	if err != nil {
+		h.log(r).Error("Invalid page id", zap.String("song_name", name), zap.Error(err))
+		return errs.ErrBadRequest
+	}
+
+	lines, err := validatePage(r.URL.Query().Get("lines"))
+	if err != nil || lines < 0 {
+		h.log(r).Error("Invalid lines per page", zap.String("song_name", name), zap.Error(err))
+		return errs.ErrBadRequest
+	}
+
+	section := r.URL.Query().Get("section")
+
+	content, err := h.usecase.GetSongLyrics(r.Context(), name, section, pageID, lines)
+	if err != nil {
+		h.log(r).Error("Failed get song lyrics", zap.String("song_name", name), zap.Error(err))
+		if errors.Is(err, errs.ErrNotFound) {
+			return errs.ErrNotFound
+		}
+		if errors.Is(err, errs.ErrBadRequest) {
+			return errs.ErrBadRequest
+		}
+		return errs.ErrInternal
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(Wrap(content))
+	h.log(r).Info("Song lyrics find successfully", zap.String("song_name", name))
+	return nil
+}
+
+// GetSongLRC godoc
+//
+//	@Summary	Get synced song lyrics in LRC format.
+//	@Tags		Lyrics
+//	@Produce	application/lrc
+//	@Param		name	path		string		true	"song name"
+//	@Success	200		{string}	string		"Success"
+//	@Failure	400		{object}	Response	"Bad Request"
+//	@Failure	401		{object}	Response	"Unauthorized"
+//	@Failure	404		{object}	Response	"Not Found"
+//	@Failure	500		{object}	Response	"Internal Server Error"
+//	@Router		/songs/{name}/lyrics.lrc [get]
+func (h *Handler) GetSongLRC(w http.ResponseWriter, r *http.Request) *errs.AppError {
+	params := httprouter.ParamsFromContext(r.Context())
+	name := params.ByName("name")
+
+	if err := validateName(name); err != nil {
+		h.log(r).Error("Validation failed", zap.Error(err))
+		return errs.ErrBadRequest
+	}
+
+	lines, err := h.usecase.GetSongTimedLines(r.Context(), name)
+	if err != nil {
+		h.log(r).Error("Failed get timed lyrics", zap.String("song_name", name), zap.Error(err))
+		if errors.Is(err, errs.ErrNotFound) {
+			return errs.ErrNotFound
+		}
+		return errs.ErrInternal
+	}
+
+	w.Header().Set("Content-Type", lrc.ContentType+"; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	if err := lrc.Write(w, name, "", lines); err != nil {
+		// Заголовки уже отправлены: ответ можно только оборвать.
+		h.log(r).Error("Song LRC write interrupted", zap.String("song_name", name), zap.Error(err))
+		return nil
+	}
+	h.log(r).Info("Song LRC find successfully", zap.String("song_name", name))
+	return nil
+}
+
+// PutSongLRC godoc
+//
+//	@Summary		Upload synced song lyrics in LRC format.
+//	@Description	Supports [offset:ms] and several timestamps per line. The song text and sections are rebuilt from the LRC lines (an empty line separates couplets).
+//	@Tags			Lyrics
+//	@Accept			application/lrc,plain
+//	@Produce		json
+//	@Param			name	path		string		true	"song name"
+//	@Param			request	body		string		true	"LRC text"
+//	@Success		200		{object}	Response	"Success"
+//	@Failure		400		{object}	Response	"Bad Request"
+//	@Failure		401		{object}	Response	"Unauthorized"
+//	@Failure		404		{object}	Response	"Not Found"
+//	@Failure		500		{object}	Response	"Internal Server Error"
+//	@Router			/songs/{name}/lyrics.lrc [put]
+func (h *Handler) PutSongLRC(w http.ResponseWriter, r *http.Request) *errs.AppError {
+	params := httprouter.ParamsFromContext(r.Context())
+	name := params.ByName("name")
+
+	if err := validateName(name); err != nil {
+		h.log(r).Error("Validation failed", zap.Error(err))
+		return errs.ErrBadRequest
+	}
+
+	lines, err := lrc.Parse(r.Body)
+	if err != nil {
+		h.log(r).Error("Invalid LRC payload", zap.String("song_name", name), zap.Error(err))
+		return errs.ErrBadRequest
+	}
+	defer r.Body.Close()
+
+	isUpdated, err := h.usecase.SetSongTimedLines(r.Context(), name, lines)
+	if err != nil {
+		h.log(r).Error("Failed update timed lyrics", zap.String("song_name", name), zap.Error(err))
+		if errors.Is(err, errs.ErrBadRequest) {
+			return errs.ErrBadRequest
+		}
+		return errs.ErrInternal
+	}
+
+	if !isUpdated {
+		h.log(r).Error("Song not found", zap.String("song_name", name))
+		return errs.ErrNotFound
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(Wrap(nil))
+	h.log(r).Info("Song LRC updated successfully", zap.String("song_name", name))
+	return nil
+}
+
+// GetSongTimedLyrics godoc
+//
+//	@Summary		Get synced song lyrics with millisecond offsets.
+//	@Description	Each page is a time window: [window*(page-1), window*page) ms.
+//	@Tags			Lyrics
+//	@Accept			json
+//	@Produce		json
+//	@Param			name	path		string														true	"song name"
+//	@Param			window	query		int															false	"window size in ms"	minimum(1)	default(30000)
+//	@Param			page	query		int															false	"page"				minimum(1)
+//	@Success		200		{object}	Response{content=entity.Content{items=[]entity.TimedLine}}	"Success"
+//	@Failure		400		{object}	Response													"Bad Request"
+//	@Failure		401		{object}	Response													"Unauthorized"
+//	@Failure		404		{object}	Response													"Not Found"
+//	@Failure		500		{object}	Response													"Internal Server Error"
+//	@Router			/songs/{name}/lyrics.json [get]
+func (h *Handler) GetSongTimedLyrics(w http.ResponseWriter, r *http.Request) *errs.AppError {
+	params := httprouter.ParamsFromContext(r.Context())
+	name := params.ByName("name")
+
+	if err := validateName(name); err != nil {
+		h.log(r).Error("Validation failed", zap.Error(err))
+		return errs.ErrBadRequest
+	}
+
+	pageID, err := validatePage(r.URL.Query().Get("page"))
+	if err != nil {
+		h.log(r).Error("Invalid page id", zap.String("song_name", name), zap.Error(err))
+		return errs.ErrBadRequest
+	}
+
+	window, err := validatePage(r.URL.Query().Get("window"))
+	if err != nil || window < 0 {
+		h.log(r).Error("Invalid window", zap.String("song_name", name), zap.Error(err))
+		return errs.ErrBadRequest
+	}
+
+	content, err := h.usecase.GetSongTimedLyrics(r.Context(), name, pageID, window)
+	if err != nil {
+		h.log(r).Error("Failed get timed lyrics", zap.String("song_name", name), zap.Error(err))
+		if errors.Is(err, errs.ErrNotFound) {
+			return errs.ErrNotFound
+		}
+		return errs.ErrInternal
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(Wrap(content))
+	h.log(r).Info("Song timed lyrics find successfully", zap.String("song_name", name))
 	return nil
 }
 
@@ -177,13 +447,13 @@ func (h *Handler) DeleteSong(w http.ResponseWriter, r *http.Request) *errs.AppEr
 	name := params.ByName("name")
 
 	if err := validateName(name); err != nil {
-		h.logger.Error("Validation failed", zap.Error(err))
+		h.log(r).Error("Validation failed", zap.Error(err))
 		return errs.ErrBadRequest
 	}
 
-	isDeleted, err := h.usecase.DeleteSong(name)
+	isDeleted, err := h.usecase.DeleteSong(r.Context(), name)
 	if err != nil {
-		h.logger.Error("Failed delete song", zap.String("song_name", name), zap.Error(err))
+		h.log(r).Error("Failed delete song", zap.String("song_name", name), zap.Error(err))
 		if errors.Is(err, errs.ErrBadRequest) {
 			return errs.ErrBadRequest
 		}
@@ -191,14 +461,14 @@ func (h *Handler) DeleteSong(w http.ResponseWriter, r *http.Request) *errs.AppEr
 	}
 
 	if !isDeleted {
-		h.logger.Error("Song not found", zap.String("song_name", name), zap.Error(err))
+		h.log(r).Error("Song not found", zap.String("song_name", name), zap.Error(err))
 		return errs.ErrNotFound
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(Wrap(nil))
-	h.logger.Info("Song deleted successfully", zap.String("song_name", name))
+	h.log(r).Info("Song deleted successfully", zap.String("song_name", name))
 	return nil
 }
 
@@ -209,7 +479,7 @@ func (h *Handler) DeleteSong(w http.ResponseWriter, r *http.Request) *errs.AppEr
 //	@Accept		json
 //	@Produce	json
 //	@Param		name	path		string		true	"song name"
-//	@Param		request	body		entity.Song	true	"song text in json"
+//	@Param		request	body		entity.Song	true	"song in json; sections replace the text when set"
 //	@Success	200		{object}	Response	"Success"
 //	@Failure	400		{object}	Response	"Bad Request"
 //	@Failure	401		{object}	Response	"Unauthorized"
@@ -221,20 +491,25 @@ func (h *Handler) UpdateSong(w http.ResponseWriter, r *http.Request) *errs.AppEr
 	name := params.ByName("name")
 
 	if err := validateName(name); err != nil {
-		h.logger.Error("Validation failed", zap.Error(err))
+		h.log(r).Error("Validation failed", zap.Error(err))
 		return errs.ErrBadRequest
 	}
 
 	var updatedSong entity.Song
 	if err := json.NewDecoder(r.Body).Decode(&updatedSong); err != nil {
-		h.logger.Error("Invalid request payload", zap.Error(err))
+		h.log(r).Error("Invalid request payload", zap.Error(err))
 		return errs.ErrBadRequest
 	}
 	defer r.Body.Close()
 
-	isUpdated, err := h.usecase.UpdateSong(name, updatedSong)
+	if updatedSong.Name != nil && reservedSongNames[*updatedSong.Name] {
+		h.log(r).Error("Validation failed", zap.String("new_name", *updatedSong.Name))
+		return errs.ErrBadRequest
+	}
+
+	isUpdated, err := h.usecase.UpdateSong(r.Context(), name, updatedSong)
 	if err != nil {
-		h.logger.Error("Failed update song", zap.String("song_name", name), zap.Error(err))
+		h.log(r).Error("Failed update song", zap.String("song_name", name), zap.Error(err))
 		if errors.Is(err, errs.ErrBadRequest) {
 			return errs.ErrBadRequest
 		}
@@ -242,14 +517,14 @@ func (h *Handler) UpdateSong(w http.ResponseWriter, r *http.Request) *errs.AppEr
 	}
 
 	if !isUpdated {
-		h.logger.Error("Song not found", zap.String("song_name", name), zap.Error(err))
+		h.log(r).Error("Song not found", zap.String("song_name", name), zap.Error(err))
 		return errs.ErrNotFound
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(Wrap(nil))
-	h.logger.Info("Song updated successfully", zap.String("song_name", name))
+	h.log(r).Info("Song updated successfully", zap.String("song_name", name))
 	return nil
 }
 
@@ -268,18 +543,18 @@ func (h *Handler) UpdateSong(w http.ResponseWriter, r *http.Request) *errs.AppEr
 func (h *Handler) AddSong(w http.ResponseWriter, r *http.Request) *errs.AppError {
 	var newSong entity.NewSong
 	if err := json.NewDecoder(r.Body).Decode(&newSong); err != nil {
-		h.logger.Error("Invalid request payload", zap.Error(err))
+		h.log(r).Error("Invalid request payload", zap.Error(err))
 		return errs.ErrBadRequest
 	}
 	defer r.Body.Close()
 
 	if err := validateNewSong(newSong); err != nil {
-		h.logger.Error("Validation failed", zap.Error(err))
+		h.log(r).Error("Validation failed", zap.Error(err))
 		return errs.ErrBadRequest
 	}
 
-	if err := h.usecase.AddSong(newSong); err != nil {
-		h.logger.Error("Failed to add new song", zap.Error(err))
+	if err := h.usecase.AddSong(r.Context(), newSong); err != nil {
+		h.log(r).Error("Failed to add new song", zap.Error(err))
 		if errors.Is(err, errs.ErrBadRequest) {
 			return errs.ErrBadRequest
 		}
@@ -289,10 +564,548 @@ func (h *Handler) AddSong(w http.ResponseWriter, r *http.Request) *errs.AppError
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(Wrap(nil))
-	h.logger.Info("Song added successfully")
+	h.log(r).Info("Song added successfully")
+	return nil
+}
+
+// SearchSongs godoc
+//
+//	@Summary		Full-text search by song name, group and lyrics.
+//	@Description	Results are ranked by relevance; each one carries a highlighted lyrics fragment.
+//	@Tags			Search
+//	@Accept			json
+//	@Produce		json
+//	@Param			q		query		string														true	"search query (websearch syntax)"
+//	@Param			lang	query		string														false	"text search configuration, e.g. english, russian"
+//	@Param			page	query		int															false	"page"	minimum(1)
+//	@Success		200		{object}	Response{content=entity.Content{items=entity.SearchResult}}	"Success"
+//	@Failure		400		{object}	Response													"Bad Request"
+//	@Failure		401		{object}	Response													"Unauthorized"
+//	@Failure		404		{object}	Response													"Not Found"
+//	@Failure		500		{object}	Response													"Internal Server Error"
+//	@Router			/search [get]
+func (h *Handler) SearchSongs(w http.ResponseWriter, r *http.Request) *errs.AppError {
+	pageID, err := validatePage(r.URL.Query().Get("page"))
+	if err != nil {
+		h.log(r).Error("Invalid page id", zap.Error(err))
+		return errs.ErrBadRequest
+	}
+
+	q := r.URL.Query().Get("q")
+	lang := r.URL.Query().Get("lang")
+
+	content, err := h.usecase.SearchSongs(r.Context(), q, lang, pageID)
+	if err != nil {
+		h.log(r).Error("Failed search songs", zap.String("query", q), zap.Error(err))
+		if errors.Is(err, errs.ErrNotFound) {
+			return errs.ErrNotFound
+		}
+		if errors.Is(err, errs.ErrBadRequest) {
+			return errs.ErrBadRequest
+		}
+		return errs.ErrInternal
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(Wrap(content))
+	h.log(r).Info("Songs search successfully", zap.String("query", q))
+	return nil
+}
+
+// GetSimilarSongs godoc
+//
+//	@Summary		Songs similar to the given one.
+//	@Description	Songs of the same group or with similar lyrics, ranked by a weighted score: same group, close release date and lyrics trigram similarity.
+//	@Description	The weights are set in the "similar" config section.
+//	@Tags			Songs
+//	@Accept			json
+//	@Produce		json
+//	@Param			name	path		string															true	"song name"
+//	@Param			page	query		int																false	"page"	minimum(1)
+//	@Success		200		{object}	Response{content=entity.Content{items=[]entity.SimilarSong}}	"Success"
+//	@Failure		400		{object}	Response														"Bad Request"
+//	@Failure		401		{object}	Response														"Unauthorized"
+//	@Failure		404		{object}	Response														"Not Found"
+//	@Failure		500		{object}	Response														"Internal Server Error"
+//	@Router			/songs/{name}/similar [get]
+func (h *Handler) GetSimilarSongs(w http.ResponseWriter, r *http.Request) *errs.AppError {
+	params := httprouter.ParamsFromContext(r.Context())
+	name := params.ByName("name")
+
+	if err := validateName(name); err != nil {
+		h.log(r).Error("Validation failed", zap.Error(err))
+		return errs.ErrBadRequest
+	}
+
+	pageID, err := validatePage(r.URL.Query().Get("page"))
+	if err != nil {
+		h.log(r).Error("Invalid page id", zap.Error(err))
+		return errs.ErrBadRequest
+	}
+
+	content, err := h.usecase.GetSimilarSongs(r.Context(), name, pageID)
+	if err != nil {
+		h.log(r).Error("Failed get similar songs", zap.String("song_name", name), zap.Error(err))
+		if errors.Is(err, errs.ErrNotFound) {
+			return errs.ErrNotFound
+		}
+		return errs.ErrInternal
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(Wrap(content))
+	h.log(r).Info("Similar songs find successfully", zap.String("song_name", name))
+	return nil
+}
+
+// GetSongFacets godoc
+//
+//	@Summary		Song counts per genre, tag, group and release year.
+//	@Description	Takes the same filters as GET /songs and counts only the matching songs.
+//	@Tags			Songs
+//	@Accept			json
+//	@Produce		json
+//	@Param			name			query		string										false	"song name"
+//	@Param			group			query		string										false	"song group"
+//	@Param			artist			query		string										false	"any song artist (primary, featured, composer, lyricist)"
+//	@Param			release_date	query		string										false	"song release date"
+//	@Param			album			query		string										false	"album title"
+//	@Param			album_id		query		int											false	"album id"
+//	@Param			genre			query		string										false	"genre from the vocabulary"
+//	@Param			tag				query		string										false	"free-form tag"
+//	@Param			link_status		query		string										false	"link check status"	Enums(unchecked, ok, broken)
+//	@Success		200				{object}	ResponseFacets{facets=entity.SongFacets}	"Success"
+//	@Failure		400				{object}	Response									"Bad Request"
+//	@Failure		401				{object}	Response									"Unauthorized"
+//	@Failure		404				{object}	Response									"Not Found"
+//	@Failure		500				{object}	Response									"Internal Server Error"
+//	@Router			/songs/facets [get]
+func (h *Handler) GetSongFacets(w http.ResponseWriter, r *http.Request) *errs.AppError {
+	song, err := filterFromQuery(r)
+	if err != nil {
+		h.log(r).Error("Validation failed", zap.Error(err))
+		return errs.ErrBadRequest
+	}
+
+	facets, err := h.usecase.GetSongFacets(r.Context(), song)
+	if err != nil {
+		h.log(r).Error("Failed get song facets", zap.Error(err))
+		if errors.Is(err, errs.ErrNotFound) {
+			return errs.ErrNotFound
+		}
+		if errors.Is(err, errs.ErrBadRequest) {
+			return errs.ErrBadRequest
+		}
+		return errs.ErrInternal
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(Wrap(facets))
+	h.log(r).Info("Song facets find successfully")
 	return nil
 }
 
+// GetSongStats godoc
+//
+//	@Summary		Catalog statistics.
+//	@Description	Songs per group, release year and decade, average verse count and the most recently added songs.
+//	@Description	Takes the same filters as GET /songs; soft-deleted counts cover the whole catalog.
+//	@Description	The response carries an ETag and may be cached for a minute.
+//	@Tags			Songs
+//	@Accept			json
+//	@Produce		json
+//	@Param			name			query		string									false	"song name"
+//	@Param			group			query		string									false	"song group"
+//	@Param			artist			query		string									false	"any song artist (primary, featured, composer, lyricist)"
+//	@Param			release_date	query		string									false	"song release date"
+//	@Param			album			query		string									false	"album title"
+//	@Param			album_id		query		int										false	"album id"
+//	@Param			genre			query		string									false	"genre from the vocabulary"
+//	@Param			tag				query		string									false	"free-form tag"
+//	@Param			link_status		query		string									false	"link check status"	Enums(unchecked, ok, broken)
+//	@Param			If-None-Match	header		string									false	"ETag of a cached response"
+//	@Success		200				{object}	ResponseStats{stats=entity.SongStats}	"Success"
+//	@Success		304				"Not Modified"
+//	@Failure		400				{object}	Response	"Bad Request"
+//	@Failure		401				{object}	Response	"Unauthorized"
+//	@Failure		404				{object}	Response	"Not Found"
+//	@Failure		500				{object}	Response	"Internal Server Error"
+//	@Router			/stats [get]
+func (h *Handler) GetSongStats(w http.ResponseWriter, r *http.Request) *errs.AppError {
+	song, err := filterFromQuery(r)
+	if err != nil {
+		h.log(r).Error("Validation failed", zap.Error(err))
+		return errs.ErrBadRequest
+	}
+
+	stats, err := h.usecase.GetSongStats(r.Context(), song)
+	if err != nil {
+		h.log(r).Error("Failed get song stats", zap.Error(err))
+		if errors.Is(err, errs.ErrNotFound) {
+			return errs.ErrNotFound
+		}
+		if errors.Is(err, errs.ErrBadRequest) {
+			return errs.ErrBadRequest
+		}
+		return errs.ErrInternal
+	}
+
+	var body bytes.Buffer
+	if err := json.NewEncoder(&body).Encode(Wrap(stats)); err != nil {
+		h.log(r).Error("Can't encode song stats", zap.Error(err))
+		return errs.ErrInternal
+	}
+
+	sum := sha256.Sum256(body.Bytes())
+	etag := `"` + hex.EncodeToString(sum[:8]) + `"`
+
+	w.Header().Set("Cache-Control", fmt.Sprintf("private, max-age=%d", _statsMaxAge))
+	w.Header().Set("ETag", etag)
+
+	if etagMatch(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		h.log(r).Info("Song stats not modified")
+		return nil
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(body.Bytes())
+	h.log(r).Info("Song stats find successfully")
+	return nil
+}
+
+// GetGenres godoc
+//
+//	@Summary	Get genre vocabulary.
+//	@Tags		Songs
+//	@Accept		json
+//	@Produce	json
+//	@Success	200	{object}	Response{content=entity.Content{items=[]string}}	"Success"
+//	@Failure	401	{object}	Response											"Unauthorized"
+//	@Failure	500	{object}	Response											"Internal Server Error"
+//	@Router		/genres [get]
+func (h *Handler) GetGenres(w http.ResponseWriter, r *http.Request) *errs.AppError {
+	genres, err := h.usecase.GetGenres(r.Context())
+	if err != nil {
+		h.log(r).Error("Failed get genres", zap.Error(err))
+		return errs.ErrInternal
+	}
+
+	content := entity.Content{
+		CurrentPage: 1,
+		TotalPage:   1,
+		TotalItems:  len(genres),
+		Items:       genres,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(Wrap(content))
+	h.log(r).Info("Genres find successfully")
+	return nil
+}
+
+// ImportSongs godoc
+//
+//	@Summary		Bulk import of songs.
+//	@Description	Accepts NDJSON or CSV (header: group,song,release_date,text,link,artists,genres,tags,lang) and returns a per-row report.
+//	@Description	In CSV the text, artists, genres and tags cells hold JSON arrays, as written by the export.
+//	@Description	A batch over 1000 rows or 64 MiB, or one that can't be read, is rejected before any row is imported.
+//	@Tags			Songs
+//	@Accept			application/x-ndjson,text/csv
+//	@Produce		json
+//	@Param			format	query		string				false	"batch format, overrides Content-Type"	Enums(ndjson, csv)
+//	@Param			enrich	query		bool				false	"fill missing fields from the external service"
+//	@Param			request	body		[]entity.ImportSong	true	"songs batch"
+//	@Success		200		{object}	ResponseReport		"Success"
+//	@Failure		400		{object}	Response			"Bad Request"
+//	@Failure		401		{object}	Response			"Unauthorized"
+//	@Failure		500		{object}	Response			"Internal Server Error"
+//	@Router			/songs:import [post]
+func (h *Handler) ImportSongs(w http.ResponseWriter, r *http.Request) *errs.AppError {
+	r.Body = http.MaxBytesReader(w, r.Body, _importBodyLimit)
+	defer r.Body.Close()
+
+	// Большой пакет и обогащение по строкам идут дольше серверных таймаутов:
+	// без продления отчёт по уже сохранённым строкам потерялся бы.
+	rc := http.NewResponseController(w)
+	deadline := time.Now().Add(_importTimeout)
+	if err := errors.Join(rc.SetReadDeadline(deadline), rc.SetWriteDeadline(deadline)); err != nil {
+		h.log(r).Warn("Can't extend import deadlines", zap.Error(err))
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = catalog.FormatByContentType(r.Header.Get("Content-Type"))
+	}
+
+	enrich, err := validateBool(r.URL.Query().Get("enrich"))
+	if err != nil {
+		h.log(r).Error("Invalid enrich flag", zap.Error(err))
+		return errs.ErrBadRequest
+	}
+
+	reader, err := catalog.NewReader(format, r.Body)
+	if err != nil {
+		h.log(r).Error("Invalid import batch", zap.String("format", format), zap.Error(err))
+		return errs.ErrBadRequest
+	}
+
+	report, err := catalog.Import(reader, _importBatchLimit, func(row entity.ImportSong) (bool, error) {
+		if reservedSongNames[row.Name] {
+			return false, errs.ErrBadRequest
+		}
+		return h.usecase.ImportSong(r.Context(), row, enrich)
+	})
+	if err != nil {
+		h.log(r).Error("Failed to read import batch", zap.Error(err))
+		return errs.ErrBadRequest
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(Wrap(report))
+	h.log(r).Info("Songs imported",
+		zap.Int("total", report.Total),
+		zap.Int("imported", report.Imported),
+		zap.Int("failed", report.Failed))
+	return nil
+}
+
+// ExportSongs godoc
+//
+//	@Summary	Stream the filtered song catalog.
+//	@Tags		Songs
+//	@Produce	application/x-ndjson,text/csv
+//	@Param		format			query		string				false	"export format"	Enums(ndjson, csv)	default(ndjson)
+//	@Param		name			query		string				false	"song name"
+//	@Param		group			query		string				false	"song group"
+//	@Param		artist			query		string				false	"any song artist (primary, featured, composer, lyricist)"
+//	@Param		release_date	query		string				false	"song release date"
+//	@Param		album			query		string				false	"album title"
+//	@Param		album_id		query		int					false	"album id"
+//	@Success	200				{array}		entity.ImportSong	"Success"
+//	@Failure	400				{object}	Response			"Bad Request"
+//	@Failure	401				{object}	Response			"Unauthorized"
+//	@Failure	404				{object}	Response			"Not Found"
+//	@Failure	500				{object}	Response			"Internal Server Error"
+//	@Router		/songs:export [get]
+func (h *Handler) ExportSongs(w http.ResponseWriter, r *http.Request) *errs.AppError {
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = catalog.FormatNDJSON
+	}
+
+	sw := newStreamWriter(w, catalog.ContentType(format), "songs."+format)
+	sw.extend()
+
+	writer, err := catalog.NewWriter(format, sw)
+	if err != nil {
+		h.log(r).Error("Invalid export format", zap.String("format", format), zap.Error(err))
+		return errs.ErrBadRequest
+	}
+
+	filter, err := filterFromQuery(r)
+	if err != nil {
+		h.log(r).Error("Invalid filter", zap.Error(err))
+		return errs.ErrBadRequest
+	}
+
+	songs, err := h.usecase.StreamSongs(r.Context(), filter)
+	if err == nil {
+		for song, e := range songs {
+			if err = e; err != nil {
+				break
+			}
+			if err = writer.Write(catalog.FromSong(song)); err != nil {
+				break
+			}
+		}
+	}
+	if err == nil {
+		err = writer.Flush()
+	}
+
+	if err != nil && sw.started {
+		// Заголовки уже отправлены: ответ можно только оборвать.
+		h.log(r).Error("Export interrupted", zap.Error(err))
+		return nil
+	}
+	if err != nil {
+		h.log(r).Error("Failed export songs", zap.Error(err))
+		if errors.Is(err, errs.ErrNotFound) {
+			return errs.ErrNotFound
+		}
+		if errors.Is(err, errs.ErrBadRequest) {
+			return errs.ErrBadRequest
+		}
+		return errs.ErrInternal
+	}
+
+	sw.begin()
+	h.log(r).Info("Songs exported successfully", zap.String("format", format))
+	return nil
+}
+
+// streamFilteredSongs отдаёт песни построчно (NDJSON), не собирая весь список в памяти.
+func (h *Handler) streamFilteredSongs(w http.ResponseWriter, r *http.Request, filter entity.FilterSong) *errs.AppError {
+	// Дедлайн записи продлевается каждой строкой: большой каталог не обрывается по WriteTimeout.
+	sw := newStreamWriter(w, catalog.ContentTypeNDJSON, "")
+	sw.extend()
+
+	songs, err := h.usecase.StreamSongs(r.Context(), filter)
+	if err != nil {
+		h.log(r).Error("Failed get filtered songs", zap.Error(err))
+		if errors.Is(err, errs.ErrNotFound) {
+			return errs.ErrNotFound
+		}
+		if errors.Is(err, errs.ErrBadRequest) {
+			return errs.ErrBadRequest
+		}
+		return errs.ErrInternal
+	}
+
+	enc := json.NewEncoder(sw)
+
+	for song, err := range songs {
+		if err == nil {
+			err = enc.Encode(song)
+		}
+		if err != nil && sw.started {
+			h.log(r).Error("Songs stream interrupted", zap.Error(err))
+			return nil
+		}
+		if err != nil {
+			h.log(r).Error("Failed get filtered songs", zap.Error(err))
+			if errors.Is(err, errs.ErrBadRequest) {
+				return errs.ErrBadRequest
+			}
+			return errs.ErrInternal
+		}
+	}
+
+	sw.begin()
+	h.log(r).Info("Songs streamed successfully")
+	return nil
+}
+
+func filterFromQuery(r *http.Request) (entity.FilterSong, error) {
+	var namePtr, groupPtr, artistPtr, releaseDatePTR, albumPtr, genrePtr, tagPtr, linkStatusPtr, sortPtr, windowPtr *string
+	var albumIDPtr *int
+	name := r.URL.Query().Get("name")
+	group := r.URL.Query().Get("group")
+	artist := r.URL.Query().Get("artist")
+	releaseDate := r.URL.Query().Get("release_date")
+	album := r.URL.Query().Get("album")
+	genre := r.URL.Query().Get("genre")
+	tag := r.URL.Query().Get("tag")
+	linkStatus := r.URL.Query().Get("link_status")
+	sort := r.URL.Query().Get("sort")
+	window := r.URL.Query().Get("window")
+
+	if name != "" {
+		namePtr = &name
+	}
+	if group != "" {
+		groupPtr = &group
+	}
+	if artist != "" {
+		artistPtr = &artist
+	}
+	if releaseDate != "" {
+		releaseDatePTR = &releaseDate
+	}
+	if album != "" {
+		albumPtr = &album
+	}
+	if genre != "" {
+		genrePtr = &genre
+	}
+	if tag != "" {
+		tagPtr = &tag
+	}
+	if linkStatus != "" {
+		linkStatusPtr = &linkStatus
+	}
+	if sort != "" {
+		sortPtr = &sort
+	}
+	if window != "" {
+		windowPtr = &window
+	}
+	if albumID := r.URL.Query().Get("album_id"); albumID != "" {
+		id, err := validateID(albumID)
+		if err != nil {
+			return entity.FilterSong{}, err
+		}
+		albumIDPtr = &id
+	}
+
+	return entity.FilterSong{
+		Name:        namePtr,
+		Group:       groupPtr,
+		Artist:      artistPtr,
+		ReleaseDate: releaseDatePTR,
+		Album:       albumPtr,
+		AlbumID:     albumIDPtr,
+		Genre:       genrePtr,
+		Tag:         tagPtr,
+		LinkStatus:  linkStatusPtr,
+		Sort:        sortPtr,
+		Window:      windowPtr,
+	}, nil
+}
+
+// songLangs возвращает языки текста из lang (один или два тега; два -- текст бок о бок)
+// или, если lang не задан, из Accept-Language по убыванию веса.
+func songLangs(r *http.Request) (langs []string, aligned bool, err error) {
+	if lang := r.URL.Query().Get("lang"); lang != "" {
+		for _, tag := range strings.Split(lang, ",") {
+			if _, err := language.Parse(strings.TrimSpace(tag)); err != nil {
+				return nil, false, err
+			}
+			langs = append(langs, strings.TrimSpace(tag))
+		}
+		if len(langs) > 2 {
+			return nil, false, fmt.Errorf("too many languages: %d", len(langs))
+		}
+		return langs, len(langs) == 2, nil
+	}
+
+	// Некорректный Accept-Language не ошибка: выдаётся оригинал.
+	tags, _, err := language.ParseAcceptLanguage(r.Header.Get("Accept-Language"))
+	if err != nil {
+		return nil, false, nil
+	}
+	for _, tag := range tags {
+		langs = append(langs, tag.String())
+	}
+
+	return langs, false, nil
+}
+
+func validateID(s string) (int, error) {
+	id, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, err
+	}
+	if id <= 0 {
+		return 0, fmt.Errorf("invalid id %d", id)
+	}
+	return id, nil
+}
+
+func validateBool(s string) (bool, error) {
+	if s == "" {
+		return false, nil
+	}
+	return strconv.ParseBool(s)
+}
+
 func validatePage(page string) (id int, err error) {
 	if page != "" {
 		id, err = strconv.Atoi(page)
@@ -308,15 +1121,42 @@ func validateName(s string) error {
 	if s == "" {
 		return fmt.Errorf("missing or invalid query name")
 	}
+	if reservedSongNames[s] {
+		return fmt.Errorf("song name %q is reserved", s)
+	}
 	return nil
 }
 
 func validateNewSong(song entity.NewSong) error {
-	if song.Group == "" {
+	hasPrimary := false
+	for _, artist := range song.Artists {
+		if artist.Name == "" {
+			return fmt.Errorf("missing or invalid artist name")
+		}
+		if artist.Role == entity.RolePrimary {
+			hasPrimary = true
+		}
+	}
+
+	if song.Group == "" && !hasPrimary {
 		return fmt.Errorf("missing or invalid song group")
 	}
 	if song.Name == "" {
 		return fmt.Errorf("missing or invalid song name")
 	}
+	if reservedSongNames[song.Name] {
+		return fmt.Errorf("song name %q is reserved", song.Name)
+	}
 	return nil
 }
+
+func acceptsNDJSON(r *http.Request) bool {
+	for _, accept := range r.Header.Values("Accept") {
+		for _, mediaType := range strings.Split(accept, ",") {
+			if catalog.FormatByContentType(mediaType) == catalog.FormatNDJSON {
+				return true
+			}
+		}
+	}
+	return false
+}
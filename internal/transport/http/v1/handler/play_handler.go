@@ -0,0 +1,100 @@
+package http_v1_handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"go-rest-api/internal/entity"
+	"go-rest-api/internal/errs"
+
+	"github.com/julienschmidt/httprouter"
+	"go.uber.org/zap"
+)
+
+type PlayUsecase interface {
+	RecordPlay(context.Context, string) error
+	GetPopularSongs(context.Context, string, int) (entity.Content, error)
+}
+
+// RecordSongPlay godoc
+//
+//	@Summary		Record a song play.
+//	@Description	The play is written asynchronously in batches; lyrics reads are recorded automatically.
+//	@Tags			Songs
+//	@Accept			json
+//	@Produce		json
+//	@Param			name	path		string		true	"song name"
+//	@Success		202		{object}	Response	"Accepted"
+//	@Failure		400		{object}	Response	"Bad Request"
+//	@Failure		401		{object}	Response	"Unauthorized"
+//	@Failure		404		{object}	Response	"Not Found"
+//	@Failure		500		{object}	Response	"Internal Server Error"
+//	@Router			/songs/{name}/plays [post]
+func (h *Handler) RecordSongPlay(w http.ResponseWriter, r *http.Request) *errs.AppError {
+	params := httprouter.ParamsFromContext(r.Context())
+	name := params.ByName("name")
+
+	if err := validateName(name); err != nil {
+		h.log(r).Error("Validation failed", zap.Error(err))
+		return errs.ErrBadRequest
+	}
+
+	if err := h.usecase.RecordPlay(r.Context(), name); err != nil {
+		h.log(r).Error("Failed record song play", zap.String("song_name", name), zap.Error(err))
+		if errors.Is(err, errs.ErrNotFound) {
+			return errs.ErrNotFound
+		}
+		return errs.ErrInternal
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(Wrap(nil))
+	h.log(r).Info("Song play recorded", zap.String("song_name", name))
+	return nil
+}
+
+// GetPopularSongs godoc
+//
+//	@Summary		Most played and read songs.
+//	@Description	Songs ranked by explicit plays plus lyrics reads within the window.
+//	@Tags			Songs
+//	@Accept			json
+//	@Produce		json
+//	@Param			window	query		string															false	"window: 7d (default), 30d, 12h; at most 365d"
+//	@Param			page	query		int																false	"page"	minimum(1)
+//	@Success		200		{object}	Response{content=entity.Content{items=[]entity.PopularSong}}	"Success"
+//	@Failure		400		{object}	Response														"Bad Request"
+//	@Failure		401		{object}	Response														"Unauthorized"
+//	@Failure		404		{object}	Response														"Not Found"
+//	@Failure		500		{object}	Response														"Internal Server Error"
+//	@Router			/songs/popular [get]
+func (h *Handler) GetPopularSongs(w http.ResponseWriter, r *http.Request) *errs.AppError {
+	pageID, err := validatePage(r.URL.Query().Get("page"))
+	if err != nil {
+		h.log(r).Error("Invalid page id", zap.Error(err))
+		return errs.ErrBadRequest
+	}
+
+	window := r.URL.Query().Get("window")
+
+	content, err := h.usecase.GetPopularSongs(r.Context(), window, pageID)
+	if err != nil {
+		h.log(r).Error("Failed get popular songs", zap.String("window", window), zap.Error(err))
+		if errors.Is(err, errs.ErrNotFound) {
+			return errs.ErrNotFound
+		}
+		if errors.Is(err, errs.ErrBadRequest) {
+			return errs.ErrBadRequest
+		}
+		return errs.ErrInternal
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(Wrap(content))
+	h.log(r).Info("Popular songs find successfully", zap.String("window", window))
+	return nil
+}
@@ -0,0 +1,463 @@
+package http_v1_handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"go-rest-api/internal/entity"
+	"go-rest-api/internal/errs"
+
+	"github.com/julienschmidt/httprouter"
+	"go.uber.org/zap"
+)
+
+type PlaylistUsecase interface {
+	AddPlaylist(context.Context, entity.Playlist) (int, error)
+	GetPlaylist(context.Context, int) (entity.Playlist, error)
+	GetPlaylists(context.Context, int) (entity.Content, error)
+	UpdatePlaylist(context.Context, int, entity.Playlist) (bool, error)
+	DeletePlaylist(context.Context, int) (bool, error)
+	GetPlaylistEntries(context.Context, int, int) (entity.Content, error)
+	AddPlaylistEntry(context.Context, int, entity.NewPlaylistEntry) (int, error)
+	MovePlaylistEntry(context.Context, int, int, entity.MovePlaylistEntry) (int, error)
+	DeletePlaylistEntry(context.Context, int, int) error
+	SetPlaylistOrder(context.Context, int, []int) error
+}
+
+// GetPlaylists godoc
+//
+//	@Summary	Get playlists.
+//	@Tags		Playlists
+//	@Accept		json
+//	@Produce	json
+//	@Param		page	query		int															false	"page"	minimum(1)
+//	@Success	200		{object}	Response{content=entity.Content{items=[]entity.Playlist}}	"Success"
+//	@Failure	400		{object}	Response													"Bad Request"
+//	@Failure	401		{object}	Response													"Unauthorized"
+//	@Failure	404		{object}	Response													"Not Found"
+//	@Failure	500		{object}	Response													"Internal Server Error"
+//	@Router		/playlists [get]
+func (h *Handler) GetPlaylists(w http.ResponseWriter, r *http.Request) *errs.AppError {
+	pageID, err := validatePage(r.URL.Query().Get("page"))
+	if err != nil {
+		h.log(r).Error("Invalid page id", zap.Error(err))
+		return errs.ErrBadRequest
+	}
+
+	content, err := h.usecase.GetPlaylists(r.Context(), pageID)
+	if err != nil {
+		h.log(r).Error("Failed get playlists", zap.Error(err))
+		if errors.Is(err, errs.ErrNotFound) {
+			return errs.ErrNotFound
+		}
+		return errs.ErrInternal
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(Wrap(content))
+	h.log(r).Info("Playlists find successfully")
+	return nil
+}
+
+// GetPlaylist godoc
+//
+//	@Summary	Get playlist.
+//	@Tags		Playlists
+//	@Accept		json
+//	@Produce	json
+//	@Param		id	path		int											true	"playlist id"
+//	@Success	200	{object}	ResponsePlaylist{playlist=entity.Playlist}	"Success"
+//	@Failure	400	{object}	Response									"Bad Request"
+//	@Failure	401	{object}	Response									"Unauthorized"
+//	@Failure	404	{object}	Response									"Not Found"
+//	@Failure	500	{object}	Response									"Internal Server Error"
+//	@Router		/playlists/{id} [get]
+func (h *Handler) GetPlaylist(w http.ResponseWriter, r *http.Request) *errs.AppError {
+	id, err := playlistIDFromPath(r)
+	if err != nil {
+		h.log(r).Error("Validation failed", zap.Error(err))
+		return errs.ErrBadRequest
+	}
+
+	playlist, err := h.usecase.GetPlaylist(r.Context(), id)
+	if err != nil {
+		h.log(r).Error("Failed get playlist", zap.Int("playlist_id", id), zap.Error(err))
+		if errors.Is(err, errs.ErrNotFound) {
+			return errs.ErrNotFound
+		}
+		return errs.ErrInternal
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(Wrap(playlist))
+	h.log(r).Info("Playlist find successfully", zap.Int("playlist_id", id))
+	return nil
+}
+
+// AddPlaylist godoc
+//
+//	@Summary	Adding a new playlist.
+//	@Tags		Playlists
+//	@Accept		json
+//	@Produce	json
+//	@Param		request	body		entity.Playlist								true	"json"
+//	@Success	201		{object}	ResponsePlaylist{playlist=entity.Playlist}	"Success"
+//	@Failure	400		{object}	Response									"Bad Request"
+//	@Failure	401		{object}	Response									"Unauthorized"
+//	@Failure	500		{object}	Response									"Internal Server Error"
+//	@Router		/playlists [post]
+func (h *Handler) AddPlaylist(w http.ResponseWriter, r *http.Request) *errs.AppError {
+	var playlist entity.Playlist
+	if err := json.NewDecoder(r.Body).Decode(&playlist); err != nil {
+		h.log(r).Error("Invalid request payload", zap.Error(err))
+		return errs.ErrBadRequest
+	}
+	defer r.Body.Close()
+
+	id, err := h.usecase.AddPlaylist(r.Context(), playlist)
+	if err != nil {
+		h.log(r).Error("Failed to add new playlist", zap.Error(err))
+		if errors.Is(err, errs.ErrBadRequest) {
+			return errs.ErrBadRequest
+		}
+		return errs.ErrInternal
+	}
+
+	playlist, err = h.usecase.GetPlaylist(r.Context(), id)
+	if err != nil {
+		h.log(r).Error("Failed get playlist", zap.Int("playlist_id", id), zap.Error(err))
+		return errs.ErrInternal
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(Wrap(playlist))
+	h.log(r).Info("Playlist added successfully", zap.Int("playlist_id", id))
+	return nil
+}
+
+// UpdatePlaylist godoc
+//
+//	@Summary	Update playlist name or description.
+//	@Tags		Playlists
+//	@Accept		json
+//	@Produce	json
+//	@Param		id		path		int				true	"playlist id"
+//	@Param		request	body		entity.Playlist	true	"json"
+//	@Success	200		{object}	Response		"Success"
+//	@Failure	400		{object}	Response		"Bad Request"
+//	@Failure	401		{object}	Response		"Unauthorized"
+//	@Failure	404		{object}	Response		"Not Found"
+//	@Failure	500		{object}	Response		"Internal Server Error"
+//	@Router		/playlists/{id} [put]
+func (h *Handler) UpdatePlaylist(w http.ResponseWriter, r *http.Request) *errs.AppError {
+	id, err := playlistIDFromPath(r)
+	if err != nil {
+		h.log(r).Error("Validation failed", zap.Error(err))
+		return errs.ErrBadRequest
+	}
+
+	var playlist entity.Playlist
+	if err := json.NewDecoder(r.Body).Decode(&playlist); err != nil {
+		h.log(r).Error("Invalid request payload", zap.Error(err))
+		return errs.ErrBadRequest
+	}
+	defer r.Body.Close()
+
+	isUpdated, err := h.usecase.UpdatePlaylist(r.Context(), id, playlist)
+	if err != nil {
+		h.log(r).Error("Failed update playlist", zap.Int("playlist_id", id), zap.Error(err))
+		if errors.Is(err, errs.ErrBadRequest) {
+			return errs.ErrBadRequest
+		}
+		return errs.ErrInternal
+	}
+
+	if !isUpdated {
+		h.log(r).Error("Playlist not found", zap.Int("playlist_id", id))
+		return errs.ErrNotFound
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(Wrap(nil))
+	h.log(r).Info("Playlist updated successfully", zap.Int("playlist_id", id))
+	return nil
+}
+
+// DeletePlaylist godoc
+//
+//	@Summary	Delete playlist.
+//	@Tags		Playlists
+//	@Accept		json
+//	@Produce	json
+//	@Param		id	path		int			true	"playlist id"
+//	@Success	200	{object}	Response	"Success"
+//	@Failure	400	{object}	Response	"Bad Request"
+//	@Failure	401	{object}	Response	"Unauthorized"
+//	@Failure	404	{object}	Response	"Not Found"
+//	@Failure	500	{object}	Response	"Internal Server Error"
+//	@Router		/playlists/{id} [delete]
+func (h *Handler) DeletePlaylist(w http.ResponseWriter, r *http.Request) *errs.AppError {
+	id, err := playlistIDFromPath(r)
+	if err != nil {
+		h.log(r).Error("Validation failed", zap.Error(err))
+		return errs.ErrBadRequest
+	}
+
+	isDeleted, err := h.usecase.DeletePlaylist(r.Context(), id)
+	if err != nil {
+		h.log(r).Error("Failed delete playlist", zap.Int("playlist_id", id), zap.Error(err))
+		return errs.ErrInternal
+	}
+
+	if !isDeleted {
+		h.log(r).Error("Playlist not found", zap.Int("playlist_id", id))
+		return errs.ErrNotFound
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(Wrap(nil))
+	h.log(r).Info("Playlist deleted successfully", zap.Int("playlist_id", id))
+	return nil
+}
+
+// GetPlaylistEntries godoc
+//
+//	@Summary		Get playlist entries in order.
+//	@Description	Soft-deleted songs stay in the playlist with available=false.
+//	@Tags			Playlists
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		int																true	"playlist id"
+//	@Param			page	query		int																false	"page"	minimum(1)
+//	@Success		200		{object}	Response{content=entity.Content{items=[]entity.PlaylistEntry}}	"Success"
+//	@Failure		400		{object}	Response														"Bad Request"
+//	@Failure		401		{object}	Response														"Unauthorized"
+//	@Failure		404		{object}	Response														"Not Found"
+//	@Failure		500		{object}	Response														"Internal Server Error"
+//	@Router			/playlists/{id}/entries [get]
+func (h *Handler) GetPlaylistEntries(w http.ResponseWriter, r *http.Request) *errs.AppError {
+	id, err := playlistIDFromPath(r)
+	if err != nil {
+		h.log(r).Error("Validation failed", zap.Error(err))
+		return errs.ErrBadRequest
+	}
+
+	pageID, err := validatePage(r.URL.Query().Get("page"))
+	if err != nil {
+		h.log(r).Error("Invalid page id", zap.Error(err))
+		return errs.ErrBadRequest
+	}
+
+	content, err := h.usecase.GetPlaylistEntries(r.Context(), id, pageID)
+	if err != nil {
+		h.log(r).Error("Failed get playlist entries", zap.Int("playlist_id", id), zap.Error(err))
+		if errors.Is(err, errs.ErrNotFound) {
+			return errs.ErrNotFound
+		}
+		return errs.ErrInternal
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(Wrap(content))
+	h.log(r).Info("Playlist entries find successfully", zap.Int("playlist_id", id))
+	return nil
+}
+
+// AddPlaylistEntry godoc
+//
+//	@Summary		Add song to playlist.
+//	@Description	Inserts the song at position (1-based), shifting the following entries; without position the song is appended. A song can be in a playlist only once.
+//	@Tags			Playlists
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		int														true	"playlist id"
+//	@Param			request	body		entity.NewPlaylistEntry									true	"json"
+//	@Success		201		{object}	ResponsePlaylistEntry{entry=entity.NewPlaylistEntry}	"Success"
+//	@Failure		400		{object}	Response												"Bad Request"
+//	@Failure		401		{object}	Response												"Unauthorized"
+//	@Failure		404		{object}	Response												"Not Found"
+//	@Failure		409		{object}	Response												"Conflict"
+//	@Failure		500		{object}	Response												"Internal Server Error"
+//	@Router			/playlists/{id}/entries [post]
+func (h *Handler) AddPlaylistEntry(w http.ResponseWriter, r *http.Request) *errs.AppError {
+	id, err := playlistIDFromPath(r)
+	if err != nil {
+		h.log(r).Error("Validation failed", zap.Error(err))
+		return errs.ErrBadRequest
+	}
+
+	var entry entity.NewPlaylistEntry
+	if err := json.NewDecoder(r.Body).Decode(&entry); err != nil {
+		h.log(r).Error("Invalid request payload", zap.Error(err))
+		return errs.ErrBadRequest
+	}
+	defer r.Body.Close()
+
+	entry.Position, err = h.usecase.AddPlaylistEntry(r.Context(), id, entry)
+	if err != nil {
+		h.log(r).Error("Failed add playlist entry", zap.Int("playlist_id", id), zap.Error(err))
+		return playlistEntryError(err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(Wrap(entry))
+	h.log(r).Info("Playlist entry added successfully", zap.Int("playlist_id", id), zap.Int("song_id", entry.SongID))
+	return nil
+}
+
+// MovePlaylistEntry godoc
+//
+//	@Summary		Move song within playlist.
+//	@Description	Moves the song to position (1-based), shifting the entries in between; a position past the end moves it to the end.
+//	@Tags			Playlists
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		int														true	"playlist id"
+//	@Param			song_id	path		int														true	"song id"
+//	@Param			request	body		entity.MovePlaylistEntry								true	"json"
+//	@Success		200		{object}	ResponsePlaylistEntry{entry=entity.NewPlaylistEntry}	"Success"
+//	@Failure		400		{object}	Response												"Bad Request"
+//	@Failure		401		{object}	Response												"Unauthorized"
+//	@Failure		404		{object}	Response												"Not Found"
+//	@Failure		500		{object}	Response												"Internal Server Error"
+//	@Router			/playlists/{id}/entries/{song_id} [patch]
+func (h *Handler) MovePlaylistEntry(w http.ResponseWriter, r *http.Request) *errs.AppError {
+	id, songID, err := playlistEntryFromPath(r)
+	if err != nil {
+		h.log(r).Error("Validation failed", zap.Error(err))
+		return errs.ErrBadRequest
+	}
+
+	var move entity.MovePlaylistEntry
+	if err := json.NewDecoder(r.Body).Decode(&move); err != nil {
+		h.log(r).Error("Invalid request payload", zap.Error(err))
+		return errs.ErrBadRequest
+	}
+	defer r.Body.Close()
+
+	position, err := h.usecase.MovePlaylistEntry(r.Context(), id, songID, move)
+	if err != nil {
+		h.log(r).Error("Failed move playlist entry", zap.Int("playlist_id", id), zap.Int("song_id", songID), zap.Error(err))
+		return playlistEntryError(err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(Wrap(entity.NewPlaylistEntry{SongID: songID, Position: position}))
+	h.log(r).Info("Playlist entry moved successfully", zap.Int("playlist_id", id), zap.Int("song_id", songID))
+	return nil
+}
+
+// DeletePlaylistEntry godoc
+//
+//	@Summary	Remove song from playlist.
+//	@Tags		Playlists
+//	@Accept		json
+//	@Produce	json
+//	@Param		id		path		int			true	"playlist id"
+//	@Param		song_id	path		int			true	"song id"
+//	@Success	200		{object}	Response	"Success"
+//	@Failure	400		{object}	Response	"Bad Request"
+//	@Failure	401		{object}	Response	"Unauthorized"
+//	@Failure	404		{object}	Response	"Not Found"
+//	@Failure	500		{object}	Response	"Internal Server Error"
+//	@Router		/playlists/{id}/entries/{song_id} [delete]
+func (h *Handler) DeletePlaylistEntry(w http.ResponseWriter, r *http.Request) *errs.AppError {
+	id, songID, err := playlistEntryFromPath(r)
+	if err != nil {
+		h.log(r).Error("Validation failed", zap.Error(err))
+		return errs.ErrBadRequest
+	}
+
+	if err := h.usecase.DeletePlaylistEntry(r.Context(), id, songID); err != nil {
+		h.log(r).Error("Failed delete playlist entry", zap.Int("playlist_id", id), zap.Int("song_id", songID), zap.Error(err))
+		return playlistEntryError(err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(Wrap(nil))
+	h.log(r).Info("Playlist entry deleted successfully", zap.Int("playlist_id", id), zap.Int("song_id", songID))
+	return nil
+}
+
+// SetPlaylistOrder godoc
+//
+//	@Summary		Reorder playlist.
+//	@Description	Sets the order of the playlist entries; the list must contain exactly the song ids already in the playlist.
+//	@Tags			Playlists
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		int			true	"playlist id"
+//	@Param			request	body		[]int		true	"ordered song ids"
+//	@Success		200		{object}	Response	"Success"
+//	@Failure		400		{object}	Response	"Bad Request"
+//	@Failure		401		{object}	Response	"Unauthorized"
+//	@Failure		404		{object}	Response	"Not Found"
+//	@Failure		500		{object}	Response	"Internal Server Error"
+//	@Router			/playlists/{id}/entries [put]
+func (h *Handler) SetPlaylistOrder(w http.ResponseWriter, r *http.Request) *errs.AppError {
+	id, err := playlistIDFromPath(r)
+	if err != nil {
+		h.log(r).Error("Validation failed", zap.Error(err))
+		return errs.ErrBadRequest
+	}
+
+	var songIDs []int
+	if err := json.NewDecoder(r.Body).Decode(&songIDs); err != nil {
+		h.log(r).Error("Invalid request payload", zap.Error(err))
+		return errs.ErrBadRequest
+	}
+	defer r.Body.Close()
+
+	if err := h.usecase.SetPlaylistOrder(r.Context(), id, songIDs); err != nil {
+		h.log(r).Error("Failed set playlist order", zap.Int("playlist_id", id), zap.Error(err))
+		return playlistEntryError(err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(Wrap(nil))
+	h.log(r).Info("Playlist order updated successfully", zap.Int("playlist_id", id))
+	return nil
+}
+
+func playlistIDFromPath(r *http.Request) (int, error) {
+	params := httprouter.ParamsFromContext(r.Context())
+	return validateID(params.ByName("id"))
+}
+
+func playlistEntryFromPath(r *http.Request) (id, songID int, err error) {
+	params := httprouter.ParamsFromContext(r.Context())
+
+	if id, err = validateID(params.ByName("id")); err != nil {
+		return 0, 0, err
+	}
+	if songID, err = validateID(params.ByName("song_id")); err != nil {
+		return 0, 0, err
+	}
+
+	return id, songID, nil
+}
+
+func playlistEntryError(err error) *errs.AppError {
+	switch {
+	case errors.Is(err, errs.ErrNotFound):
+		return errs.ErrNotFound
+	case errors.Is(err, errs.ErrBadRequest):
+		return errs.ErrBadRequest
+	case errors.Is(err, errs.ErrConflict):
+		return errs.ErrConflict
+	default:
+		return errs.ErrInternal
+	}
+}
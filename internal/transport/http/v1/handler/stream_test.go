@@ -0,0 +1,140 @@
+package http_v1_handler
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"iter"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go-rest-api/internal/entity"
+	"go-rest-api/internal/errs"
+)
+
+const (
+	_testServerTimeout = 200 * time.Millisecond
+	_testRowDelay      = 100 * time.Millisecond
+	_testRows          = 5
+)
+
+// stubUsecase подменяет только методы, которые вызывают проверяемые хендлеры.
+type stubUsecase struct {
+	Usecase
+}
+
+func (stubUsecase) StreamSongs(context.Context, entity.FilterSong) (iter.Seq2[entity.Song, error], error) {
+	return func(yield func(entity.Song, error) bool) {
+		for i := range _testRows {
+			time.Sleep(_testRowDelay)
+			name := fmt.Sprintf("song %d", i)
+			if !yield(entity.Song{Name: &name}, nil) {
+				return
+			}
+		}
+	}, nil
+}
+
+func (stubUsecase) ImportSong(context.Context, entity.ImportSong, bool) (bool, error) {
+	time.Sleep(_testRowDelay)
+	return true, nil
+}
+
+// newSlowServer -- сервер с таймаутами короче, чем идёт ответ хендлера.
+func newSlowServer(t *testing.T, h func(http.ResponseWriter, *http.Request) *errs.AppError) *httptest.Server {
+	t.Helper()
+
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := h(w, r); err != nil {
+			http.Error(w, err.Msg, http.StatusInternalServerError)
+		}
+	}))
+	srv.Config.ReadTimeout = _testServerTimeout
+	srv.Config.WriteTimeout = _testServerTimeout
+	srv.Start()
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func countLines(t *testing.T, body io.Reader) int {
+	t.Helper()
+
+	lines := 0
+	scanner := bufio.NewScanner(body)
+	for scanner.Scan() {
+		lines++
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatalf("stream cut off after %d lines: %v", lines, err)
+	}
+	return lines
+}
+
+func TestExportSongsOutlivesWriteTimeout(t *testing.T) {
+	h := New(context.Background(), stubUsecase{})
+	srv := newSlowServer(t, h.ExportSongs)
+
+	res, err := http.Get(srv.URL + "/api/v1/songs:export?format=ndjson")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	if got := countLines(t, res.Body); got != _testRows {
+		t.Errorf("got %d rows, want %d", got, _testRows)
+	}
+}
+
+func TestStreamFilteredSongsOutlivesWriteTimeout(t *testing.T) {
+	h := New(context.Background(), stubUsecase{})
+	srv := newSlowServer(t, h.GetFilteredSongs)
+
+	req, err := http.NewRequest(http.MethodGet, srv.URL+"/api/v1/songs", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Accept", "application/x-ndjson")
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	if got := countLines(t, res.Body); got != _testRows {
+		t.Errorf("got %d songs, want %d", got, _testRows)
+	}
+}
+
+func TestImportSongsOutlivesServerTimeouts(t *testing.T) {
+	h := New(context.Background(), stubUsecase{})
+	srv := newSlowServer(t, h.ImportSongs)
+
+	// Тело приходит медленнее ReadTimeout, а обработка строк дольше WriteTimeout.
+	body, pw := io.Pipe()
+	go func() {
+		for i := range _testRows {
+			time.Sleep(_testRowDelay)
+			fmt.Fprintf(pw, `{"group":"g","song":"song %d"}`+"\n", i)
+		}
+		pw.Close()
+	}()
+
+	res, err := http.Post(srv.URL+"/api/v1/songs:import", "application/x-ndjson", body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	var resp ResponseReport
+	if err := json.NewDecoder(res.Body).Decode(&resp); err != nil {
+		t.Fatalf("report lost: %v", err)
+	}
+	if resp.Report.Total != _testRows || resp.Report.Imported != _testRows {
+		t.Errorf("got %d of %d imported, want %d", resp.Report.Imported, resp.Report.Total, _testRows)
+	}
+}
@@ -0,0 +1,163 @@
+package http_v1_handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"go-rest-api/internal/entity"
+	"go-rest-api/internal/errs"
+
+	"github.com/julienschmidt/httprouter"
+	"go.uber.org/zap"
+)
+
+type TranslationUsecase interface {
+	GetSongTranslations(context.Context, string) ([]entity.Translation, error)
+	SetSongTranslation(context.Context, string, string, []string) (bool, error)
+	DeleteSongTranslation(context.Context, string, string) (bool, error)
+}
+
+// GetSongTranslations godoc
+//
+//	@Summary		Get song text languages.
+//	@Description	The original comes first; its lang is empty when the original language is not set.
+//	@Tags			Translations
+//	@Accept			json
+//	@Produce		json
+//	@Param			name	path		string															true	"song name"
+//	@Success		200		{object}	Response{content=entity.Content{items=[]entity.Translation}}	"Success"
+//	@Failure		400		{object}	Response														"Bad Request"
+//	@Failure		401		{object}	Response														"Unauthorized"
+//	@Failure		404		{object}	Response														"Not Found"
+//	@Failure		500		{object}	Response														"Internal Server Error"
+//	@Router			/songs/{name}/translations [get]
+func (h *Handler) GetSongTranslations(w http.ResponseWriter, r *http.Request) *errs.AppError {
+	params := httprouter.ParamsFromContext(r.Context())
+	name := params.ByName("name")
+
+	if err := validateName(name); err != nil {
+		h.log(r).Error("Validation failed", zap.Error(err))
+		return errs.ErrBadRequest
+	}
+
+	translations, err := h.usecase.GetSongTranslations(r.Context(), name)
+	if err != nil {
+		h.log(r).Error("Failed get song translations", zap.String("song_name", name), zap.Error(err))
+		if errors.Is(err, errs.ErrNotFound) {
+			return errs.ErrNotFound
+		}
+		return errs.ErrInternal
+	}
+
+	content := entity.Content{
+		CurrentPage: 1,
+		TotalPage:   1,
+		TotalItems:  len(translations),
+		Items:       translations,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(Wrap(content))
+	h.log(r).Info("Song translations find successfully", zap.String("song_name", name))
+	return nil
+}
+
+// SetSongTranslation godoc
+//
+//	@Summary		Set song text translation.
+//	@Description	Text is a list of couplets aligned with the original by index. The original language can't be set here.
+//	@Tags			Translations
+//	@Accept			json
+//	@Produce		json
+//	@Param			name	path		string				true	"song name"
+//	@Param			lang	path		string				true	"BCP-47 language tag"
+//	@Param			request	body		entity.Translation	true	"json, only text is used"
+//	@Success		200		{object}	Response			"Success"
+//	@Failure		400		{object}	Response			"Bad Request"
+//	@Failure		401		{object}	Response			"Unauthorized"
+//	@Failure		404		{object}	Response			"Not Found"
+//	@Failure		500		{object}	Response			"Internal Server Error"
+//	@Router			/songs/{name}/translations/{lang} [put]
+func (h *Handler) SetSongTranslation(w http.ResponseWriter, r *http.Request) *errs.AppError {
+	params := httprouter.ParamsFromContext(r.Context())
+	name, lang := params.ByName("name"), params.ByName("lang")
+
+	if err := validateName(name); err != nil {
+		h.log(r).Error("Validation failed", zap.Error(err))
+		return errs.ErrBadRequest
+	}
+
+	var translation entity.Translation
+	if err := json.NewDecoder(r.Body).Decode(&translation); err != nil {
+		h.log(r).Error("Invalid request payload", zap.Error(err))
+		return errs.ErrBadRequest
+	}
+	defer r.Body.Close()
+
+	isUpdated, err := h.usecase.SetSongTranslation(r.Context(), name, lang, translation.Text)
+	if err != nil {
+		h.log(r).Error("Failed set song translation", zap.String("song_name", name), zap.String("lang", lang), zap.Error(err))
+		if errors.Is(err, errs.ErrBadRequest) {
+			return errs.ErrBadRequest
+		}
+		return errs.ErrInternal
+	}
+
+	if !isUpdated {
+		h.log(r).Error("Song not found", zap.String("song_name", name))
+		return errs.ErrNotFound
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(Wrap(nil))
+	h.log(r).Info("Song translation updated successfully", zap.String("song_name", name), zap.String("lang", lang))
+	return nil
+}
+
+// DeleteSongTranslation godoc
+//
+//	@Summary	Delete song text translation.
+//	@Tags		Translations
+//	@Accept		json
+//	@Produce	json
+//	@Param		name	path		string		true	"song name"
+//	@Param		lang	path		string		true	"BCP-47 language tag"
+//	@Success	200		{object}	Response	"Success"
+//	@Failure	400		{object}	Response	"Bad Request"
+//	@Failure	401		{object}	Response	"Unauthorized"
+//	@Failure	404		{object}	Response	"Not Found"
+//	@Failure	500		{object}	Response	"Internal Server Error"
+//	@Router		/songs/{name}/translations/{lang} [delete]
+func (h *Handler) DeleteSongTranslation(w http.ResponseWriter, r *http.Request) *errs.AppError {
+	params := httprouter.ParamsFromContext(r.Context())
+	name, lang := params.ByName("name"), params.ByName("lang")
+
+	if err := validateName(name); err != nil {
+		h.log(r).Error("Validation failed", zap.Error(err))
+		return errs.ErrBadRequest
+	}
+
+	isDeleted, err := h.usecase.DeleteSongTranslation(r.Context(), name, lang)
+	if err != nil {
+		h.log(r).Error("Failed delete song translation", zap.String("song_name", name), zap.String("lang", lang), zap.Error(err))
+		if errors.Is(err, errs.ErrBadRequest) {
+			return errs.ErrBadRequest
+		}
+		return errs.ErrInternal
+	}
+
+	if !isDeleted {
+		h.log(r).Error("Song translation not found", zap.String("song_name", name), zap.String("lang", lang))
+		return errs.ErrNotFound
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(Wrap(nil))
+	h.log(r).Info("Song translation deleted successfully", zap.String("song_name", name), zap.String("lang", lang))
+	return nil
+}
@@ -0,0 +1,26 @@
+package http_v1_route
+
+import (
+	"context"
+	"net/http"
+
+	"go-rest-api/internal/composite"
+	"go-rest-api/internal/transport/http/middleware"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+const (
+	getDuplicates = "/api/v1/admin/duplicates"
+
+	mergeSongs = "/api/v1/admin/songs/merge"
+
+	mergeGroups = "/api/v1/admin/groups/merge"
+)
+
+func AdminRouteRegister(ctx context.Context, r *httprouter.Router, c *composite.Composite) {
+	handle(r, http.MethodGet, getDuplicates, middleware.Wrap(ctx, c.Handler.GetDuplicates))
+
+	handle(r, http.MethodPost, mergeSongs, middleware.Wrap(ctx, c.Handler.MergeSongs))
+	handle(r, http.MethodPost, mergeGroups, middleware.Wrap(ctx, c.Handler.MergeGroups))
+}
@@ -0,0 +1,36 @@
+package http_v1_route
+
+import (
+	"context"
+	"net/http"
+
+	"go-rest-api/internal/composite"
+	"go-rest-api/internal/transport/http/middleware"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+const (
+	getAlbums = "/api/v1/albums"
+	addAlbum
+
+	getAlbum = "/api/v1/albums/:id"
+	updateAlbum
+	deleteAlbum
+
+	getAlbumTracks = "/api/v1/albums/:id/tracks"
+	setAlbumTracks
+)
+
+func AlbumRouteRegister(ctx context.Context, r *httprouter.Router, c *composite.Composite) {
+	handle(r, http.MethodGet, getAlbums, middleware.Wrap(ctx, c.Handler.GetAlbums))
+	handle(r, http.MethodGet, getAlbum, middleware.Wrap(ctx, c.Handler.GetAlbum))
+	handle(r, http.MethodGet, getAlbumTracks, middleware.Wrap(ctx, c.Handler.GetAlbumTracks))
+
+	handle(r, http.MethodPost, addAlbum, middleware.Wrap(ctx, c.Handler.AddAlbum))
+
+	handle(r, http.MethodPut, updateAlbum, middleware.Wrap(ctx, c.Handler.UpdateAlbum))
+	handle(r, http.MethodPut, setAlbumTracks, middleware.Wrap(ctx, c.Handler.SetAlbumTracks))
+
+	handle(r, http.MethodDelete, deleteAlbum, middleware.Wrap(ctx, c.Handler.DeleteAlbum))
+}
@@ -0,0 +1,26 @@
+package http_v1_route
+
+import (
+	"net/http"
+	"strings"
+
+	"go-rest-api/internal/transport/http/middleware"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+// handle регистрирует обработчик маршрута через instrument.
+func handle(r *httprouter.Router, method, path string, h http.HandlerFunc) {
+	r.Handler(method, path, instrument(path, h))
+}
+
+// handleAction -- то же для маршрутов-действий; pattern вида "POST /api/v1/songs:import".
+func handleAction(mux *http.ServeMux, pattern string, h http.HandlerFunc) {
+	_, path, _ := strings.Cut(pattern, " ")
+	mux.Handle(pattern, instrument(path, h))
+}
+
+// instrument оборачивает обработчик трассировкой и метриками по шаблону пути.
+func instrument(path string, h http.HandlerFunc) http.HandlerFunc {
+	return middleware.Tracing(path, middleware.Metrics(path, h))
+}
@@ -0,0 +1,21 @@
+package http_v1_route
+
+import (
+	"net/http"
+
+	"go-rest-api/internal/health"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+const (
+	healthz = "/healthz"
+	readyz  = "/readyz"
+)
+
+// HealthRouteRegister регистрирует пробы без авторизации, метрик и трассировки:
+// их дёргает оркестратор, и они только зашумили бы статистику.
+func HealthRouteRegister(r *httprouter.Router, c *health.Checker) {
+	r.HandlerFunc(http.MethodGet, healthz, c.Live)
+	r.HandlerFunc(http.MethodGet, readyz, c.Ready)
+}
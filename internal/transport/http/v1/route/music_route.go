@@ -17,15 +17,89 @@ const (
 	deleteSong = "/api/v1/songs/:name"
 	updateSong
 	getSong
+
+	// Обслуживается обработчиком getSong, см. songSubroutes.
+	getSongFacets   = "facets"
+	getPopularSongs = "popular"
+
+	getSongLyrics = "/api/v1/songs/:name/lyrics"
+
+	getSongLRC = "/api/v1/songs/:name/lyrics.lrc"
+	putSongLRC
+
+	getSongTimedLyrics = "/api/v1/songs/:name/lyrics.json"
+
+	getSongTranslations = "/api/v1/songs/:name/translations"
+
+	recordSongPlay = "/api/v1/songs/:name/plays"
+
+	getSimilarSongs = "/api/v1/songs/:name/similar"
+
+	setSongTranslation = "/api/v1/songs/:name/translations/:lang"
+	deleteSongTranslation
+
+	searchSongs = "/api/v1/search"
+
+	getGenres = "/api/v1/genres"
+
+	getStats = "/api/v1/stats"
+)
+
+// Маршруты-действия в стиле /songs:action (pattern для http.ServeMux).
+const (
+	importSongs = "POST /api/v1/songs:import"
+	exportSongs = "GET /api/v1/songs:export"
 )
 
 func MusicRouteRegister(ctx context.Context, r *httprouter.Router, c *composite.Composite) {
-	r.HandlerFunc(http.MethodGet, getSongs, middleware.Wrap(ctx, c.Handler.GetFilteredSongs))
-	r.HandlerFunc(http.MethodGet, getSong, middleware.Wrap(ctx, c.Handler.GetSongText))
+	handle(r, http.MethodGet, getSongs, middleware.Wrap(ctx, c.Handler.GetFilteredSongs))
+	// Трассировка и метрики -- после разбора songSubroutes, чтобы статические пути были отдельными маршрутами.
+	r.HandlerFunc(http.MethodGet, getSong, songSubroutes(
+		instrument(getSong, middleware.Wrap(ctx, c.Handler.GetSongText)),
+		map[string]http.HandlerFunc{
+			getSongFacets:   instrument(getSongs+"/"+getSongFacets, middleware.Wrap(ctx, c.Handler.GetSongFacets)),
+			getPopularSongs: instrument(getSongs+"/"+getPopularSongs, middleware.Wrap(ctx, c.Handler.GetPopularSongs)),
+		},
+	))
+	handle(r, http.MethodGet, getSongLyrics, middleware.Wrap(ctx, c.Handler.GetSongLyrics))
+	handle(r, http.MethodGet, getSongLRC, middleware.Wrap(ctx, c.Handler.GetSongLRC))
+	handle(r, http.MethodGet, getSongTimedLyrics, middleware.Wrap(ctx, c.Handler.GetSongTimedLyrics))
+	handle(r, http.MethodGet, getSongTranslations, middleware.Wrap(ctx, c.Handler.GetSongTranslations))
+	handle(r, http.MethodGet, getSimilarSongs, middleware.Wrap(ctx, c.Handler.GetSimilarSongs))
+	handle(r, http.MethodGet, searchSongs, middleware.Wrap(ctx, c.Handler.SearchSongs))
+	handle(r, http.MethodGet, getGenres, middleware.Wrap(ctx, c.Handler.GetGenres))
+	handle(r, http.MethodGet, getStats, middleware.Wrap(ctx, c.Handler.GetSongStats))
 
-	r.HandlerFunc(http.MethodPost, addSong, middleware.Wrap(ctx, c.Handler.AddSong))
+	handle(r, http.MethodPost, addSong, middleware.Wrap(ctx, c.Handler.AddSong))
+	handle(r, http.MethodPost, recordSongPlay, middleware.Wrap(ctx, c.Handler.RecordSongPlay))
 
-	r.HandlerFunc(http.MethodPut, updateSong, middleware.Wrap(ctx, c.Handler.UpdateSong))
+	handle(r, http.MethodPut, updateSong, middleware.Wrap(ctx, c.Handler.UpdateSong))
+	handle(r, http.MethodPut, putSongLRC, middleware.Wrap(ctx, c.Handler.PutSongLRC))
+	handle(r, http.MethodPut, setSongTranslation, middleware.Wrap(ctx, c.Handler.SetSongTranslation))
+
+	handle(r, http.MethodDelete, deleteSong, middleware.Wrap(ctx, c.Handler.DeleteSong))
+	handle(r, http.MethodDelete, deleteSongTranslation, middleware.Wrap(ctx, c.Handler.DeleteSongTranslation))
+}
+
+// ActionRouteRegister регистрирует маршруты вида /songs:action.
+// httprouter считает ':' началом параметра и не даёт объявить такой путь рядом
+// с /songs/:name, поэтому они обслуживаются отдельным mux, подключённым как router.NotFound.
+func ActionRouteRegister(ctx context.Context, mux *http.ServeMux, c *composite.Composite) {
+	handleAction(mux, importSongs, middleware.WrapStream(ctx, c.Handler.ImportSongs))
+	handleAction(mux, exportSongs, middleware.Wrap(ctx, c.Handler.ExportSongs))
+}
 
-	r.HandlerFunc(http.MethodDelete, deleteSong, middleware.Wrap(ctx, c.Handler.DeleteSong))
+// songSubroutes отдаёт запросы к статическим путям вида /api/v1/songs/facets их обработчикам
+// раньше, чем /songs/:name. httprouter не допускает статический сегмент рядом с параметром,
+// поэтому такие пути разбираются по значению :name; эти названия песен зарезервированы
+// (см. reservedSongNames в http_v1_handler).
+func songSubroutes(song http.HandlerFunc, static map[string]http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		name := httprouter.ParamsFromContext(r.Context()).ByName("name")
+		if h, ok := static[name]; ok {
+			h(w, r)
+			return
+		}
+		song(w, r)
+	}
 }
@@ -0,0 +1,44 @@
+package http_v1_route
+
+import (
+	"context"
+	"net/http"
+
+	"go-rest-api/internal/composite"
+	"go-rest-api/internal/transport/http/middleware"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+const (
+	getPlaylists = "/api/v1/playlists"
+	addPlaylist
+
+	getPlaylist = "/api/v1/playlists/:id"
+	updatePlaylist
+	deletePlaylist
+
+	getPlaylistEntries = "/api/v1/playlists/:id/entries"
+	addPlaylistEntry
+	setPlaylistOrder
+
+	movePlaylistEntry = "/api/v1/playlists/:id/entries/:song_id"
+	deletePlaylistEntry
+)
+
+func PlaylistRouteRegister(ctx context.Context, r *httprouter.Router, c *composite.Composite) {
+	handle(r, http.MethodGet, getPlaylists, middleware.Wrap(ctx, c.Handler.GetPlaylists))
+	handle(r, http.MethodGet, getPlaylist, middleware.Wrap(ctx, c.Handler.GetPlaylist))
+	handle(r, http.MethodGet, getPlaylistEntries, middleware.Wrap(ctx, c.Handler.GetPlaylistEntries))
+
+	handle(r, http.MethodPost, addPlaylist, middleware.Wrap(ctx, c.Handler.AddPlaylist))
+	handle(r, http.MethodPost, addPlaylistEntry, middleware.Wrap(ctx, c.Handler.AddPlaylistEntry))
+
+	handle(r, http.MethodPut, updatePlaylist, middleware.Wrap(ctx, c.Handler.UpdatePlaylist))
+	handle(r, http.MethodPut, setPlaylistOrder, middleware.Wrap(ctx, c.Handler.SetPlaylistOrder))
+
+	handle(r, http.MethodPatch, movePlaylistEntry, middleware.Wrap(ctx, c.Handler.MovePlaylistEntry))
+
+	handle(r, http.MethodDelete, deletePlaylist, middleware.Wrap(ctx, c.Handler.DeletePlaylist))
+	handle(r, http.MethodDelete, deletePlaylistEntry, middleware.Wrap(ctx, c.Handler.DeletePlaylistEntry))
+}
@@ -9,5 +9,5 @@ import (
 )
 
 func SwaggerRouteRegister(ctx context.Context, r *httprouter.Router) {
-	r.HandlerFunc(http.MethodGet, "/swagger/*any", httpSwagger.WrapHandler)
+	handle(r, http.MethodGet, "/swagger/*any", httpSwagger.WrapHandler)
 }
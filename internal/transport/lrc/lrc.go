@@ -0,0 +1,132 @@
+package lrc
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go-rest-api/internal/entity"
+)
+
+const ContentType = "application/lrc"
+
+var (
+	tagRe  = regexp.MustCompile(`^\[([^\]]*)\]`)
+	timeRe = regexp.MustCompile(`^(\d+):(\d{1,2})(?:[.:](\d{1,3}))?$`)
+)
+
+// Parse разбирает LRC: строка может иметь несколько меток времени, [offset:ms]
+// сдвигает все метки (положительный -- текст появляется раньше). Строки
+// возвращаются отсортированными по времени.
+func Parse(r io.Reader) ([]entity.TimedLine, error) {
+	var lines []entity.TimedLine
+	offset := 0
+
+	scanner := bufio.NewScanner(r)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(strings.TrimPrefix(scanner.Text(), "\ufeff"))
+		if line == "" {
+			continue
+		}
+
+		var times []int
+		for {
+			m := tagRe.FindStringSubmatch(line)
+			if m == nil {
+				break
+			}
+			line = line[len(m[0]):]
+
+			if t, ok := parseTime(m[1]); ok {
+				times = append(times, t)
+				continue
+			}
+
+			key, value, ok := strings.Cut(m[1], ":")
+			if !ok {
+				return nil, fmt.Errorf("line %d: malformed tag [%s]", lineNum, m[1])
+			}
+			if strings.EqualFold(strings.TrimSpace(key), "offset") {
+				v, err := strconv.Atoi(strings.TrimSpace(value))
+				if err != nil {
+					return nil, fmt.Errorf("line %d: malformed offset: %w", lineNum, err)
+				}
+				offset = v
+			}
+		}
+
+		text := strings.TrimSpace(line)
+		if len(times) == 0 {
+			if text != "" {
+				return nil, fmt.Errorf("line %d: missing timestamp", lineNum)
+			}
+			continue
+		}
+
+		for _, t := range times {
+			lines = append(lines, entity.TimedLine{TimeMS: t, Text: text})
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range lines {
+		lines[i].TimeMS = max(lines[i].TimeMS-offset, 0)
+	}
+	sort.SliceStable(lines, func(i, j int) bool {
+		return lines[i].TimeMS < lines[j].TimeMS
+	})
+
+	return lines, nil
+}
+
+// Write выводит строки в формате LRC с заголовками [ti:] и [ar:].
+func Write(w io.Writer, title, artist string, lines []entity.TimedLine) error {
+	bw := bufio.NewWriter(w)
+
+	if title != "" {
+		fmt.Fprintf(bw, "[ti:%s]\n", title)
+	}
+	if artist != "" {
+		fmt.Fprintf(bw, "[ar:%s]\n", artist)
+	}
+
+	for _, line := range lines {
+		fmt.Fprintf(bw, "[%s]%s\n", formatTime(line.TimeMS), line.Text)
+	}
+
+	return bw.Flush()
+}
+
+func parseTime(s string) (int, bool) {
+	m := timeRe.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return 0, false
+	}
+
+	minutes, _ := strconv.Atoi(m[1])
+	seconds, _ := strconv.Atoi(m[2])
+	if seconds >= 60 {
+		return 0, false
+	}
+
+	ms := 0
+	if m[3] != "" {
+		// .x -- десятые, .xx -- сотые, .xxx -- миллисекунды
+		frac := m[3] + strings.Repeat("0", 3-len(m[3]))
+		ms, _ = strconv.Atoi(frac)
+	}
+
+	return (minutes*60+seconds)*1000 + ms, true
+}
+
+// formatTime выводит метку с миллисекундами (mm:ss.xxx): Parse читает её без потерь.
+func formatTime(ms int) string {
+	return fmt.Sprintf("%02d:%02d.%03d", ms/60000, ms/1000%60, ms%1000)
+}
@@ -0,0 +1,145 @@
+package lrc
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"go-rest-api/internal/entity"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []entity.TimedLine
+	}{
+		{
+			"fractions",
+			"[00:01.5]tenths\n[00:02.25]hundredths\n[00:03.125]millis\n[00:04:50]colon\n[01:05]no fraction\n",
+			[]entity.TimedLine{
+				{TimeMS: 1500, Text: "tenths"},
+				{TimeMS: 2250, Text: "hundredths"},
+				{TimeMS: 3125, Text: "millis"},
+				{TimeMS: 4500, Text: "colon"},
+				{TimeMS: 65000, Text: "no fraction"},
+			},
+		},
+		{
+			"multiple timestamps are sorted",
+			"[00:10.00][00:01.00]chorus\n[00:05.00]verse\n",
+			[]entity.TimedLine{
+				{TimeMS: 1000, Text: "chorus"},
+				{TimeMS: 5000, Text: "verse"},
+				{TimeMS: 10000, Text: "chorus"},
+			},
+		},
+		{
+			"headers and blank lines",
+			"[ti:Uprising]\n[ar:Muse]\n\n[00:01.00]line\n[00:02.00]\n",
+			[]entity.TimedLine{
+				{TimeMS: 1000, Text: "line"},
+				{TimeMS: 2000, Text: ""},
+			},
+		},
+		{
+			"positive offset shows text earlier",
+			"[offset:500]\n[00:01.00]a\n[00:02.00]b\n",
+			[]entity.TimedLine{
+				{TimeMS: 500, Text: "a"},
+				{TimeMS: 1500, Text: "b"},
+			},
+		},
+		{
+			"negative offset shows text later",
+			"[offset:-250]\n[00:01.00]a\n",
+			[]entity.TimedLine{{TimeMS: 1250, Text: "a"}},
+		},
+		{
+			"offset clamps at zero",
+			"[00:00.20]a\n[00:01.00]b\n[offset:500]\n",
+			[]entity.TimedLine{
+				{TimeMS: 0, Text: "a"},
+				{TimeMS: 500, Text: "b"},
+			},
+		},
+		{
+			"byte order mark",
+			"\ufeff[00:01.00]a\n",
+			[]entity.TimedLine{{TimeMS: 1000, Text: "a"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(strings.NewReader(tt.input))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"missing timestamp", "[00:01.00]a\nno time\n"},
+		{"malformed tag", "[garbage]a\n"},
+		{"malformed offset", "[offset:soon]\n"},
+		{"seconds out of range", "[00:60.00]a\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse(strings.NewReader(tt.input)); err == nil {
+				t.Error("malformed LRC accepted")
+			}
+		})
+	}
+}
+
+func TestWrite(t *testing.T) {
+	var buf bytes.Buffer
+	lines := []entity.TimedLine{
+		{TimeMS: 1005, Text: "a"},
+		{TimeMS: 61230, Text: "b"},
+		{TimeMS: 600000, Text: ""},
+	}
+
+	if err := Write(&buf, "Uprising", "Muse", lines); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "[ti:Uprising]\n[ar:Muse]\n[00:01.005]a\n[01:01.230]b\n[10:00.000]\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	input := "\ufeff[ti:Uprising]\n[offset:100]\n[00:00.050][00:30.00]chorus\n[00:12.345]verse\n"
+
+	first, err := Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, "Uprising", "", first); err != nil {
+		t.Fatal(err)
+	}
+
+	second, err := Parse(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(second, first) {
+		t.Errorf("round trip changed lines:\ngot  %+v\nwant %+v", second, first)
+	}
+}
@@ -0,0 +1,167 @@
+package usecase
+
+import (
+	"context"
+
+	"go-rest-api/internal/entity"
+	"go-rest-api/internal/errs"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// _defaultDuplicateThreshold -- сходство названий, начиная с которого записи считаются дублями.
+	_defaultDuplicateThreshold = 0.6
+	// _mergeLimit -- сколько записей можно слить за раз.
+	_mergeLimit = 100
+)
+
+type AdminRepo interface {
+	GetDuplicates(context.Context, string, float64, int, int) ([]entity.Duplicate, int, error)
+	MergeSongs(context.Context, int, []int) (bool, error)
+	MergeGroups(context.Context, int, []int) (bool, error)
+}
+
+/*
+По введённому виду записей (songs, groups), порогу сходства и page:
+- сравниваем нормализованные названия: без лишних пробелов и без учёта регистра
+- песни сравниваются только в пределах одной группы, группы -- все между собой
+- выдаётся максимум 10 пар за раз, самые похожие -- первыми
+
+Заметки:
+1. Порог -- от 0 (не включая) до 1; если не указан, то 0.6. Совпадающие после нормализации названия имеют сходство 1.
+2. Если дублей нет, то вернётся not found.
+*/
+func (uc *Usecase) GetDuplicates(ctx context.Context, kind string, threshold float64, page int) (entity.Content, error) {
+	ctx, span := tracer.Start(ctx, "usecase.GetDuplicates")
+	defer span.End()
+
+	if kind == "" {
+		kind = entity.DuplicateSongs
+	}
+	if kind != entity.DuplicateSongs && kind != entity.DuplicateGroups {
+		uc.log(ctx).Debug("Invalid duplicate kind", zap.String("kind", kind))
+		return entity.Content{}, errs.ErrBadRequest
+	}
+
+	if threshold == 0 {
+		threshold = _defaultDuplicateThreshold
+	}
+	if threshold < 0 || threshold > 1 {
+		uc.log(ctx).Debug("Invalid duplicate threshold", zap.Float64("threshold", threshold))
+		return entity.Content{}, errs.ErrBadRequest
+	}
+
+	const perPage = 10
+	page = max(page, 1)
+
+	duplicates, total, err := uc.repo.GetDuplicates(ctx, kind, threshold, perPage, (page-1)*perPage)
+	if err != nil {
+		uc.log(ctx).Debug("Find duplicates error", zap.Error(err))
+		return entity.Content{}, err
+	}
+
+	if total == 0 {
+		uc.log(ctx).Debug("Duplicates not found", zap.String("kind", kind))
+		return entity.Content{}, errs.ErrNotFound
+	}
+
+	totalPage := (total + perPage - 1) / perPage
+	if page > totalPage {
+		page = totalPage
+
+		duplicates, _, err = uc.repo.GetDuplicates(ctx, kind, threshold, perPage, (page-1)*perPage)
+		if err != nil {
+			uc.log(ctx).Debug("Find duplicates error", zap.Error(err))
+			return entity.Content{}, err
+		}
+	}
+
+	content := entity.Content{
+		CurrentPage: page,
+		TotalPage:   totalPage,
+		TotalItems:  total,
+		Items:       duplicates,
+	}
+
+	return content, nil
+}
+
+/*
+По введённым id остающейся песни и сливаемых песен:
+- переносим на остающуюся песню записи плейлистов и прослушивания
+- добавляем ей исполнителей (кроме основного), жанры, теги и недостающие переводы сливаемых песен
+- сливаемые песни удаляются; всё выполняется атомарно
+
+Заметки:
+1. Если какой-то из песен нет, то вернётся not found.
+*/
+func (uc *Usecase) MergeSongs(ctx context.Context, merge entity.Merge) error {
+	ctx, span := tracer.Start(ctx, "usecase.MergeSongs")
+	defer span.End()
+
+	if err := validateMerge(merge); err != nil {
+		uc.log(ctx).Debug("Invalid merge", zap.Error(err))
+		return err
+	}
+
+	merged, err := uc.repo.MergeSongs(ctx, merge.Keep, merge.Merge)
+	if err != nil {
+		uc.log(ctx).Debug("Merge songs error", zap.Error(err))
+		return err
+	}
+	if !merged {
+		uc.log(ctx).Debug("Songs not exist", zap.Int("keep", merge.Keep), zap.Ints("merge", merge.Merge))
+		return errs.ErrNotFound
+	}
+
+	return nil
+}
+
+/*
+По введённым id остающейся группы и сливаемых групп:
+- переносим на остающуюся группу песни, альбомы и участие в песнях как исполнителя
+- сливаемые группы удаляются; всё выполняется атомарно
+
+Заметки:
+1. Если какой-то из групп нет, то вернётся not found.
+2. После слияния у группы могут появиться дубли песен -- их видно в GetDuplicates.
+*/
+func (uc *Usecase) MergeGroups(ctx context.Context, merge entity.Merge) error {
+	ctx, span := tracer.Start(ctx, "usecase.MergeGroups")
+	defer span.End()
+
+	if err := validateMerge(merge); err != nil {
+		uc.log(ctx).Debug("Invalid merge", zap.Error(err))
+		return err
+	}
+
+	merged, err := uc.repo.MergeGroups(ctx, merge.Keep, merge.Merge)
+	if err != nil {
+		uc.log(ctx).Debug("Merge groups error", zap.Error(err))
+		return err
+	}
+	if !merged {
+		uc.log(ctx).Debug("Groups not exist", zap.Int("keep", merge.Keep), zap.Ints("merge", merge.Merge))
+		return errs.ErrNotFound
+	}
+
+	return nil
+}
+
+// validateMerge проверяет, что id положительные, не повторяются и остающаяся запись не сливается сама в себя.
+func validateMerge(merge entity.Merge) error {
+	if merge.Keep <= 0 || len(merge.Merge) == 0 || len(merge.Merge) > _mergeLimit {
+		return errs.ErrBadRequest
+	}
+
+	seen := map[int]bool{merge.Keep: true}
+	for _, id := range merge.Merge {
+		if id <= 0 || seen[id] {
+			return errs.ErrBadRequest
+		}
+		seen[id] = true
+	}
+
+	return nil
+}
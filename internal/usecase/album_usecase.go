@@ -0,0 +1,299 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go-rest-api/internal/entity"
+	"go-rest-api/internal/errs"
+
+	"go.uber.org/zap"
+)
+
+type AlbumRepo interface {
+	FindAlbumID(context.Context, int, string) (int, error)
+	CreateAlbum(context.Context, entity.AlbumDTO) (int, error)
+	GetAlbum(context.Context, int) (*entity.Album, error)
+	GetAlbums(context.Context, *int, int, int) ([]entity.Album, int, error)
+	UpdateAlbum(context.Context, int, entity.AlbumDTO) (bool, error)
+	DeleteAlbum(context.Context, int) (bool, error)
+	GetAlbumTracks(context.Context, int) ([]entity.Track, error)
+	SetAlbumTracks(context.Context, int, []entity.Track) error
+}
+
+/*
+По введённым данным альбома:
+- проверяем, что группа уже есть в хранилище; если нет, то она создаётся
+- записываем альбом в хранилище и возвращаем его id
+*/
+func (uc *Usecase) AddAlbum(ctx context.Context, album entity.Album) (int, error) {
+	ctx, span := tracer.Start(ctx, "usecase.AddAlbum")
+	defer span.End()
+
+	if album.Title == nil || strings.TrimSpace(*album.Title) == "" {
+		uc.log(ctx).Debug("Missing album title")
+		return 0, errs.ErrBadRequest
+	}
+	if album.Group == nil || strings.TrimSpace(*album.Group) == "" {
+		uc.log(ctx).Debug("Missing album group")
+		return 0, errs.ErrBadRequest
+	}
+
+	groupID, err := uc.createGroup(ctx, *album.Group)
+	if err != nil {
+		uc.log(ctx).Debug("Can't create group", zap.Error(err))
+		return 0, err
+	}
+
+	id, err := uc.repo.CreateAlbum(ctx, entity.AlbumDTO{
+		Title:       album.Title,
+		GroupID:     &groupID,
+		ReleaseDate: album.ReleaseDate,
+		CoverLink:   album.CoverLink,
+	})
+	if err != nil {
+		uc.log(ctx).Debug("Can't save new album", zap.Error(err))
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// GetAlbum возвращает альбом по id или ошибку (not found -- если альбома нет).
+func (uc *Usecase) GetAlbum(ctx context.Context, id int) (entity.Album, error) {
+	ctx, span := tracer.Start(ctx, "usecase.GetAlbum")
+	defer span.End()
+
+	album, err := uc.repo.GetAlbum(ctx, id)
+	if err != nil {
+		uc.log(ctx).Debug("Find album error", zap.Error(err))
+		return entity.Album{}, err
+	}
+
+	if album == nil {
+		uc.log(ctx).Debug("Album not exist", zap.Int("album_id", id))
+		return entity.Album{}, errs.ErrNotFound
+	}
+
+	return *album, nil
+}
+
+/*
+По введённой группе (опционально) и page:
+- выдаём альбомы по 10 за раз
+
+Заметки:
+1. Если будет введена группа, которой не существует, то вернётся not found.
+*/
+func (uc *Usecase) GetAlbums(ctx context.Context, group string, page int) (entity.Content, error) {
+	ctx, span := tracer.Start(ctx, "usecase.GetAlbums")
+	defer span.End()
+
+	var groupID *int
+	if group != "" {
+		id, err := uc.repo.FindGroupID(ctx, group)
+		if err != nil {
+			uc.log(ctx).Debug("Find group id error", zap.Error(err))
+			return entity.Content{}, err
+		}
+		if id == 0 {
+			uc.log(ctx).Debug("Group not exist", zap.String("group", group))
+			return entity.Content{}, errs.ErrNotFound
+		}
+
+		groupID = &id
+	}
+
+	const perPage = 10
+	page = max(page, 1)
+
+	albums, total, err := uc.repo.GetAlbums(ctx, groupID, perPage, (page-1)*perPage)
+	if err != nil {
+		uc.log(ctx).Debug("Find albums error", zap.Error(err))
+		return entity.Content{}, err
+	}
+
+	if total == 0 {
+		uc.log(ctx).Debug("Albums not exist")
+		return entity.Content{}, errs.ErrNotFound
+	}
+
+	totalPage := (total + perPage - 1) / perPage
+	if page > totalPage {
+		page = totalPage
+
+		albums, _, err = uc.repo.GetAlbums(ctx, groupID, perPage, (page-1)*perPage)
+		if err != nil {
+			uc.log(ctx).Debug("Find albums error", zap.Error(err))
+			return entity.Content{}, err
+		}
+	}
+
+	content := entity.Content{
+		CurrentPage: page,
+		TotalPage:   totalPage,
+		TotalItems:  total,
+		Items:       albums,
+	}
+
+	return content, nil
+}
+
+/*
+По введённому album id и данным альбома:
+- если указана группа, то проверяем, что она есть в хранилище; если нет, то она создаётся
+- обновляем данные альбома в хранилище
+*/
+func (uc *Usecase) UpdateAlbum(ctx context.Context, id int, album entity.Album) (bool, error) {
+	ctx, span := tracer.Start(ctx, "usecase.UpdateAlbum")
+	defer span.End()
+
+	albumDTO := entity.AlbumDTO{
+		Title:       album.Title,
+		ReleaseDate: album.ReleaseDate,
+		CoverLink:   album.CoverLink,
+	}
+
+	if album.Group != nil {
+		groupID, err := uc.createGroup(ctx, *album.Group)
+		if err != nil {
+			uc.log(ctx).Debug("Can't create group", zap.Error(err))
+			return false, err
+		}
+		albumDTO.GroupID = &groupID
+	}
+
+	isUpdated, err := uc.repo.UpdateAlbum(ctx, id, albumDTO)
+	if err != nil {
+		uc.log(ctx).Debug("Update album error", zap.Error(err))
+		return false, err
+	}
+
+	return isUpdated, nil
+}
+
+// DeleteAlbum "удаляет" альбом; песни альбома остаются в хранилище.
+func (uc *Usecase) DeleteAlbum(ctx context.Context, id int) (bool, error) {
+	ctx, span := tracer.Start(ctx, "usecase.DeleteAlbum")
+	defer span.End()
+
+	isDeleted, err := uc.repo.DeleteAlbum(ctx, id)
+	if err != nil {
+		uc.log(ctx).Debug("Delete album error", zap.Error(err))
+		return false, err
+	}
+
+	return isDeleted, nil
+}
+
+// GetAlbumTracks возвращает треки альбома по порядку или ошибку (not found -- если альбома нет).
+func (uc *Usecase) GetAlbumTracks(ctx context.Context, id int) ([]entity.Track, error) {
+	ctx, span := tracer.Start(ctx, "usecase.GetAlbumTracks")
+	defer span.End()
+
+	if _, err := uc.GetAlbum(ctx, id); err != nil {
+		return nil, err
+	}
+
+	tracks, err := uc.repo.GetAlbumTracks(ctx, id)
+	if err != nil {
+		uc.log(ctx).Debug("Find album tracks error", zap.Error(err))
+		return nil, err
+	}
+
+	return tracks, nil
+}
+
+/*
+По введённому album id и списку треков:
+- проверяем, что альбом существует
+- проверяем, что песни и пары (диск, номер трека) не повторяются
+- заменяем состав и порядок треков альбома
+
+Заметки:
+1. Если диск не указан, то считается, что это диск 1.
+*/
+func (uc *Usecase) SetAlbumTracks(ctx context.Context, id int, tracks []entity.Track) error {
+	ctx, span := tracer.Start(ctx, "usecase.SetAlbumTracks")
+	defer span.End()
+
+	if _, err := uc.GetAlbum(ctx, id); err != nil {
+		return err
+	}
+
+	if err := normalizeTracks(tracks); err != nil {
+		uc.log(ctx).Debug("Invalid album tracks", zap.Error(err))
+		return errs.ErrBadRequest
+	}
+
+	if err := uc.repo.SetAlbumTracks(ctx, id, tracks); err != nil {
+		uc.log(ctx).Debug("Set album tracks error", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// withAlbum находит или создаёт альбом группы песни по данным внешнего сервиса
+// и проставляет песне альбом и позицию трека. Если альбом не указан, песня не меняется.
+func (uc *Usecase) withAlbum(ctx context.Context, song *entity.SongDTO, detail entity.SongDetail) error {
+	title := strings.TrimSpace(detail.Album)
+	if title == "" {
+		return nil
+	}
+
+	albumID, err := uc.repo.FindAlbumID(ctx, *song.GroupID, title)
+	if err != nil {
+		uc.log(ctx).Debug("Find album id error", zap.Error(err))
+		return err
+	}
+
+	if albumID == 0 {
+		albumID, err = uc.repo.CreateAlbum(ctx, entity.AlbumDTO{
+			Title:   &title,
+			GroupID: song.GroupID,
+		})
+		if err != nil {
+			uc.log(ctx).Debug("Create album error", zap.Error(err))
+			return err
+		}
+	}
+
+	song.AlbumID = &albumID
+	if detail.TrackNumber > 0 {
+		disc := max(detail.DiscNumber, 1)
+		song.DiscNumber = &disc
+		song.TrackNumber = &detail.TrackNumber
+	}
+
+	return nil
+}
+
+func normalizeTracks(tracks []entity.Track) error {
+	songs := make(map[int]bool, len(tracks))
+	positions := make(map[[2]int]bool, len(tracks))
+
+	for i := range tracks {
+		track := &tracks[i]
+		if track.DiscNumber == 0 {
+			track.DiscNumber = 1
+		}
+
+		if track.SongID <= 0 {
+			return fmt.Errorf("track %d: invalid song id", i+1)
+		}
+		if track.DiscNumber < 0 || track.TrackNumber <= 0 {
+			return fmt.Errorf("track %d: invalid disc or track number", i+1)
+		}
+
+		position := [2]int{track.DiscNumber, track.TrackNumber}
+		if songs[track.SongID] || positions[position] {
+			return fmt.Errorf("track %d: duplicate song or position", i+1)
+		}
+		songs[track.SongID] = true
+		positions[position] = true
+	}
+
+	return nil
+}
@@ -2,27 +2,64 @@ package usecase
 
 import (
 	"context"
+	"fmt"
+	"iter"
+	"net"
+	"net/url"
+	"strings"
+	"time"
 
+	"go-rest-api/config"
 	"go-rest-api/internal/entity"
 	"go-rest-api/internal/errs"
 	"go-rest-api/pkg/logger"
+	"go-rest-api/pkg/netguard"
 
+	"go.opentelemetry.io/otel"
 	"go.uber.org/zap"
 )
 
+const (
+	// _defaultLyricsWindow -- окно страницы синхронизированного текста, мс.
+	_defaultLyricsWindow = 30000
+	// _defaultRecentSongs -- сколько последних добавленных песен попадает в статистику.
+	_defaultRecentSongs = 10
+	// _releaseDateLayout -- формат даты выхода песни (DD.MM.YYYY).
+	_releaseDateLayout = "02.01.2006"
+)
+
+// _defaultSimilarWeights -- веса оценки похожих песен, если они не заданы в конфиге.
+var _defaultSimilarWeights = entity.SimilarWeights{Group: 0.3, Release: 0.2, Lyrics: 0.5}
+
+var tracer = otel.Tracer("go-rest-api/internal/usecase")
+
 type (
 	Repo interface {
-		FindGroupID(string) (int, error)
-		CreateGroup(string) (int, error)
-		CreateSong(entity.SongDTO) error
-		DeleteSong(string) (bool, error)
-		UpdateSong(string, entity.SongDTO) (bool, error)
-		GetSongText(string) ([]string, error)
-		GetFilteredSongs(entity.FilterSongDTO) ([]entity.Song, error)
+		AlbumRepo
+		PlaylistRepo
+		TranslationRepo
+		PlayRepo
+		AdminRepo
+
+		FindGroupID(context.Context, string) (int, error)
+		CreateGroup(context.Context, string) (int, error)
+		CreateSong(context.Context, entity.SongDTO) error
+		DeleteSong(context.Context, string) (bool, error)
+		UpdateSong(context.Context, string, entity.SongDTO) (bool, error)
+		GetSongText(context.Context, string) ([]string, error)
+		GetSongSections(context.Context, string) ([]entity.Section, error)
+		GetSongTimedLines(context.Context, string) ([]entity.TimedLine, error)
+		GetFilteredSongs(context.Context, entity.FilterSongDTO) ([]entity.Song, error)
+		StreamSongs(context.Context, entity.FilterSongDTO) iter.Seq2[entity.Song, error]
+		SearchSongs(context.Context, string, string, int, int) ([]entity.SearchResult, int, error)
+		GetSongFacets(context.Context, entity.FilterSongDTO) (entity.SongFacets, error)
+		GetSongStats(context.Context, entity.FilterSongDTO, int) (entity.SongStats, error)
+		GetSimilarSongs(context.Context, string, entity.SimilarWeights, int, int) ([]entity.SimilarSong, int, error)
+		GetGenres(context.Context) ([]string, error)
 	}
 
 	Webapi interface {
-		GetSongDetail(entity.NewSong) (entity.SongDetail, error)
+		GetSongDetail(context.Context, entity.NewSong) (entity.SongDetail, error)
 	}
 
 	Usecase struct {
@@ -30,48 +67,111 @@ type (
 		logger *logger.Logger
 		repo   Repo
 		webapi Webapi
+		plays  PlayRecorder
 	}
 )
 
-func New(ctx context.Context, repo Repo, webapi Webapi) *Usecase {
+func New(ctx context.Context, repo Repo, webapi Webapi, plays PlayRecorder) *Usecase {
 	return &Usecase{
 		ctx:    ctx,
 		logger: logger.FromContext(ctx),
 		repo:   repo,
 		webapi: webapi,
+		plays:  plays,
 	}
 }
 
+// log -- логгер текущего запроса (request_id, trace_id).
+func (uc *Usecase) log(ctx context.Context) *logger.Logger {
+	return logger.Request(ctx, uc.logger)
+}
+
 /*
 По введённым song_name и song_group:
 - проверяем, что такая группа уже есть в хранилище
 - если группы нет в хранилище, то она создаётся
+- если группа не указана, основной считается первый исполнитель с ролью primary
+- остальные исполнители (feat., авторы) создаются так же, как группы
+- жанры и теги приводятся к нижнему регистру; жанр должен быть из словаря
+- язык оригинала (если указан) должен быть тегом BCP-47
 - потом получаем данные о песне из внешнего сервиса
 - записываем обогащённые данные о песне в хранилище
 */
-func (uc *Usecase) AddSong(newSong entity.NewSong) error {
-	groupID, err := uc.createGroup(newSong.Group)
+func (uc *Usecase) AddSong(ctx context.Context, newSong entity.NewSong) error {
+	ctx, span := tracer.Start(ctx, "usecase.AddSong")
+	defer span.End()
+
+	if newSong.Group == "" {
+		newSong.Group = primaryArtist(newSong.Artists)
+	}
+
+	groupID, err := uc.createGroup(ctx, newSong.Group)
 	if err != nil {
-		uc.logger.Debug("Can't create group", zap.Error(err))
+		uc.log(ctx).Debug("Can't create group", zap.Error(err))
 		return err
 	}
 
-	songDetail, err := uc.webapi.GetSongDetail(newSong)
+	artists, err := uc.resolveArtists(ctx, newSong.Artists)
 	if err != nil {
-		uc.logger.Debug("Can't receive song detail", zap.Error(err))
+		uc.log(ctx).Debug("Can't resolve artists", zap.Error(err))
 		return err
 	}
 
+	genres, err := normalizeLabels(newSong.Genres)
+	if err != nil {
+		uc.log(ctx).Debug("Invalid genres", zap.Error(err))
+		return errs.ErrBadRequest
+	}
+
+	tags, err := normalizeLabels(newSong.Tags)
+	if err != nil {
+		uc.log(ctx).Debug("Invalid tags", zap.Error(err))
+		return errs.ErrBadRequest
+	}
+
+	var lang *string
+	if newSong.Lang != "" {
+		tag, err := parseLang(newSong.Lang)
+		if err != nil {
+			uc.log(ctx).Debug("Invalid language tag", zap.Error(err))
+			return errs.ErrBadRequest
+		}
+		lang = &tag
+	}
+
+	songDetail, err := uc.webapi.GetSongDetail(ctx, newSong)
+	if err != nil {
+		uc.log(ctx).Debug("Can't receive song detail", zap.Error(err))
+		return err
+	}
+
+	// Ссылку присылает внешний сервис: некорректная ссылка -- это его ошибка, а не клиента.
+	if songDetail.Link, err = normalizeLink(songDetail.Link); err != nil {
+		uc.log(ctx).Debug("External API return invalid link", zap.Error(err))
+		return err
+	}
+
+	sections := versesFromText(songDetail.Text)
 	songDTO := entity.SongDTO{
 		Name:        &newSong.Name,
 		GroupID:     &groupID,
 		ReleaseDate: &songDetail.ReleaseDate,
 		Text:        &songDetail.Text,
 		Link:        &songDetail.Link,
+		Sections:    &sections,
+		Artists:     &artists,
+		Genres:      &genres,
+		Tags:        &tags,
+		Lang:        lang,
 	}
 
-	if err = uc.repo.CreateSong(songDTO); err != nil {
-		uc.logger.Debug("Can't save new song", zap.Error(err))
+	if err = uc.withAlbum(ctx, &songDTO, songDetail); err != nil {
+		uc.log(ctx).Debug("Can't create album", zap.Error(err))
+		return err
+	}
+
+	if err = uc.repo.CreateSong(ctx, songDTO); err != nil {
+		uc.log(ctx).Debug("Can't save new song", zap.Error(err))
 		return err
 	}
 
@@ -88,10 +188,13 @@ func (uc *Usecase) AddSong(newSong entity.NewSong) error {
 прежде всего должен его знать.
 3. Фактически, запись остаётся, но помечается отметкой об удалении.
 */
-func (uc *Usecase) DeleteSong(name string) (bool, error) {
-	isDeleted, err := uc.repo.DeleteSong(name)
+func (uc *Usecase) DeleteSong(ctx context.Context, name string) (bool, error) {
+	ctx, span := tracer.Start(ctx, "usecase.DeleteSong")
+	defer span.End()
+
+	isDeleted, err := uc.repo.DeleteSong(ctx, name)
 	if err != nil {
-		uc.logger.Debug("Delete song error", zap.Error(err))
+		uc.log(ctx).Debug("Delete song error", zap.Error(err))
 		return false, err
 	}
 
@@ -102,29 +205,108 @@ func (uc *Usecase) DeleteSong(name string) (bool, error) {
 По введённому song name и new song detail:
 - проверяем, что группа уже есть в хранилище
 - если группы нет в хранилище, то она создаётся
+- если группа не указана, основной считается первый исполнитель с ролью primary
+- если переданы исполнители, то они заменяют текущих (основная группа остаётся primary)
+- если переданы жанры или теги, то они заменяют текущие
+- если передан язык оригинала, то он заменяет текущий (пустая строка -- язык не указан)
+- если переданы секции, то текст песни пересобирается из них;
+если передан только текст, то секции пересобираются из него (все -- verse)
 - обновляем данные о песне в хранилище
 
 Заметки:
 1. Поиск существующей песни происходит на стороне хранилища.
 */
-func (uc *Usecase) UpdateSong(name string, updateSong entity.Song) (bool, error) {
-	groupID, err := uc.createGroup(*updateSong.Group)
-	if err != nil {
-		uc.logger.Debug("Can't create group", zap.Error(err))
-		return false, err
-	}
+func (uc *Usecase) UpdateSong(ctx context.Context, name string, updateSong entity.Song) (bool, error) {
+	ctx, span := tracer.Start(ctx, "usecase.UpdateSong")
+	defer span.End()
 
 	song := entity.SongDTO{
 		Name:        updateSong.Name,
-		GroupID:     &groupID,
 		ReleaseDate: updateSong.ReleaseDate,
 		Text:        updateSong.Text,
-		Link:        updateSong.Link,
 	}
 
-	isUpdated, err := uc.repo.UpdateSong(name, song)
+	if updateSong.Link != nil {
+		link, err := normalizeLink(*updateSong.Link)
+		if err != nil {
+			uc.log(ctx).Debug("Invalid link", zap.Error(err))
+			return false, errs.ErrBadRequest
+		}
+		song.Link = &link
+	}
+
+	group := updateSong.Group
+	if group == nil && updateSong.Artists != nil {
+		if primary := primaryArtist(*updateSong.Artists); primary != "" {
+			group = &primary
+		}
+	}
+
+	if group != nil {
+		groupID, err := uc.createGroup(ctx, *group)
+		if err != nil {
+			uc.log(ctx).Debug("Can't create group", zap.Error(err))
+			return false, err
+		}
+		song.GroupID = &groupID
+	}
+
+	if updateSong.Artists != nil {
+		artists, err := uc.resolveArtists(ctx, *updateSong.Artists)
+		if err != nil {
+			uc.log(ctx).Debug("Can't resolve artists", zap.Error(err))
+			return false, err
+		}
+		song.Artists = &artists
+	}
+
+	if updateSong.Genres != nil {
+		genres, err := normalizeLabels(*updateSong.Genres)
+		if err != nil {
+			uc.log(ctx).Debug("Invalid genres", zap.Error(err))
+			return false, errs.ErrBadRequest
+		}
+		song.Genres = &genres
+	}
+
+	if updateSong.Tags != nil {
+		tags, err := normalizeLabels(*updateSong.Tags)
+		if err != nil {
+			uc.log(ctx).Debug("Invalid tags", zap.Error(err))
+			return false, errs.ErrBadRequest
+		}
+		song.Tags = &tags
+	}
+
+	if updateSong.Lang != nil && *updateSong.Lang != "" {
+		lang, err := parseLang(*updateSong.Lang)
+		if err != nil {
+			uc.log(ctx).Debug("Invalid language tag", zap.Error(err))
+			return false, errs.ErrBadRequest
+		}
+		song.Lang = &lang
+	} else {
+		song.Lang = updateSong.Lang
+	}
+
+	switch {
+	case updateSong.Sections != nil:
+		if err := validateSections(*updateSong.Sections); err != nil {
+			uc.log(ctx).Debug("Invalid sections", zap.Error(err))
+			return false, errs.ErrBadRequest
+		}
+		text := textFromSections(*updateSong.Sections)
+		song.Text = &text
+		song.Sections = updateSong.Sections
+
+	case updateSong.Text != nil:
+		sections := versesFromText(*updateSong.Text)
+		song.Sections = &sections
+	}
+
+	isUpdated, err := uc.repo.UpdateSong(ctx, name, song)
 	if err != nil {
-		uc.logger.Debug("Update song error", zap.Error(err))
+		uc.log(ctx).Debug("Update song error", zap.Error(err))
 		return false, err
 	}
 
@@ -132,26 +314,26 @@ func (uc *Usecase) UpdateSong(name string, updateSong entity.Song) (bool, error)
 }
 
 /*
-По введённому song name и page:
-- получаем текст песни
-- разбиваем его на куплеты и представим, что выдаётся по 1 за раз.
+По введённому song name, языкам и page:
+- выбираем язык текста: первый из предпочтений (lang или Accept-Language), для которого
+есть перевод; если подходящего перевода нет, то выдаётся оригинал
+- разбиваем текст на куплеты и представим, что выдаётся по 1 за раз.
 Значит кол-во куплетов - это кол-во страниц, а page - указывает какую страницу
 необходимо выдать на запрос.
 
 Заметки:
 1. Поиск существующей песни происходит на стороне хранилища.
+2. Каждая выдача записывается как чтение текста (асинхронно, см. recordRead).
 */
-func (uc *Usecase) GetSongText(name string, page int) (entity.Content, error) {
-	text, err := uc.repo.GetSongText(name)
+func (uc *Usecase) GetSongText(ctx context.Context, name string, langs []string, page int) (entity.Content, error) {
+	ctx, span := tracer.Start(ctx, "usecase.GetSongText")
+	defer span.End()
+
+	version, err := uc.songVersion(ctx, name, langs)
 	if err != nil {
-		uc.logger.Debug("Find song text error", zap.Error(err))
 		return entity.Content{}, err
 	}
-
-	if text == nil {
-		uc.logger.Debug("Song not exist", zap.String("song_name", name))
-		return entity.Content{}, errs.ErrNotFound
-	}
+	text := version.Text
 
 	if page > len(text) {
 		page = len(text)
@@ -164,55 +346,167 @@ func (uc *Usecase) GetSongText(name string, page int) (entity.Content, error) {
 		TotalPage:   len(text),
 		TotalItems:  len(text),
 		Items: entity.Couplet{
-			Text: text[page-1],
+			Text:     text[page-1],
+			Lang:     version.Lang,
+			Original: version.Original,
 		},
 	}
 
+	uc.recordRead(ctx, name)
+
 	return content, nil
 }
 
 /*
-По введённым данным о песне и page:
-- делаем запрос в хранилище о наличии песен с указанными параметрами
-- если найдётся несколько песен, то представим, что выдаётся максимум 10 за раз.
-Где каждая из вложенных структур - это структура песни.
+По введённому song name, двум языкам и page:
+- для каждого языка выбираем перевод (если его нет -- оригинал)
+- выдаём по 1 куплету каждого языка на страницу, один напротив другого
 
 Заметки:
-1. Если будет введена группа, которой не существует, то вернётся not found.
+1. Если в переводах разное число куплетов, то недостающие куплеты пустые.
+2. Каждая выдача записывается как чтение текста.
 */
-func (uc *Usecase) GetFilteredSongs(song entity.FilterSong, page int) (entity.Content, error) {
-	var group string
-	var groupID *int
-	if song.Group != nil {
-		group = *song.Group
+func (uc *Usecase) GetSongTextAligned(ctx context.Context, name, first, second string, page int) (entity.Content, error) {
+	ctx, span := tracer.Start(ctx, "usecase.GetSongTextAligned")
+	defer span.End()
 
-		id, err := uc.repo.FindGroupID(group)
-		if err != nil {
-			uc.logger.Debug("Find group id error", zap.Error(err))
-			return entity.Content{}, err
+	left, err := uc.songVersion(ctx, name, []string{first})
+	if err != nil {
+		return entity.Content{}, err
+	}
+
+	right, err := uc.songVersion(ctx, name, []string{second})
+	if err != nil {
+		return entity.Content{}, err
+	}
+
+	total := max(len(left.Text), len(right.Text))
+	page = clampPage(page, total)
+
+	content := entity.Content{
+		CurrentPage: page,
+		TotalPage:   total,
+		TotalItems:  total,
+		Items: []entity.Couplet{
+			{Text: coupletAt(left.Text, page-1), Lang: left.Lang, Original: left.Original},
+			{Text: coupletAt(right.Text, page-1), Lang: right.Lang, Original: right.Original},
+		},
+	}
+
+	uc.recordRead(ctx, name)
+
+	return content, nil
+}
+
+/*
+По введённому song name, типу секции, page и lines:
+- получаем секции текста песни по порядку
+- если указан тип секции (verse, chorus, bridge, outro), оставляем только такие
+- если lines = 0, то выдаётся 1 секция на страницу;
+иначе строки всех секций идут подряд и выдаются по lines строк на страницу,
+сгруппированные по секциям, к которым они относятся
+
+Заметки:
+1. Поиск существующей песни происходит на стороне хранилища.
+2. Если у песни нет секций указанного типа, то вернётся not found.
+3. Каждая выдача записывается как чтение текста.
+*/
+func (uc *Usecase) GetSongLyrics(ctx context.Context, name, kind string, page, lines int) (entity.Content, error) {
+	ctx, span := tracer.Start(ctx, "usecase.GetSongLyrics")
+	defer span.End()
+
+	if kind != "" && !isSectionType(kind) {
+		uc.log(ctx).Debug("Unknown section type", zap.String("section", kind))
+		return entity.Content{}, errs.ErrBadRequest
+	}
+
+	sections, err := uc.repo.GetSongSections(ctx, name)
+	if err != nil {
+		uc.log(ctx).Debug("Find song sections error", zap.Error(err))
+		return entity.Content{}, err
+	}
+
+	if sections == nil {
+		uc.log(ctx).Debug("Song not exist", zap.String("song_name", name))
+		return entity.Content{}, errs.ErrNotFound
+	}
+
+	if kind != "" {
+		filtered := []entity.Section{}
+		for _, section := range sections {
+			if section.Type == kind {
+				filtered = append(filtered, section)
+			}
 		}
-		if id == 0 {
-			uc.logger.Debug("Group not exist", zap.String("group", group))
-			return entity.Content{}, errs.ErrNotFound
+		sections = filtered
+	}
+
+	if len(sections) == 0 {
+		uc.log(ctx).Debug("Song sections not exist", zap.String("song_name", name), zap.String("section", kind))
+		return entity.Content{}, errs.ErrNotFound
+	}
+
+	if lines <= 0 {
+		page = clampPage(page, len(sections))
+
+		content := entity.Content{
+			CurrentPage: page,
+			TotalPage:   len(sections),
+			TotalItems:  len(sections),
+			Items:       sections[page-1],
 		}
 
-		groupID = &id
+		uc.recordRead(ctx, name)
+
+		return content, nil
 	}
 
-	s := entity.FilterSongDTO{
-		Name:        song.Name,
-		GroupID:     groupID,
-		ReleaseDate: song.ReleaseDate,
+	totalLines := 0
+	for _, section := range sections {
+		totalLines += len(section.Lines)
+	}
+
+	totalPage := (totalLines + lines - 1) / lines
+	page = clampPage(page, totalPage)
+
+	content := entity.Content{
+		CurrentPage: page,
+		TotalPage:   max(totalPage, 1),
+		TotalItems:  totalLines,
+		Items:       sliceSectionLines(sections, (page-1)*lines, page*lines),
 	}
 
-	songs, err := uc.repo.GetFilteredSongs(s)
+	uc.recordRead(ctx, name)
+
+	return content, nil
+}
+
+/*
+По введённым данным о песне и page:
+- делаем запрос в хранилище о наличии песен с указанными параметрами
+- если найдётся несколько песен, то представим, что выдаётся максимум 10 за раз.
+Где каждая из вложенных структур - это структура песни.
+
+Заметки:
+1. Если будет введена группа, которой не существует, то вернётся not found.
+*/
+func (uc *Usecase) GetFilteredSongs(ctx context.Context, song entity.FilterSong, page int) (entity.Content, error) {
+	ctx, span := tracer.Start(ctx, "usecase.GetFilteredSongs")
+	defer span.End()
+
+	s, err := uc.filterDTO(ctx, song)
 	if err != nil {
-		uc.logger.Debug("Find song error", zap.Error(err))
+		return entity.Content{}, err
+	}
+
+	songs, err := uc.repo.GetFilteredSongs(ctx, s)
+	if err != nil {
+		uc.log(ctx).Debug("Find song error", zap.Error(err))
 		return entity.Content{}, err
 	}
 
 	if songs == nil {
-		uc.logger.Debug("Songs not exist")
+		uc.log(ctx).Debug("Songs not exist")
 		return entity.Content{}, errs.ErrNotFound
 	}
 
@@ -237,21 +531,769 @@ func (uc *Usecase) GetFilteredSongs(song entity.FilterSong, page int) (entity.Co
 	return content, nil
 }
 
-// createGroup создаёт группу в хранилище и возвращает id записи; или возвращает ошибку.
-func (uc *Usecase) createGroup(name string) (int, error) {
-	groupID, err := uc.repo.FindGroupID(name)
+/*
+По строке импорта и флагу enrich:
+- проверяем обязательные поля (группа или основной исполнитель, название)
+- если песня с таким названием уже есть у группы, то строка пропускается
+- при enrich недостающие поля дозапрашиваются во внешнем сервисе
+- проверяем дату, ссылку, исполнителей, жанры, теги и язык, и только потом создаём группы
+- записываем песню в хранилище
+
+Заметки:
+1. Возвращает true, если песня была добавлена; false -- если пропущена.
+2. Отклонённая строка не оставляет после себя пустую группу.
+*/
+func (uc *Usecase) ImportSong(ctx context.Context, row entity.ImportSong, enrich bool) (bool, error) {
+	ctx, span := tracer.Start(ctx, "usecase.ImportSong")
+	defer span.End()
+
+	if row.Group == "" {
+		row.Group = primaryArtist(row.Artists)
+	}
+	row.Group = strings.TrimSpace(row.Group)
+	row.Name = strings.TrimSpace(row.Name)
+	if row.Group == "" {
+		return false, errs.NewAppError(nil, "missing song group")
+	}
+	if row.Name == "" {
+		return false, errs.NewAppError(nil, "missing song name")
+	}
+
+	// Если группы ещё нет, то и песни у неё нет: проверка дубликата создавать группу не должна.
+	groupID, err := uc.repo.FindGroupID(ctx, row.Group)
 	if err != nil {
-		uc.logger.Debug("Find group id error", zap.Error(err))
-		return 0, err
+		uc.log(ctx).Debug("Find group id error", zap.Error(err))
+		return false, err
+	}
+	if groupID != 0 {
+		existing, err := uc.repo.GetFilteredSongs(ctx, entity.FilterSongDTO{
+			Name:    &row.Name,
+			GroupID: &groupID,
+		})
+		if err != nil {
+			uc.log(ctx).Debug("Find song error", zap.Error(err))
+			return false, err
+		}
+		if existing != nil {
+			uc.log(ctx).Debug("Song already exist", zap.String("song_name", row.Name))
+			return false, nil
+		}
+	}
+
+	var songDetail entity.SongDetail
+	if enrich && (row.ReleaseDate == "" || row.Text == nil || row.Link == "") {
+		songDetail, err = uc.webapi.GetSongDetail(ctx, entity.NewSong{Group: row.Group, Name: row.Name})
+		if err != nil {
+			uc.log(ctx).Debug("Can't receive song detail", zap.Error(err))
+			return false, err
+		}
+
+		if row.ReleaseDate == "" {
+			row.ReleaseDate = songDetail.ReleaseDate
+		}
+		if row.Text == nil {
+			row.Text = songDetail.Text
+		}
+		if row.Link == "" {
+			row.Link = songDetail.Link
+		}
+	}
+
+	switch {
+	case row.ReleaseDate == "":
+		return false, errs.NewAppError(nil, "missing release date")
+	case row.Text == nil:
+		return false, errs.NewAppError(nil, "missing song text")
+	case row.Link == "":
+		return false, errs.NewAppError(nil, "missing link")
+	}
+
+	if _, err := time.Parse(_releaseDateLayout, row.ReleaseDate); err != nil {
+		return false, errs.NewAppError(err, "invalid release date")
+	}
+
+	if row.Link, err = normalizeLink(row.Link); err != nil {
+		return false, errs.NewAppError(err, "invalid link")
+	}
+
+	for _, artist := range row.Artists {
+		if strings.TrimSpace(artist.Name) == "" || !isArtistRole(artist.Role) {
+			return false, errs.NewAppError(nil, "invalid artist")
+		}
+	}
+
+	genres, err := normalizeLabels(row.Genres)
+	if err != nil {
+		return false, errs.NewAppError(err, "invalid genres")
 	}
-	if groupID == 0 {
-		uc.logger.Debug("Group not exist", zap.String("group", name))
 
-		groupID, err = uc.repo.CreateGroup(name)
+	tags, err := normalizeLabels(row.Tags)
+	if err != nil {
+		return false, errs.NewAppError(err, "invalid tags")
+	}
+
+	var lang *string
+	if row.Lang != "" {
+		tag, err := parseLang(row.Lang)
 		if err != nil {
-			uc.logger.Debug("Create group error", zap.Error(err))
-			return 0, err
+			return false, errs.NewAppError(err, "invalid language tag")
 		}
+		lang = &tag
+	}
+
+	groupID, err = uc.createGroup(ctx, row.Group)
+	if err != nil {
+		uc.log(ctx).Debug("Can't create group", zap.Error(err))
+		return false, err
+	}
+
+	artists, err := uc.resolveArtists(ctx, row.Artists)
+	if err != nil {
+		uc.log(ctx).Debug("Can't resolve artists", zap.Error(err))
+		return false, err
+	}
+
+	sections := versesFromText(row.Text)
+	songDTO := entity.SongDTO{
+		Name:        &row.Name,
+		GroupID:     &groupID,
+		ReleaseDate: &row.ReleaseDate,
+		Text:        &row.Text,
+		Link:        &row.Link,
+		Sections:    &sections,
+		Artists:     &artists,
+		Genres:      &genres,
+		Tags:        &tags,
+		Lang:        lang,
+	}
+
+	if err = uc.withAlbum(ctx, &songDTO, songDetail); err != nil {
+		uc.log(ctx).Debug("Can't create album", zap.Error(err))
+		return false, err
+	}
+
+	if err = uc.repo.CreateSong(ctx, songDTO); err != nil {
+		uc.log(ctx).Debug("Can't save imported song", zap.Error(err))
+		return false, err
+	}
+
+	return true, nil
+}
+
+/*
+По введённым данным о песне:
+- возвращаем итератор по отфильтрованному каталогу, не собирая его в памяти
+
+Заметки:
+1. Если будет введена группа, которой не существует, то вернётся not found.
+2. Ошибки чтения из хранилища приходят элементами итератора.
+*/
+func (uc *Usecase) StreamSongs(ctx context.Context, song entity.FilterSong) (iter.Seq2[entity.Song, error], error) {
+	ctx, span := tracer.Start(ctx, "usecase.StreamSongs")
+	defer span.End()
+
+	s, err := uc.filterDTO(ctx, song)
+	if err != nil {
+		return nil, err
+	}
+
+	return uc.repo.StreamSongs(ctx, s), nil
+}
+
+/*
+По введённым данным о песне:
+- считаем отфильтрованные песни по жанрам, тегам, группам и годам выпуска
+
+Заметки:
+1. Если будет введена группа, которой не существует, то вернётся not found.
+2. Пустой результат -- это пустые списки, а не not found.
+*/
+func (uc *Usecase) GetSongFacets(ctx context.Context, song entity.FilterSong) (entity.SongFacets, error) {
+	ctx, span := tracer.Start(ctx, "usecase.GetSongFacets")
+	defer span.End()
+
+	s, err := uc.filterDTO(ctx, song)
+	if err != nil {
+		return entity.SongFacets{}, err
+	}
+
+	facets, err := uc.repo.GetSongFacets(ctx, s)
+	if err != nil {
+		uc.log(ctx).Debug("Find song facets error", zap.Error(err))
+		return entity.SongFacets{}, err
+	}
+
+	return facets, nil
+}
+
+/*
+По введённым данным о песне:
+- считаем отфильтрованные песни по группам, годам и десятилетиям выпуска
+- считаем среднее число куплетов и берём последние добавленные песни
+- удалённые песни, альбомы и плейлисты считаются по всему каталогу, без фильтра
+
+Заметки:
+1. Если будет введена группа, которой не существует, то вернётся not found.
+*/
+func (uc *Usecase) GetSongStats(ctx context.Context, song entity.FilterSong) (entity.SongStats, error) {
+	ctx, span := tracer.Start(ctx, "usecase.GetSongStats")
+	defer span.End()
+
+	s, err := uc.filterDTO(ctx, song)
+	if err != nil {
+		return entity.SongStats{}, err
+	}
+
+	stats, err := uc.repo.GetSongStats(ctx, s, _defaultRecentSongs)
+	if err != nil {
+		uc.log(ctx).Debug("Find song stats error", zap.Error(err))
+		return entity.SongStats{}, err
+	}
+
+	return stats, nil
+}
+
+/*
+По введённому song name и page:
+- оцениваем по сходству песни той же группы и песни с похожим текстом: та же группа,
+близкая дата выпуска, похожий текст
+- веса оценки берутся из конфига (similar); если они не заданы, то используются веса по умолчанию
+- выдаётся максимум 10 песен за раз, самые похожие -- первыми
+
+Заметки:
+1. Если песни нет или похожих песен нет, то вернётся not found.
+*/
+func (uc *Usecase) GetSimilarSongs(ctx context.Context, name string, page int) (entity.Content, error) {
+	ctx, span := tracer.Start(ctx, "usecase.GetSimilarSongs")
+	defer span.End()
+
+	exists, err := uc.repo.SongExists(ctx, name)
+	if err != nil {
+		uc.log(ctx).Debug("Find song error", zap.Error(err))
+		return entity.Content{}, err
+	}
+	if !exists {
+		uc.log(ctx).Debug("Song not exist", zap.String("song_name", name))
+		return entity.Content{}, errs.ErrNotFound
+	}
+
+	weights := uc.similarWeights()
+
+	const perPage = 10
+	page = max(page, 1)
+
+	songs, total, err := uc.repo.GetSimilarSongs(ctx, name, weights, perPage, (page-1)*perPage)
+	if err != nil {
+		uc.log(ctx).Debug("Find similar songs error", zap.Error(err))
+		return entity.Content{}, err
+	}
+
+	if total == 0 {
+		uc.log(ctx).Debug("Similar songs not found", zap.String("song_name", name))
+		return entity.Content{}, errs.ErrNotFound
+	}
+
+	totalPage := (total + perPage - 1) / perPage
+	if page > totalPage {
+		page = totalPage
+
+		songs, _, err = uc.repo.GetSimilarSongs(ctx, name, weights, perPage, (page-1)*perPage)
+		if err != nil {
+			uc.log(ctx).Debug("Find similar songs error", zap.Error(err))
+			return entity.Content{}, err
+		}
+	}
+
+	content := entity.Content{
+		CurrentPage: page,
+		TotalPage:   totalPage,
+		TotalItems:  total,
+		Items:       songs,
+	}
+
+	return content, nil
+}
+
+// similarWeights возвращает веса оценки похожих песен из конфига; отрицательные веса считаются нулевыми.
+func (uc *Usecase) similarWeights() entity.SimilarWeights {
+	cfg := config.FromContext(uc.ctx).Similar
+	weights := entity.SimilarWeights{
+		Group:   max(cfg.GroupWeight, 0),
+		Release: max(cfg.ReleaseWeight, 0),
+		Lyrics:  max(cfg.LyricsWeight, 0),
+	}
+
+	if weights == (entity.SimilarWeights{}) {
+		return _defaultSimilarWeights
+	}
+	return weights
+}
+
+// GetGenres возвращает словарь жанров.
+func (uc *Usecase) GetGenres(ctx context.Context) ([]string, error) {
+	ctx, span := tracer.Start(ctx, "usecase.GetGenres")
+	defer span.End()
+
+	genres, err := uc.repo.GetGenres(ctx)
+	if err != nil {
+		uc.log(ctx).Debug("Find genres error", zap.Error(err))
+		return nil, err
+	}
+
+	return genres, nil
+}
+
+/*
+По введённому song name и синхронизированным строкам (LRC):
+- сохраняем строки с временем начала
+- пересобираем из них текст песни и секции: пустая строка разделяет куплеты
+
+Заметки:
+1. Поиск существующей песни происходит на стороне хранилища.
+*/
+func (uc *Usecase) SetSongTimedLines(ctx context.Context, name string, lines []entity.TimedLine) (bool, error) {
+	ctx, span := tracer.Start(ctx, "usecase.SetSongTimedLines")
+	defer span.End()
+
+	if len(lines) == 0 {
+		uc.log(ctx).Debug("Timed lyrics is empty", zap.String("song_name", name))
+		return false, errs.ErrBadRequest
+	}
+
+	text := textFromTimedLines(lines)
+	sections := versesFromText(text)
+
+	song := entity.SongDTO{
+		Text:       &text,
+		Sections:   &sections,
+		TimedLines: &lines,
+	}
+
+	isUpdated, err := uc.repo.UpdateSong(ctx, name, song)
+	if err != nil {
+		uc.log(ctx).Debug("Update song error", zap.Error(err))
+		return false, err
+	}
+
+	return isUpdated, nil
+}
+
+// GetSongTimedLines возвращает все синхронизированные строки песни или ошибку (not found -- если их нет).
+// Выдача записывается как чтение текста.
+func (uc *Usecase) GetSongTimedLines(ctx context.Context, name string) ([]entity.TimedLine, error) {
+	ctx, span := tracer.Start(ctx, "usecase.GetSongTimedLines")
+	defer span.End()
+
+	lines, err := uc.repo.GetSongTimedLines(ctx, name)
+	if err != nil {
+		uc.log(ctx).Debug("Find song timed lines error", zap.Error(err))
+		return nil, err
+	}
+
+	if len(lines) == 0 {
+		uc.log(ctx).Debug("Song or timed lyrics not exist", zap.String("song_name", name))
+		return nil, errs.ErrNotFound
+	}
+
+	uc.recordRead(ctx, name)
+
+	return lines, nil
+}
+
+/*
+По введённому song name, page и window (мс):
+- получаем синхронизированные строки песни
+- страница -- это временное окно длиной window: [window*(page-1), window*page)
+
+Заметки:
+1. Окно может оказаться пустым (например, на проигрыше) -- тогда items пуст.
+*/
+func (uc *Usecase) GetSongTimedLyrics(ctx context.Context, name string, page, window int) (entity.Content, error) {
+	ctx, span := tracer.Start(ctx, "usecase.GetSongTimedLyrics")
+	defer span.End()
+
+	lines, err := uc.GetSongTimedLines(ctx, name)
+	if err != nil {
+		return entity.Content{}, err
+	}
+
+	if window <= 0 {
+		window = _defaultLyricsWindow
+	}
+
+	totalPage := lines[len(lines)-1].TimeMS/window + 1
+	page = clampPage(page, totalPage)
+
+	from, to := window*(page-1), window*page
+	items := []entity.TimedLine{}
+	for _, line := range lines {
+		if line.TimeMS >= from && line.TimeMS < to {
+			items = append(items, line)
+		}
+	}
+
+	content := entity.Content{
+		CurrentPage: page,
+		TotalPage:   totalPage,
+		TotalItems:  len(lines),
+		Items:       items,
+	}
+
+	return content, nil
+}
+
+/*
+По введённому тексту запроса, конфигурации поиска и page:
+- ищем песни по названию, группе и тексту, упорядочивая по релевантности
+- выдаётся максимум 10 результатов за раз, у каждого -- фрагмент текста с подсветкой
+
+Заметки:
+1. lang -- конфигурация текстового поиска Postgres (simple, english, russian, ...);
+если не указана, каждая песня использует свою. Конфигурация песни выводится
+из языка её текста (lang), поэтому с lang находятся только песни на этом языке.
+2. Если ничего не нашлось, то вернётся not found.
+*/
+func (uc *Usecase) SearchSongs(ctx context.Context, q, lang string, page int) (entity.Content, error) {
+	ctx, span := tracer.Start(ctx, "usecase.SearchSongs")
+	defer span.End()
+
+	q = strings.TrimSpace(q)
+	if q == "" {
+		uc.log(ctx).Debug("Empty search query")
+		return entity.Content{}, errs.ErrBadRequest
+	}
+
+	const perPage = 10
+	page = max(page, 1)
+
+	results, total, err := uc.repo.SearchSongs(ctx, q, lang, perPage, (page-1)*perPage)
+	if err != nil {
+		uc.log(ctx).Debug("Search songs error", zap.Error(err))
+		return entity.Content{}, err
+	}
+
+	if total == 0 {
+		uc.log(ctx).Debug("Songs not found", zap.String("query", q))
+		return entity.Content{}, errs.ErrNotFound
+	}
+
+	totalPage := (total + perPage - 1) / perPage
+	if page > totalPage {
+		page = totalPage
+
+		results, _, err = uc.repo.SearchSongs(ctx, q, lang, perPage, (page-1)*perPage)
+		if err != nil {
+			uc.log(ctx).Debug("Search songs error", zap.Error(err))
+			return entity.Content{}, err
+		}
+	}
+
+	content := entity.Content{
+		CurrentPage: page,
+		TotalPage:   totalPage,
+		TotalItems:  total,
+		Items:       results,
+	}
+
+	return content, nil
+}
+
+// resolveArtists проверяет исполнителей и находит (или создаёт) их группы; или возвращает ошибку.
+func (uc *Usecase) resolveArtists(ctx context.Context, artists []entity.Artist) ([]entity.ArtistDTO, error) {
+	result := make([]entity.ArtistDTO, 0, len(artists))
+
+	for _, artist := range artists {
+		name := strings.TrimSpace(artist.Name)
+		if name == "" || !isArtistRole(artist.Role) {
+			uc.log(ctx).Debug("Invalid artist", zap.String("artist", artist.Name), zap.String("role", artist.Role))
+			return nil, errs.ErrBadRequest
+		}
+
+		groupID, err := uc.createGroup(ctx, name)
+		if err != nil {
+			uc.log(ctx).Debug("Can't create group", zap.Error(err))
+			return nil, err
+		}
+
+		result = append(result, entity.ArtistDTO{GroupID: groupID, Role: artist.Role})
+	}
+
+	return result, nil
+}
+
+// filterDTO приводит фильтр песен к фильтру хранилища; если группы не существует, возвращает not found.
+func (uc *Usecase) filterDTO(ctx context.Context, song entity.FilterSong) (entity.FilterSongDTO, error) {
+	var groupID *int
+	if song.Group != nil {
+		id, err := uc.repo.FindGroupID(ctx, *song.Group)
+		if err != nil {
+			uc.log(ctx).Debug("Find group id error", zap.Error(err))
+			return entity.FilterSongDTO{}, err
+		}
+		if id == 0 {
+			uc.log(ctx).Debug("Group not exist", zap.String("group", *song.Group))
+			return entity.FilterSongDTO{}, errs.ErrNotFound
+		}
+
+		groupID = &id
+	}
+
+	var artistID *int
+	if song.Artist != nil {
+		id, err := uc.repo.FindGroupID(ctx, *song.Artist)
+		if err != nil {
+			uc.log(ctx).Debug("Find artist id error", zap.Error(err))
+			return entity.FilterSongDTO{}, err
+		}
+		if id == 0 {
+			uc.log(ctx).Debug("Artist not exist", zap.String("artist", *song.Artist))
+			return entity.FilterSongDTO{}, errs.ErrNotFound
+		}
+
+		artistID = &id
+	}
+
+	filter := entity.FilterSongDTO{
+		Name:        song.Name,
+		GroupID:     groupID,
+		ArtistID:    artistID,
+		ReleaseDate: song.ReleaseDate,
+		Album:       song.Album,
+		AlbumID:     song.AlbumID,
+	}
+	if song.Genre != nil {
+		genre := strings.ToLower(strings.TrimSpace(*song.Genre))
+		filter.Genre = &genre
+	}
+	if song.Tag != nil {
+		tag := strings.ToLower(strings.TrimSpace(*song.Tag))
+		filter.Tag = &tag
+	}
+	if song.LinkStatus != nil {
+		if !isLinkStatus(*song.LinkStatus) {
+			uc.log(ctx).Debug("Invalid link status", zap.String("link_status", *song.LinkStatus))
+			return entity.FilterSongDTO{}, errs.ErrBadRequest
+		}
+		filter.LinkStatus = song.LinkStatus
+	}
+	if song.Sort != nil {
+		switch *song.Sort {
+		case entity.SortID:
+		case entity.SortPopularity:
+			var window string
+			if song.Window != nil {
+				window = *song.Window
+			}
+			w, err := parseWindow(window)
+			if err != nil {
+				uc.log(ctx).Debug("Invalid window", zap.String("window", window), zap.Error(err))
+				return entity.FilterSongDTO{}, errs.ErrBadRequest
+			}
+			filter.Popularity = &w
+		default:
+			uc.log(ctx).Debug("Invalid sort", zap.String("sort", *song.Sort))
+			return entity.FilterSongDTO{}, errs.ErrBadRequest
+		}
+	}
+
+	return filter, nil
+}
+
+// createGroup возвращает id группы, создавая её при необходимости; или возвращает ошибку.
+// Поиск и создание -- один upsert в primary: чтение с реплики могло не увидеть
+// только что созданную группу.
+func (uc *Usecase) createGroup(ctx context.Context, name string) (int, error) {
+	groupID, err := uc.repo.CreateGroup(ctx, name)
+	if err != nil {
+		uc.log(ctx).Debug("Create group error", zap.Error(err))
+		return 0, err
 	}
 	return groupID, nil
 }
+
+// versesFromText превращает куплеты текста в секции verse; строки куплета разделены переводом строки.
+func versesFromText(text []string) []entity.Section {
+	sections := make([]entity.Section, 0, len(text))
+	for _, couplet := range text {
+		sections = append(sections, entity.Section{
+			Type:  entity.SectionVerse,
+			Lines: strings.Split(couplet, "\n"),
+		})
+	}
+	return sections
+}
+
+// textFromTimedLines собирает куплеты из синхронизированных строк: пустая строка разделяет куплеты.
+func textFromTimedLines(lines []entity.TimedLine) []string {
+	text := []string{}
+	var couplet []string
+
+	for _, line := range lines {
+		if line.Text == "" {
+			if len(couplet) > 0 {
+				text = append(text, strings.Join(couplet, "\n"))
+				couplet = nil
+			}
+			continue
+		}
+		couplet = append(couplet, line.Text)
+	}
+	if len(couplet) > 0 {
+		text = append(text, strings.Join(couplet, "\n"))
+	}
+
+	return text
+}
+
+// textFromSections собирает плоский текст (1 элемент -- 1 секция) для обратной совместимости.
+func textFromSections(sections []entity.Section) []string {
+	text := make([]string, 0, len(sections))
+	for _, section := range sections {
+		text = append(text, strings.Join(section.Lines, "\n"))
+	}
+	return text
+}
+
+func validateSections(sections []entity.Section) error {
+	for i, section := range sections {
+		if !isSectionType(section.Type) {
+			return fmt.Errorf("section %d: unknown type %q", i+1, section.Type)
+		}
+		if len(section.Lines) == 0 {
+			return fmt.Errorf("section %d: missing lines", i+1)
+		}
+	}
+	return nil
+}
+
+// primaryArtist возвращает первого исполнителя с ролью primary или пустую строку.
+func primaryArtist(artists []entity.Artist) string {
+	for _, artist := range artists {
+		if artist.Role == entity.RolePrimary {
+			return strings.TrimSpace(artist.Name)
+		}
+	}
+	return ""
+}
+
+// normalizeLabels приводит жанры или теги к нижнему регистру и убирает повторы; пустая метка -- ошибка.
+func normalizeLabels(labels []string) ([]string, error) {
+	result := make([]string, 0, len(labels))
+	seen := make(map[string]bool, len(labels))
+
+	for i, label := range labels {
+		label = strings.ToLower(strings.TrimSpace(label))
+		if label == "" {
+			return nil, fmt.Errorf("label %d is empty", i+1)
+		}
+		if seen[label] {
+			continue
+		}
+		seen[label] = true
+		result = append(result, label)
+	}
+
+	return result, nil
+}
+
+/*
+normalizeLink проверяет ссылку и приводит её к единому виду:
+- допускаются только абсолютные http(s)-ссылки с хостом и не длиннее 255 символов
+- схема и хост -- в нижнем регистре, порт по умолчанию и фрагмент (#...) убираются
+*/
+func normalizeLink(link string) (string, error) {
+	u, err := url.Parse(strings.TrimSpace(link))
+	if err != nil {
+		return "", err
+	}
+
+	u.Scheme = strings.ToLower(u.Scheme)
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", fmt.Errorf("unsupported link scheme %q", u.Scheme)
+	}
+	if u.Hostname() == "" || u.User != nil {
+		return "", fmt.Errorf("link must have a host and no credentials")
+	}
+	if !netguard.PublicHost(u.Hostname()) {
+		return "", fmt.Errorf("link must point to a public host")
+	}
+
+	host, port := strings.ToLower(u.Hostname()), u.Port()
+	if (u.Scheme == "http" && port == "80") || (u.Scheme == "https" && port == "443") {
+		port = ""
+	}
+	if port != "" {
+		host = net.JoinHostPort(host, port)
+	} else if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+	u.Host = host
+	u.Fragment, u.RawFragment = "", ""
+
+	normalized := u.String()
+	if len(normalized) > 255 {
+		return "", fmt.Errorf("link is longer than 255 characters")
+	}
+
+	return normalized, nil
+}
+
+func isLinkStatus(status string) bool {
+	switch status {
+	case entity.LinkStatusUnchecked, entity.LinkStatusOK, entity.LinkStatusBroken:
+		return true
+	}
+	return false
+}
+
+func isArtistRole(role string) bool {
+	switch role {
+	case entity.RolePrimary, entity.RoleFeatured, entity.RoleComposer, entity.RoleLyricist:
+		return true
+	}
+	return false
+}
+
+func isSectionType(kind string) bool {
+	switch kind {
+	case entity.SectionVerse, entity.SectionChorus, entity.SectionBridge, entity.SectionOutro:
+		return true
+	}
+	return false
+}
+
+// sliceSectionLines возвращает строки с from по to (сквозная нумерация), сохраняя деление на секции.
+func sliceSectionLines(sections []entity.Section, from, to int) []entity.Section {
+	result := []entity.Section{}
+	offset := 0
+
+	for _, section := range sections {
+		start, end := from-offset, to-offset
+		offset += len(section.Lines)
+
+		start = max(start, 0)
+		end = min(end, len(section.Lines))
+		if start >= end {
+			continue
+		}
+
+		result = append(result, entity.Section{
+			Type:  section.Type,
+			Label: section.Label,
+			Lines: section.Lines[start:end],
+		})
+	}
+
+	return result
+}
+
+// clampPage приводит page к диапазону [1, total].
+func clampPage(page, total int) int {
+	if page > total {
+		page = total
+	}
+	if page < 1 {
+		page = 1
+	}
+	return page
+}
@@ -0,0 +1,148 @@
+package usecase
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-rest-api/internal/entity"
+	"go-rest-api/internal/errs"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// _defaultPopularWindow -- окно популярности, если оно не указано.
+	_defaultPopularWindow = 7 * 24 * time.Hour
+	// _maxPopularWindow -- самое длинное окно популярности.
+	_maxPopularWindow = 365 * 24 * time.Hour
+)
+
+type (
+	PlayRepo interface {
+		SongExists(context.Context, string) (bool, error)
+		FindSongID(context.Context, string) (int, error)
+		GetPopularSongs(context.Context, time.Duration, int, int) ([]entity.PopularSong, int, error)
+	}
+
+	// PlayRecorder записывает события асинхронно, см. playtrack.Recorder.
+	PlayRecorder interface {
+		Record(entity.PlayEvent) bool
+	}
+)
+
+// RecordPlay записывает явное прослушивание песни или возвращает ошибку (not found -- если песни нет).
+func (uc *Usecase) RecordPlay(ctx context.Context, name string) error {
+	ctx, span := tracer.Start(ctx, "usecase.RecordPlay")
+	defer span.End()
+
+	id, err := uc.repo.FindSongID(ctx, name)
+	if err != nil {
+		uc.log(ctx).Debug("Find song error", zap.Error(err))
+		return err
+	}
+	if id == 0 {
+		uc.log(ctx).Debug("Song not exist", zap.String("song_name", name))
+		return errs.ErrNotFound
+	}
+
+	uc.plays.Record(entity.PlayEvent{SongID: id, SongName: name, Kind: entity.PlayKindPlay, At: time.Now()})
+	return nil
+}
+
+/*
+По введённому окну и page:
+- считаем прослушивания и чтения текста каждой песни за окно
+- выдаётся максимум 10 песен за раз, самые популярные -- первыми
+
+Заметки:
+1. Окно -- число с суффиксом d (дни) или длительность Go (12h, 90m); по умолчанию 7d.
+2. Если за окно ничего не слушали, то вернётся not found.
+*/
+func (uc *Usecase) GetPopularSongs(ctx context.Context, window string, page int) (entity.Content, error) {
+	ctx, span := tracer.Start(ctx, "usecase.GetPopularSongs")
+	defer span.End()
+
+	w, err := parseWindow(window)
+	if err != nil {
+		uc.log(ctx).Debug("Invalid window", zap.String("window", window), zap.Error(err))
+		return entity.Content{}, errs.ErrBadRequest
+	}
+
+	const perPage = 10
+	page = max(page, 1)
+
+	songs, total, err := uc.repo.GetPopularSongs(ctx, w, perPage, (page-1)*perPage)
+	if err != nil {
+		uc.log(ctx).Debug("Find popular songs error", zap.Error(err))
+		return entity.Content{}, err
+	}
+
+	if total == 0 {
+		uc.log(ctx).Debug("Popular songs not found", zap.Duration("window", w))
+		return entity.Content{}, errs.ErrNotFound
+	}
+
+	totalPage := (total + perPage - 1) / perPage
+	if page > totalPage {
+		page = totalPage
+
+		songs, _, err = uc.repo.GetPopularSongs(ctx, w, perPage, (page-1)*perPage)
+		if err != nil {
+			uc.log(ctx).Debug("Find popular songs error", zap.Error(err))
+			return entity.Content{}, err
+		}
+	}
+
+	content := entity.Content{
+		CurrentPage: page,
+		TotalPage:   totalPage,
+		TotalItems:  total,
+		Items:       songs,
+	}
+
+	return content, nil
+}
+
+// recordRead записывает чтение текста песни: каждая выдача текста (страница, секции,
+// синхронизированные строки, LRC) считается отдельным чтением. id песни определяется сразу,
+// чтобы переименование до записи партии не переносило чтение на другую песню.
+func (uc *Usecase) recordRead(ctx context.Context, name string) {
+	id, err := uc.repo.FindSongID(ctx, name)
+	if err != nil || id == 0 {
+		uc.log(ctx).Debug("Read not recorded, song not found", zap.String("song_name", name), zap.Error(err))
+		return
+	}
+
+	uc.plays.Record(entity.PlayEvent{SongID: id, SongName: name, Kind: entity.PlayKindRead, At: time.Now()})
+}
+
+// parseWindow разбирает окно популярности: "7d" или длительность Go; пустое -- окно по умолчанию.
+func parseWindow(window string) (time.Duration, error) {
+	window = strings.TrimSpace(window)
+	if window == "" {
+		return _defaultPopularWindow, nil
+	}
+
+	var w time.Duration
+	if days, ok := strings.CutSuffix(window, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, err
+		}
+		w = time.Duration(n) * 24 * time.Hour
+	} else {
+		d, err := time.ParseDuration(window)
+		if err != nil {
+			return 0, err
+		}
+		w = d
+	}
+
+	if w <= 0 || w > _maxPopularWindow {
+		return 0, errs.ErrBadRequest
+	}
+
+	return w, nil
+}
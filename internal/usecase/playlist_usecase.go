@@ -0,0 +1,274 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+
+	"go-rest-api/internal/entity"
+	"go-rest-api/internal/errs"
+
+	"go.uber.org/zap"
+)
+
+type PlaylistRepo interface {
+	CreatePlaylist(context.Context, entity.Playlist) (int, error)
+	GetPlaylist(context.Context, int) (*entity.Playlist, error)
+	GetPlaylists(context.Context, int, int) ([]entity.Playlist, int, error)
+	UpdatePlaylist(context.Context, int, entity.Playlist) (bool, error)
+	DeletePlaylist(context.Context, int) (bool, error)
+	GetPlaylistEntries(context.Context, int, int, int) ([]entity.PlaylistEntry, int, error)
+	AddPlaylistEntry(context.Context, int, int, int) (int, error)
+	MovePlaylistEntry(context.Context, int, int, int) (int, error)
+	DeletePlaylistEntry(context.Context, int, int) error
+	SetPlaylistOrder(context.Context, int, []int) error
+}
+
+// AddPlaylist проверяет название плейлиста, записывает его в хранилище и возвращает id.
+func (uc *Usecase) AddPlaylist(ctx context.Context, playlist entity.Playlist) (int, error) {
+	ctx, span := tracer.Start(ctx, "usecase.AddPlaylist")
+	defer span.End()
+
+	if playlist.Name == nil || strings.TrimSpace(*playlist.Name) == "" {
+		uc.log(ctx).Debug("Missing playlist name")
+		return 0, errs.ErrBadRequest
+	}
+
+	id, err := uc.repo.CreatePlaylist(ctx, playlist)
+	if err != nil {
+		uc.log(ctx).Debug("Can't save new playlist", zap.Error(err))
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// GetPlaylist возвращает плейлист по id или ошибку (not found -- если плейлиста нет).
+func (uc *Usecase) GetPlaylist(ctx context.Context, id int) (entity.Playlist, error) {
+	ctx, span := tracer.Start(ctx, "usecase.GetPlaylist")
+	defer span.End()
+
+	playlist, err := uc.repo.GetPlaylist(ctx, id)
+	if err != nil {
+		uc.log(ctx).Debug("Find playlist error", zap.Error(err))
+		return entity.Playlist{}, err
+	}
+
+	if playlist == nil {
+		uc.log(ctx).Debug("Playlist not exist", zap.Int("playlist_id", id))
+		return entity.Playlist{}, errs.ErrNotFound
+	}
+
+	return *playlist, nil
+}
+
+// GetPlaylists выдаёт плейлисты по 10 за раз.
+func (uc *Usecase) GetPlaylists(ctx context.Context, page int) (entity.Content, error) {
+	ctx, span := tracer.Start(ctx, "usecase.GetPlaylists")
+	defer span.End()
+
+	const perPage = 10
+	page = max(page, 1)
+
+	playlists, total, err := uc.repo.GetPlaylists(ctx, perPage, (page-1)*perPage)
+	if err != nil {
+		uc.log(ctx).Debug("Find playlists error", zap.Error(err))
+		return entity.Content{}, err
+	}
+
+	if total == 0 {
+		uc.log(ctx).Debug("Playlists not exist")
+		return entity.Content{}, errs.ErrNotFound
+	}
+
+	totalPage := (total + perPage - 1) / perPage
+	if page > totalPage {
+		page = totalPage
+
+		playlists, _, err = uc.repo.GetPlaylists(ctx, perPage, (page-1)*perPage)
+		if err != nil {
+			uc.log(ctx).Debug("Find playlists error", zap.Error(err))
+			return entity.Content{}, err
+		}
+	}
+
+	content := entity.Content{
+		CurrentPage: page,
+		TotalPage:   totalPage,
+		TotalItems:  total,
+		Items:       playlists,
+	}
+
+	return content, nil
+}
+
+// UpdatePlaylist обновляет название и описание плейлиста; пустое название -- bad request.
+func (uc *Usecase) UpdatePlaylist(ctx context.Context, id int, playlist entity.Playlist) (bool, error) {
+	ctx, span := tracer.Start(ctx, "usecase.UpdatePlaylist")
+	defer span.End()
+
+	if playlist.Name != nil && strings.TrimSpace(*playlist.Name) == "" {
+		uc.log(ctx).Debug("Empty playlist name")
+		return false, errs.ErrBadRequest
+	}
+
+	isUpdated, err := uc.repo.UpdatePlaylist(ctx, id, playlist)
+	if err != nil {
+		uc.log(ctx).Debug("Update playlist error", zap.Error(err))
+		return false, err
+	}
+
+	return isUpdated, nil
+}
+
+// DeletePlaylist "удаляет" плейлист; песни плейлиста остаются в хранилище.
+func (uc *Usecase) DeletePlaylist(ctx context.Context, id int) (bool, error) {
+	ctx, span := tracer.Start(ctx, "usecase.DeletePlaylist")
+	defer span.End()
+
+	isDeleted, err := uc.repo.DeletePlaylist(ctx, id)
+	if err != nil {
+		uc.log(ctx).Debug("Delete playlist error", zap.Error(err))
+		return false, err
+	}
+
+	return isDeleted, nil
+}
+
+/*
+По введённому playlist id и page:
+- проверяем, что плейлист существует
+- выдаём записи плейлиста по порядку, по 10 за раз
+
+Заметки:
+1. Удалённые песни остаются в плейлисте с available = false.
+2. Для пустого плейлиста возвращается одна пустая страница.
+*/
+func (uc *Usecase) GetPlaylistEntries(ctx context.Context, id, page int) (entity.Content, error) {
+	ctx, span := tracer.Start(ctx, "usecase.GetPlaylistEntries")
+	defer span.End()
+
+	if _, err := uc.GetPlaylist(ctx, id); err != nil {
+		return entity.Content{}, err
+	}
+
+	const perPage = 10
+	page = max(page, 1)
+
+	entries, total, err := uc.repo.GetPlaylistEntries(ctx, id, perPage, (page-1)*perPage)
+	if err != nil {
+		uc.log(ctx).Debug("Find playlist entries error", zap.Error(err))
+		return entity.Content{}, err
+	}
+
+	totalPage := (total + perPage - 1) / perPage
+	if total > 0 && page > totalPage {
+		page = totalPage
+
+		entries, _, err = uc.repo.GetPlaylistEntries(ctx, id, perPage, (page-1)*perPage)
+		if err != nil {
+			uc.log(ctx).Debug("Find playlist entries error", zap.Error(err))
+			return entity.Content{}, err
+		}
+	}
+	if entries == nil {
+		entries = []entity.PlaylistEntry{}
+	}
+
+	content := entity.Content{
+		CurrentPage: clampPage(page, totalPage),
+		TotalPage:   max(totalPage, 1),
+		TotalItems:  total,
+		Items:       entries,
+	}
+
+	return content, nil
+}
+
+/*
+По введённому playlist id и записи:
+- добавляем песню на указанную позицию, сдвигая следующие записи; без позиции -- в конец
+- возвращаем итоговую позицию
+
+Заметки:
+1. Повторное добавление той же песни возвращает conflict.
+2. Удалённую песню добавить нельзя -- bad request.
+*/
+func (uc *Usecase) AddPlaylistEntry(ctx context.Context, id int, entry entity.NewPlaylistEntry) (int, error) {
+	ctx, span := tracer.Start(ctx, "usecase.AddPlaylistEntry")
+	defer span.End()
+
+	if entry.SongID <= 0 || entry.Position < 0 {
+		uc.log(ctx).Debug("Invalid playlist entry", zap.Int("song_id", entry.SongID), zap.Int("position", entry.Position))
+		return 0, errs.ErrBadRequest
+	}
+
+	position, err := uc.repo.AddPlaylistEntry(ctx, id, entry.SongID, entry.Position)
+	if err != nil {
+		uc.log(ctx).Debug("Add playlist entry error", zap.Error(err))
+		return 0, err
+	}
+
+	return position, nil
+}
+
+// MovePlaylistEntry переносит песню плейлиста на новую позицию и возвращает итоговую позицию.
+func (uc *Usecase) MovePlaylistEntry(ctx context.Context, id, songID int, move entity.MovePlaylistEntry) (int, error) {
+	ctx, span := tracer.Start(ctx, "usecase.MovePlaylistEntry")
+	defer span.End()
+
+	if move.Position <= 0 {
+		uc.log(ctx).Debug("Invalid playlist position", zap.Int("position", move.Position))
+		return 0, errs.ErrBadRequest
+	}
+
+	position, err := uc.repo.MovePlaylistEntry(ctx, id, songID, move.Position)
+	if err != nil {
+		uc.log(ctx).Debug("Move playlist entry error", zap.Error(err))
+		return 0, err
+	}
+
+	return position, nil
+}
+
+// DeletePlaylistEntry убирает песню из плейлиста.
+func (uc *Usecase) DeletePlaylistEntry(ctx context.Context, id, songID int) error {
+	ctx, span := tracer.Start(ctx, "usecase.DeletePlaylistEntry")
+	defer span.End()
+
+	if err := uc.repo.DeletePlaylistEntry(ctx, id, songID); err != nil {
+		uc.log(ctx).Debug("Delete playlist entry error", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+/*
+По введённому playlist id и списку song id:
+- проверяем, что песни в списке не повторяются
+- расставляем записи плейлиста в порядке списка
+
+Заметки:
+1. Список должен содержать ровно все песни плейлиста; добавлять и убирать песни
+нужно через отдельные запросы.
+*/
+func (uc *Usecase) SetPlaylistOrder(ctx context.Context, id int, songIDs []int) error {
+	ctx, span := tracer.Start(ctx, "usecase.SetPlaylistOrder")
+	defer span.End()
+
+	seen := make(map[int]bool, len(songIDs))
+	for _, songID := range songIDs {
+		if songID <= 0 || seen[songID] {
+			uc.log(ctx).Debug("Invalid playlist order", zap.Int("song_id", songID))
+			return errs.ErrBadRequest
+		}
+		seen[songID] = true
+	}
+
+	if err := uc.repo.SetPlaylistOrder(ctx, id, songIDs); err != nil {
+		uc.log(ctx).Debug("Set playlist order error", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
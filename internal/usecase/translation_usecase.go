@@ -0,0 +1,179 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"go-rest-api/internal/entity"
+	"go-rest-api/internal/errs"
+
+	"go.uber.org/zap"
+	"golang.org/x/text/language"
+)
+
+type TranslationRepo interface {
+	GetSongTranslations(context.Context, string) ([]entity.Translation, error)
+	GetSongTranslation(context.Context, string, string) ([]string, error)
+	SetSongTranslation(context.Context, string, string, []string) (bool, error)
+	DeleteSongTranslation(context.Context, string, string) (bool, error)
+}
+
+// GetSongTranslations возвращает языки текста песни (первым -- оригинал) или ошибку (not found -- если песни нет).
+func (uc *Usecase) GetSongTranslations(ctx context.Context, name string) ([]entity.Translation, error) {
+	ctx, span := tracer.Start(ctx, "usecase.GetSongTranslations")
+	defer span.End()
+
+	translations, err := uc.repo.GetSongTranslations(ctx, name)
+	if err != nil {
+		uc.log(ctx).Debug("Find song translations error", zap.Error(err))
+		return nil, err
+	}
+
+	if translations == nil {
+		uc.log(ctx).Debug("Song not exist", zap.String("song_name", name))
+		return nil, errs.ErrNotFound
+	}
+
+	return translations, nil
+}
+
+/*
+По введённому song name, языку и тексту:
+- проверяем, что язык -- корректный тег BCP-47, и приводим его к каноническому виду
+- проверяем, что это не язык оригинала (оригинал меняется через обновление песни)
+- сохраняем перевод; прежний перевод на этот язык заменяется
+
+Заметки:
+1. Текст перевода -- это куплеты, как и у оригинала: куплеты выравниваются по номеру.
+*/
+func (uc *Usecase) SetSongTranslation(ctx context.Context, name, lang string, text []string) (bool, error) {
+	ctx, span := tracer.Start(ctx, "usecase.SetSongTranslation")
+	defer span.End()
+
+	lang, err := parseLang(lang)
+	if err != nil {
+		uc.log(ctx).Debug("Invalid language tag", zap.Error(err))
+		return false, errs.ErrBadRequest
+	}
+
+	if len(text) == 0 {
+		uc.log(ctx).Debug("Translation is empty", zap.String("song_name", name))
+		return false, errs.ErrBadRequest
+	}
+
+	translations, err := uc.GetSongTranslations(ctx, name)
+	if err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	if translations[0].Lang == lang {
+		uc.log(ctx).Debug("Translation to the original language", zap.String("lang", lang))
+		return false, errs.ErrBadRequest
+	}
+
+	isUpdated, err := uc.repo.SetSongTranslation(ctx, name, lang, text)
+	if err != nil {
+		uc.log(ctx).Debug("Set song translation error", zap.Error(err))
+		return false, err
+	}
+
+	return isUpdated, nil
+}
+
+// DeleteSongTranslation удаляет перевод текста песни; false -- если песни или перевода нет.
+func (uc *Usecase) DeleteSongTranslation(ctx context.Context, name, lang string) (bool, error) {
+	ctx, span := tracer.Start(ctx, "usecase.DeleteSongTranslation")
+	defer span.End()
+
+	lang, err := parseLang(lang)
+	if err != nil {
+		uc.log(ctx).Debug("Invalid language tag", zap.Error(err))
+		return false, errs.ErrBadRequest
+	}
+
+	isDeleted, err := uc.repo.DeleteSongTranslation(ctx, name, lang)
+	if err != nil {
+		uc.log(ctx).Debug("Delete song translation error", zap.Error(err))
+		return false, err
+	}
+
+	return isDeleted, nil
+}
+
+// songVersion выбирает по предпочтениям язык текста песни и возвращает его вместе с текстом.
+func (uc *Usecase) songVersion(ctx context.Context, name string, langs []string) (entity.Translation, error) {
+	translations, err := uc.GetSongTranslations(ctx, name)
+	if err != nil {
+		return entity.Translation{}, err
+	}
+
+	version := matchTranslation(translations, langs)
+	if version.Original {
+		version.Text, err = uc.repo.GetSongText(ctx, name)
+	} else {
+		version.Text, err = uc.repo.GetSongTranslation(ctx, name, version.Lang)
+	}
+	if err != nil {
+		uc.log(ctx).Debug("Find song text error", zap.Error(err))
+		return entity.Translation{}, err
+	}
+
+	if len(version.Text) == 0 {
+		uc.log(ctx).Debug("Song text not exist", zap.String("song_name", name), zap.String("lang", version.Lang))
+		return entity.Translation{}, errs.ErrNotFound
+	}
+
+	return version, nil
+}
+
+// matchTranslation возвращает перевод, лучше всего подходящий под предпочтения
+// (например, en-US подойдёт к en); если подходящего нет -- оригинал.
+func matchTranslation(translations []entity.Translation, langs []string) entity.Translation {
+	if len(langs) == 0 || len(translations) == 1 {
+		return translations[0]
+	}
+
+	supported := make([]language.Tag, 0, len(translations))
+	for _, translation := range translations {
+		tag, err := language.Parse(translation.Lang)
+		if err != nil {
+			tag = language.Und
+		}
+		supported = append(supported, tag)
+	}
+
+	desired := make([]language.Tag, 0, len(langs))
+	for _, lang := range langs {
+		if tag, err := language.Parse(lang); err == nil {
+			desired = append(desired, tag)
+		}
+	}
+
+	_, index, confidence := language.NewMatcher(supported).Match(desired...)
+	if confidence < language.High {
+		return translations[0]
+	}
+
+	return translations[index]
+}
+
+// parseLang проверяет тег языка (BCP-47) и возвращает его канонический вид.
+func parseLang(lang string) (string, error) {
+	tag, err := language.Parse(strings.TrimSpace(lang))
+	if err != nil {
+		return "", err
+	}
+	return tag.String(), nil
+}
+
+// coupletAt возвращает куплет по номеру или пустую строку, если куплета нет.
+func coupletAt(text []string, i int) string {
+	if i < len(text) {
+		return text[i]
+	}
+	return ""
+}
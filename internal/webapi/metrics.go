@@ -0,0 +1,19 @@
+package webapi
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	upstreamDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "upstream_request_duration_seconds",
+		Help:    "External service call latency by service.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"service"})
+
+	upstreamErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "upstream_errors_total",
+		Help: "Failed external service calls by service and reason: network, status, decode, invalid.",
+	}, []string{"service", "reason"})
+)
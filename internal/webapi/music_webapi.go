@@ -6,16 +6,24 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
-	"path"
+	"time"
 
 	"go-rest-api/config"
 	"go-rest-api/internal/entity"
 	"go-rest-api/internal/errs"
 	"go-rest-api/pkg/logger"
+	"go-rest-api/pkg/requestid"
 
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 )
 
+var tracer = otel.Tracer("go-rest-api/internal/webapi")
+
 type Webapi struct {
 	ctx    context.Context
 	logger *logger.Logger
@@ -29,11 +37,27 @@ func New(ctx context.Context) *Webapi {
 }
 
 // GetSongDetail получает от внешнего сервиса данные о песне или возвращает ошибку.
-func (wa *Webapi) GetSongDetail(newSong entity.NewSong) (songDetail entity.SongDetail, err error) {
+func (wa *Webapi) GetSongDetail(ctx context.Context, newSong entity.NewSong) (songDetail entity.SongDetail, err error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	ctx, span := tracer.Start(ctx, "webapi.GetSongDetail", trace.WithSpanKind(trace.SpanKindClient))
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+
 	token := config.FromContext(wa.ctx).Webapi.Token
 	webapiURL := config.FromContext(wa.ctx).Webapi.URL
 	service := "info"
-	externalURL := path.Join(webapiURL, service)
+	externalURL, err := url.JoinPath(webapiURL, service)
+	if err != nil {
+		wa.log(ctx).Debug("Invalid external service URL", zap.Error(err))
+		return entity.SongDetail{}, err
+	}
 
 	params := url.Values{}
 	params.Add("group", newSong.Group)
@@ -41,41 +65,76 @@ func (wa *Webapi) GetSongDetail(newSong entity.NewSong) (songDetail entity.SongD
 
 	reqURL := fmt.Sprintf("%s?%s", externalURL, params.Encode())
 
-	req, err := http.NewRequest(http.MethodGet, reqURL, nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
 	if err != nil {
-		wa.logger.Debug("Failed to create request", zap.Error(err))
+		wa.log(ctx).Debug("Failed to create request", zap.Error(err))
 		return entity.SongDetail{}, err
 	}
 
 	req.Header.Set("Authorization", token)
+	if id := requestid.FromContext(ctx); id != "" {
+		req.Header.Set(requestid.Header, id)
+	}
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+	span.SetAttributes(semconv.HTTPRequestMethodKey.String(req.Method), semconv.URLFull(externalURL))
+
+	start := time.Now()
+	defer func() {
+		upstreamDuration.WithLabelValues(service).Observe(time.Since(start).Seconds())
+	}()
 
 	client := &http.Client{}
 	res, err := client.Do(req)
 	if err != nil {
-		wa.logger.Debug("Request to external service was executed with error", zap.String("url", req.URL.String()), zap.Error(err))
+		upstreamErrors.WithLabelValues(service, "network").Inc()
+		wa.log(ctx).Debug("Request to external service was executed with error", zap.String("url", req.URL.String()), zap.Error(err))
 		return entity.SongDetail{}, err
 	}
 	defer res.Body.Close()
 
+	span.SetAttributes(semconv.HTTPResponseStatusCode(res.StatusCode))
+
 	if res.StatusCode == http.StatusOK {
 		if err := json.NewDecoder(res.Body).Decode(&songDetail); err != nil {
-			wa.logger.Debug("Can't decode request body", zap.Error(err))
+			upstreamErrors.WithLabelValues(service, "decode").Inc()
+			wa.log(ctx).Debug("Can't decode request body", zap.Error(err))
 			return entity.SongDetail{}, err
 		}
 
 		if err = validate(songDetail); err != nil {
-			wa.logger.Debug("External API return incorrect song detail")
+			upstreamErrors.WithLabelValues(service, "invalid").Inc()
+			wa.log(ctx).Debug("External API return incorrect song detail")
 			return entity.SongDetail{}, err
 		}
 
 		return songDetail, nil
 	} else {
-		wa.logger.Debug("Request to external service return status code", zap.Int("status_code", res.StatusCode))
+		upstreamErrors.WithLabelValues(service, "status").Inc()
+		wa.log(ctx).Debug("Request to external service return status code", zap.Int("status_code", res.StatusCode))
 		errMsg := fmt.Sprintf("Received non-200 response status code: %s", res.Status)
 		return entity.SongDetail{}, errs.NewAppError(nil, errMsg)
 	}
 }
 
+// Ping проверяет, что внешний сервис отвечает; код ответа не важен.
+func (wa *Webapi) Ping(ctx context.Context) error {
+	cfg := config.FromContext(wa.ctx).Webapi
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, cfg.URL, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", cfg.Token)
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	res.Body.Close()
+
+	return nil
+}
+
 func validate(song entity.SongDetail) error {
 	if song.Text == nil {
 		return fmt.Errorf("missing song text")
@@ -88,3 +147,7 @@ func validate(song entity.SongDetail) error {
 	}
 	return nil
 }
+
+func (wa *Webapi) log(ctx context.Context) *logger.Logger {
+	return logger.Request(ctx, wa.logger)
+}
@@ -13,20 +13,70 @@ func Port(port string) Option {
 	}
 }
 
+// Таймауты и лимиты ниже: значение 0 оставляет умолчание.
+
 func ReadTimeout(timeout time.Duration) Option {
 	return func(s *Server) {
-		s.server.ReadTimeout = timeout
+		if timeout > 0 {
+			s.server.ReadTimeout = timeout
+		}
+	}
+}
+
+// ReadHeaderTimeout -- время на чтение заголовков; если не задан, действует ReadTimeout.
+func ReadHeaderTimeout(timeout time.Duration) Option {
+	return func(s *Server) {
+		if timeout > 0 {
+			s.server.ReadHeaderTimeout = timeout
+		}
 	}
 }
 
 func WriteTimeout(timeout time.Duration) Option {
 	return func(s *Server) {
-		s.server.WriteTimeout = timeout
+		if timeout > 0 {
+			s.server.WriteTimeout = timeout
+		}
 	}
 }
 
+// IdleTimeout -- сколько держать keep-alive соединение без запросов.
+func IdleTimeout(timeout time.Duration) Option {
+	return func(s *Server) {
+		if timeout > 0 {
+			s.server.IdleTimeout = timeout
+		}
+	}
+}
+
+// MaxHeaderBytes -- предельный размер заголовков запроса.
+func MaxHeaderBytes(size int) Option {
+	return func(s *Server) {
+		if size > 0 {
+			s.server.MaxHeaderBytes = size
+		}
+	}
+}
+
+// ShutdownTimeout -- сколько Shutdown ждёт завершения обрабатываемых запросов.
 func ShutdownTimeout(timeout time.Duration) Option {
 	return func(s *Server) {
-		s.shutdownTimeout = timeout
+		if timeout > 0 {
+			s.shutdownTimeout = timeout
+		}
+	}
+}
+
+// TLS включает HTTPS (и HTTP/2) с сертификатами из certs; mTLS -- если в certs задан CA клиентов.
+func TLS(certs *CertReloader) Option {
+	return func(s *Server) {
+		s.certs = certs
+	}
+}
+
+// H2C включает HTTP/2 без TLS (prior knowledge и Upgrade) для внутреннего трафика.
+func H2C() Option {
+	return func(s *Server) {
+		s.h2c = true
 	}
 }
@@ -4,12 +4,16 @@ import (
 	"context"
 	"net/http"
 	"time"
+
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
 )
 
 const (
 	_defaultAddr            = ":80"
 	_defaultReadTimeout     = 5 * time.Second
 	_defaultWriteTimeout    = 5 * time.Second
+	_defaultIdleTimeout     = 60 * time.Second
 	_defaultShutdownTimeout = 3 * time.Second
 )
 
@@ -17,6 +21,9 @@ type Server struct {
 	server          *http.Server
 	notify          chan error
 	shutdownTimeout time.Duration
+
+	certs *CertReloader
+	h2c   bool
 }
 
 func New(handler http.Handler, opts ...Option) *Server {
@@ -24,6 +31,7 @@ func New(handler http.Handler, opts ...Option) *Server {
 		Handler:      handler,
 		ReadTimeout:  _defaultReadTimeout,
 		WriteTimeout: _defaultWriteTimeout,
+		IdleTimeout:  _defaultIdleTimeout,
 		Addr:         _defaultAddr,
 	}
 
@@ -42,7 +50,28 @@ func New(handler http.Handler, opts ...Option) *Server {
 	return srv
 }
 
+// start запускает сервер: с TLS (и HTTP/2 через ALPN), если заданы сертификаты (TLS),
+// иначе открытым текстом -- с h2c, если он включён. Ошибка настройки приходит в Notify.
 func (s *Server) start() {
+	if s.certs != nil {
+		s.server.TLSConfig = s.certs.config()
+		if err := http2.ConfigureServer(s.server, nil); err != nil {
+			s.notify <- err
+			close(s.notify)
+			return
+		}
+
+		go func() {
+			s.notify <- s.server.ListenAndServeTLS("", "")
+			close(s.notify)
+		}()
+		return
+	}
+
+	if s.h2c {
+		s.server.Handler = h2c.NewHandler(s.server.Handler, &http2.Server{})
+	}
+
 	go func() {
 		s.notify <- s.server.ListenAndServe()
 		close(s.notify)
@@ -0,0 +1,172 @@
+package http_server
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"go-rest-api/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// _reloadCheckInterval -- как часто (не чаще) при рукопожатии проверяется, не сменились ли файлы.
+const _reloadCheckInterval = 10 * time.Second
+
+// CertReloader отдаёт сертификат сервера и пул CA клиентов, перечитывая файлы,
+// когда у них меняется время модификации. Так обновлённый сертификат подхватывается без перезапуска.
+// Если перечитать не удалось, остаются прежние сертификаты, а ошибка пишется в лог и доступна через Err.
+type CertReloader struct {
+	logger *logger.Logger
+
+	certFile     string
+	keyFile      string
+	clientCAFile string
+
+	mu        sync.Mutex
+	cert      *tls.Certificate
+	clientCAs *x509.CertPool
+	modTimes  map[string]time.Time
+	checked   time.Time
+	err       error
+}
+
+// NewCertReloader загружает сертификат (и CA клиентов для mTLS, если clientCAFile задан)
+// или возвращает ошибку.
+func NewCertReloader(ctx context.Context, certFile, keyFile, clientCAFile string) (*CertReloader, error) {
+	cr := &CertReloader{
+		logger:       logger.FromContext(ctx),
+		certFile:     certFile,
+		keyFile:      keyFile,
+		clientCAFile: clientCAFile,
+		modTimes:     make(map[string]time.Time),
+	}
+
+	if err := cr.load(); err != nil {
+		return nil, err
+	}
+	return cr, nil
+}
+
+// config возвращает базовую конфигурацию TLS; сертификат и CA клиентов берутся при каждом рукопожатии.
+func (cr *CertReloader) config() *tls.Config {
+	cfg := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			cert, _ := cr.current()
+			return cert, nil
+		},
+	}
+
+	if cr.clientCAFile != "" {
+		cfg.ClientAuth = tls.RequireAndVerifyClientCert
+		cfg.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			_, clientCAs := cr.current()
+
+			c := cfg.Clone()
+			c.GetConfigForClient = nil
+			c.ClientCAs = clientCAs
+			return c, nil
+		}
+	}
+
+	return cfg
+}
+
+// Err -- ошибка последней попытки перечитать файлы; nil, если действуют актуальные сертификаты.
+func (cr *CertReloader) Err() error {
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+
+	return cr.err
+}
+
+// current перечитывает файлы, если они изменились; при ошибке остаются прежние сертификаты.
+func (cr *CertReloader) current() (*tls.Certificate, *x509.CertPool) {
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+
+	if time.Since(cr.checked) >= _reloadCheckInterval {
+		cr.checked = time.Now()
+		if cr.changed() {
+			err := cr.reload()
+			switch {
+			case err != nil:
+				cr.logger.Error("Failed to reload TLS certificate, serving the previous one", zap.Error(err))
+			case cr.err != nil:
+				cr.logger.Info("TLS certificate reloaded after an error")
+			default:
+				cr.logger.Info("TLS certificate reloaded")
+			}
+			cr.err = err
+		}
+	}
+
+	return cr.cert, cr.clientCAs
+}
+
+func (cr *CertReloader) load() error {
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+
+	cr.checked = time.Now()
+	return cr.reload()
+}
+
+func (cr *CertReloader) reload() error {
+	modTimes := make(map[string]time.Time)
+	for _, file := range cr.files() {
+		info, err := os.Stat(file)
+		if err != nil {
+			return err
+		}
+		modTimes[file] = info.ModTime()
+	}
+
+	cert, err := tls.LoadX509KeyPair(cr.certFile, cr.keyFile)
+	if err != nil {
+		return err
+	}
+
+	var clientCAs *x509.CertPool
+	if cr.clientCAFile != "" {
+		pem, err := os.ReadFile(cr.clientCAFile)
+		if err != nil {
+			return err
+		}
+		clientCAs = x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("no certificates in client CA file %s", cr.clientCAFile)
+		}
+	}
+
+	cr.cert = &cert
+	cr.clientCAs = clientCAs
+	cr.modTimes = modTimes
+	return nil
+}
+
+func (cr *CertReloader) changed() bool {
+	for _, file := range cr.files() {
+		info, err := os.Stat(file)
+		if err != nil {
+			return false
+		}
+		if !info.ModTime().Equal(cr.modTimes[file]) {
+			return true
+		}
+	}
+	return false
+}
+
+func (cr *CertReloader) files() []string {
+	files := []string{cr.certFile, cr.keyFile}
+	if cr.clientCAFile != "" {
+		files = append(files, cr.clientCAFile)
+	}
+	return files
+}
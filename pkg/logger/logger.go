@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net"
 
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
@@ -39,7 +40,7 @@ func New(cfg *Config) (logger *Logger, err error) {
 		}
 
 	case "prod":
-		conn, err := net.Dial("udp", fmt.Sprintf("%s:%s", cfg.KibanaHost, cfg.KibanaPort))
+		conn, err := net.Dial("udp", net.JoinHostPort(cfg.KibanaHost, cfg.KibanaPort))
 		if err != nil {
 			return nil, err
 		}
@@ -86,3 +87,24 @@ func FromContext(ctx context.Context) *Logger {
 	}
 	return zap.L()
 }
+
+// Request возвращает логгер запроса из ctx (его кладёт HTTP-middleware), а если его нет -- base.
+// К логгеру добавляются trace_id и span_id текущего спана.
+func Request(ctx context.Context, base *Logger) *Logger {
+	if l, ok := ctx.Value(Logger{}).(*Logger); ok {
+		base = l
+	}
+	return WithTrace(ctx, base)
+}
+
+// WithTrace добавляет к логгеру trace_id и span_id текущего спана, если он есть в контексте.
+func WithTrace(ctx context.Context, logger *Logger) *Logger {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return logger
+	}
+	return logger.With(
+		zap.String("trace_id", sc.TraceID().String()),
+		zap.String("span_id", sc.SpanID().String()),
+	)
+}
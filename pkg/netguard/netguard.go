@@ -0,0 +1,64 @@
+package netguard
+
+import (
+	"errors"
+	"net"
+	"net/netip"
+	"strings"
+	"syscall"
+)
+
+var ErrForbiddenAddress = errors.New("address is not public")
+
+// Непубличные диапазоны, которых нет среди проверок netip.Addr.
+var _reserved = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+}
+
+// Public -- адрес из публичного интернета: не loopback, не частная сеть, не link-local
+// (в том числе 169.254.169.254) и не зарезервированный диапазон.
+func Public(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsValid() || addr.IsLoopback() || addr.IsPrivate() || addr.IsUnspecified() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() || addr.IsInterfaceLocalMulticast() || addr.IsMulticast() {
+		return false
+	}
+	for _, prefix := range _reserved {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// PublicHost проверяет хост ссылки до запроса: localhost и непубличные IP-литералы отклоняются.
+// Имена, которые резолвятся во внутренние адреса, отсекает Control при соединении.
+func PublicHost(host string) bool {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return false
+	}
+	if addr, err := netip.ParseAddr(strings.Trim(host, "[]")); err == nil {
+		return Public(addr)
+	}
+	return true
+}
+
+// Control -- для net.Dialer.Control: запрещает соединения с непубличными адресами.
+// Проверяется уже разрешённый адрес, поэтому защита действует и на редиректы, и на DNS-подмену.
+func Control(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil || !Public(addr) {
+		return ErrForbiddenAddress
+	}
+	return nil
+}
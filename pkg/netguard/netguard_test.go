@@ -0,0 +1,104 @@
+package netguard
+
+import (
+	"errors"
+	"net/netip"
+	"testing"
+)
+
+func TestPublic(t *testing.T) {
+	tests := []struct {
+		addr string
+		want bool
+	}{
+		{"8.8.8.8", true},
+		{"2606:4700:4700::1111", true},
+		{"127.0.0.1", false},
+		{"127.1.2.3", false},
+		{"::1", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"172.31.255.255", false},
+		{"172.32.0.1", true},
+		{"192.168.1.1", false},
+		{"fd00::1", false},
+		{"169.254.169.254", false},
+		{"fe80::1", false},
+		{"0.0.0.0", false},
+		{"::", false},
+		{"100.64.0.1", false},
+		{"198.18.0.1", false},
+		{"224.0.0.1", false},
+		{"255.255.255.255", false},
+		{"::ffff:127.0.0.1", false},
+		{"::ffff:169.254.169.254", false},
+		{"::ffff:10.0.0.1", false},
+		{"::ffff:8.8.8.8", true},
+		{"64:ff9b::a9fe:a9fe", false},
+		{"64:ff9b::808:808", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.addr, func(t *testing.T) {
+			if got := Public(netip.MustParseAddr(tt.addr)); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPublicHost(t *testing.T) {
+	tests := []struct {
+		host string
+		want bool
+	}{
+		{"example.com", true},
+		{"example.com.", true},
+		{"localhost", false},
+		{"LOCALHOST", false},
+		{"localhost.", false},
+		{"api.localhost", false},
+		{"api.localhost.", false},
+		{"localhost.example.com", true},
+		{"127.0.0.1", false},
+		{"169.254.169.254", false},
+		{"[::1]", false},
+		{"[::ffff:127.0.0.1]", false},
+		{"[64:ff9b::7f00:1]", false},
+		{"[2606:4700:4700::1111]", true},
+		{"93.184.216.34", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			if got := PublicHost(tt.host); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestControl(t *testing.T) {
+	tests := []struct {
+		address string
+		want    error
+	}{
+		{"93.184.216.34:443", nil},
+		{"127.0.0.1:80", ErrForbiddenAddress},
+		{"[::ffff:169.254.169.254]:80", ErrForbiddenAddress},
+		{"[64:ff9b::a00:1]:80", ErrForbiddenAddress},
+		{"example.com:80", ErrForbiddenAddress},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.address, func(t *testing.T) {
+			if err := Control("tcp", tt.address, nil); !errors.Is(err, tt.want) {
+				t.Errorf("got %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	if err := Control("tcp", "no-port", nil); err == nil {
+		t.Error("address without port accepted")
+	}
+}
@@ -0,0 +1,189 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"iter"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go-rest-api/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+const _defaultReplicaCheck = 5 * time.Second
+
+type (
+	replica struct {
+		name    string
+		db      *sql.DB
+		healthy atomic.Bool
+	}
+
+	// Cluster -- основной пул и необязательные реплики для чтения.
+	// Состояние реплик проверяется в фоне; Reader обходит их по кругу и пропускает недоступные.
+	Cluster struct {
+		logger   *logger.Logger
+		primary  *DB
+		replicas []*replica
+		next     atomic.Uint64
+		interval time.Duration
+
+		stop chan struct{}
+		done chan struct{}
+		once sync.Once
+	}
+)
+
+// NewCluster подключается к основной базе (с повторами, как New) и открывает пулы реплик.
+// Недоступная при старте реплика не мешает запуску: она начнёт получать запросы, когда ответит.
+func NewCluster(ctx context.Context, cfg *Config) (*Cluster, error) {
+	primary, err := New(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Cluster{
+		logger:   logger.FromContext(ctx),
+		primary:  &DB{DB: primary},
+		interval: orDefault(cfg.ReplicaCheck, _defaultReplicaCheck),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+
+	for _, addr := range cfg.Replicas {
+		if addr = strings.TrimSpace(addr); addr == "" {
+			continue
+		}
+
+		db, err := open(replicaConfig(cfg, addr))
+		if err != nil {
+			c.Close()
+			return nil, err
+		}
+		c.replicas = append(c.replicas, &replica{name: replicaName(addr), db: db})
+	}
+
+	if len(c.replicas) == 0 {
+		close(c.done)
+		return c, nil
+	}
+
+	c.check(ctx)
+	go c.run()
+
+	return c, nil
+}
+
+// Primary -- основной пул: все записи и чтения, которым нужна свежесть.
+func (c *Cluster) Primary() *DB {
+	return c.primary
+}
+
+// Replicas перечисляет реплики: имя (адрес без учётных данных) и пул -- для метрик и проверок готовности.
+func (c *Cluster) Replicas() iter.Seq2[string, *sql.DB] {
+	return func(yield func(string, *sql.DB) bool) {
+		for _, r := range c.replicas {
+			if !yield(r.name, r.db) {
+				return
+			}
+		}
+	}
+}
+
+// Reader -- пул для чтения: здоровая реплика, а если их нет или в этом запросе уже была запись
+// (read-your-writes) -- основной пул.
+func (c *Cluster) Reader(ctx context.Context) *sql.DB {
+	if len(c.replicas) == 0 || wrote(ctx) {
+		return c.primary.DB
+	}
+
+	start := c.next.Add(1)
+	for i := range c.replicas {
+		r := c.replicas[(int(start)+i)%len(c.replicas)]
+		if r.healthy.Load() {
+			return r.db
+		}
+	}
+	return c.primary.DB
+}
+
+// Close останавливает проверки реплик и закрывает все пулы.
+func (c *Cluster) Close() error {
+	c.once.Do(func() { close(c.stop) })
+	<-c.done
+
+	var errs []error
+	for _, r := range c.replicas {
+		errs = append(errs, r.db.Close())
+	}
+	errs = append(errs, c.primary.Close())
+
+	return errors.Join(errs...)
+}
+
+func (c *Cluster) run() {
+	defer close(c.done)
+
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.stop:
+			return
+		case <-ticker.C:
+			c.check(context.Background())
+		}
+	}
+}
+
+// check пингует реплики и пишет в лог смену их состояния.
+func (c *Cluster) check(ctx context.Context) {
+	for _, r := range c.replicas {
+		pingCtx, cancel := context.WithTimeout(ctx, _pingTimeout)
+		err := r.db.PingContext(pingCtx)
+		cancel()
+
+		healthy := err == nil
+		if r.healthy.Swap(healthy) == healthy {
+			continue
+		}
+		if healthy {
+			c.logger.Info("Postgres replica is available", zap.String("replica", r.name))
+		} else {
+			c.logger.Warn("Postgres replica is unavailable, reads go to primary", zap.String("replica", r.name), zap.Error(err))
+		}
+	}
+}
+
+// replicaConfig -- параметры основной базы с адресом реплики.
+func replicaConfig(cfg *Config, addr string) *Config {
+	rc := *cfg
+	rc.Replicas = nil
+
+	if strings.Contains(addr, "://") {
+		rc.DSN = addr
+		return &rc
+	}
+
+	rc.DSN = ""
+	rc.Host, rc.Port = addr, cfg.Port
+	if host, port, err := net.SplitHostPort(addr); err == nil {
+		rc.Host, rc.Port = host, port
+	}
+	return &rc
+}
+
+// replicaName -- адрес реплики для логов, без учётных данных.
+func replicaName(addr string) string {
+	if _, rest, ok := strings.Cut(addr, "@"); ok {
+		return rest
+	}
+	return addr
+}
@@ -0,0 +1,221 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+// fakeDriver -- драйвер без сервера: соединение либо открывается, либо нет (недоступная реплика).
+type fakeDriver struct{ fail bool }
+
+func (d fakeDriver) Open(string) (driver.Conn, error) {
+	if d.fail {
+		return nil, errors.New("connection refused")
+	}
+	return fakeConn{}, nil
+}
+
+type fakeConn struct{}
+
+func (fakeConn) Prepare(string) (driver.Stmt, error) { return fakeStmt{}, nil }
+func (fakeConn) Close() error                        { return nil }
+func (fakeConn) Begin() (driver.Tx, error)           { return fakeTx{}, nil }
+
+func (fakeConn) BeginTx(context.Context, driver.TxOptions) (driver.Tx, error) {
+	return fakeTx{}, nil
+}
+
+type fakeStmt struct{}
+
+func (fakeStmt) Close() error                               { return nil }
+func (fakeStmt) NumInput() int                              { return -1 }
+func (fakeStmt) Exec([]driver.Value) (driver.Result, error) { return driver.RowsAffected(1), nil }
+func (fakeStmt) Query([]driver.Value) (driver.Rows, error)  { return fakeRows{}, nil }
+func (fakeTx) Commit() error                                { return nil }
+func (fakeTx) Rollback() error                              { return nil }
+func (fakeRows) Columns() []string                          { return nil }
+func (fakeRows) Close() error                               { return nil }
+func (fakeRows) Next([]driver.Value) error                  { return io.EOF }
+
+type (
+	fakeTx   struct{}
+	fakeRows struct{}
+)
+
+func init() {
+	sql.Register("fake", fakeDriver{})
+	sql.Register("fakedown", fakeDriver{fail: true})
+}
+
+func openFake(t *testing.T, name string) *sql.DB {
+	t.Helper()
+
+	db, err := sql.Open(name, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+// newTestCluster собирает кластер без фоновых проверок; healthy -- состояние реплик.
+func newTestCluster(t *testing.T, healthy ...bool) *Cluster {
+	t.Helper()
+
+	c := &Cluster{
+		logger:  zap.NewNop(),
+		primary: &DB{DB: openFake(t, "fake")},
+	}
+	for i, ok := range healthy {
+		r := &replica{name: string(rune('a' + i)), db: openFake(t, "fake")}
+		r.healthy.Store(ok)
+		c.replicas = append(c.replicas, r)
+	}
+	return c
+}
+
+func TestReaderWithoutReplicas(t *testing.T) {
+	c := newTestCluster(t)
+
+	if got := c.Reader(context.Background()); got != c.primary.DB {
+		t.Error("reader without replicas is not the primary")
+	}
+}
+
+func TestReaderRoundRobinSkipsUnhealthy(t *testing.T) {
+	c := newTestCluster(t, true, false, true)
+
+	seen := map[*sql.DB]int{}
+	for range 6 {
+		seen[c.Reader(context.Background())]++
+	}
+
+	if seen[c.replicas[0].db] == 0 || seen[c.replicas[2].db] == 0 {
+		t.Errorf("reads are not spread over healthy replicas: %v", seen)
+	}
+	if seen[c.replicas[1].db] != 0 || seen[c.primary.DB] != 0 {
+		t.Error("read went to an unhealthy replica or the primary")
+	}
+}
+
+func TestReaderFallsBackToPrimary(t *testing.T) {
+	c := newTestCluster(t, false, false)
+
+	if got := c.Reader(context.Background()); got != c.primary.DB {
+		t.Error("reader with all replicas down is not the primary")
+	}
+}
+
+func TestReaderStickyAfterWrite(t *testing.T) {
+	tests := []struct {
+		name  string
+		write func(ctx context.Context, db *DB) error
+		stick bool
+	}{
+		{"exec", func(ctx context.Context, db *DB) error {
+			_, err := db.ExecContext(ctx, "UPDATE songs SET link = $1", "x")
+			return err
+		}, true},
+		{"insert returning", func(ctx context.Context, db *DB) error {
+			return db.QueryRowContext(ctx, "INSERT INTO music_groups (name) VALUES ($1) RETURNING id", "x").Err()
+		}, true},
+		{"transaction", func(ctx context.Context, db *DB) error {
+			tx, err := db.BeginTx(ctx, nil)
+			if err != nil {
+				return err
+			}
+			return tx.Rollback()
+		}, true},
+		{"select", func(ctx context.Context, db *DB) error {
+			return db.QueryRowContext(ctx, " -- comment\n(SELECT 1)").Err()
+		}, false},
+		{"cte select", func(ctx context.Context, db *DB) error {
+			return db.QueryRowContext(ctx, "WITH recent AS (SELECT id, updated FROM songs) SELECT COUNT(*) FROM recent").Err()
+		}, false},
+		{"cte update", func(ctx context.Context, db *DB) error {
+			return db.QueryRowContext(ctx, "with moved AS (UPDATE songs SET link = $1 RETURNING id) SELECT COUNT(*) FROM moved", "x").Err()
+		}, true},
+		{"cte insert", func(ctx context.Context, db *DB) error {
+			return db.QueryRowContext(ctx, "WITH g AS (SELECT 1) INSERT INTO music_groups (name) SELECT 'x' FROM g RETURNING id").Err()
+		}, true},
+		{"read-only transaction", func(ctx context.Context, db *DB) error {
+			tx, err := db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+			if err != nil {
+				return err
+			}
+			return tx.Rollback()
+		}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestCluster(t, true)
+			ctx := WithSession(context.Background())
+
+			if err := tt.write(ctx, c.Primary()); err != nil && !errors.Is(err, sql.ErrNoRows) {
+				t.Fatal(err)
+			}
+
+			for range 3 {
+				if got := c.Reader(ctx) == c.primary.DB; got != tt.stick {
+					t.Fatalf("reader is primary: %v, want %v", got, tt.stick)
+				}
+			}
+			if c.Reader(context.Background()) == c.primary.DB {
+				t.Error("write in one session moved another session to the primary")
+			}
+		})
+	}
+}
+
+func TestReaderWithoutSessionDoesNotStick(t *testing.T) {
+	c := newTestCluster(t, true)
+	ctx := context.Background()
+
+	if _, err := c.Primary().ExecContext(ctx, "DELETE FROM songs"); err != nil {
+		t.Fatal(err)
+	}
+	if c.Reader(ctx) == c.primary.DB {
+		t.Error("write without a session moved reads to the primary")
+	}
+}
+
+func TestCheckUpdatesReplicaHealth(t *testing.T) {
+	c := newTestCluster(t)
+	up := &replica{name: "up", db: openFake(t, "fake")}
+	down := &replica{name: "down", db: openFake(t, "fakedown")}
+	down.healthy.Store(true)
+	c.replicas = []*replica{up, down}
+
+	c.check(context.Background())
+
+	if !up.healthy.Load() || down.healthy.Load() {
+		t.Fatalf("healthy: up=%v down=%v, want up=true down=false", up.healthy.Load(), down.healthy.Load())
+	}
+	for range 4 {
+		if got := c.Reader(context.Background()); got != up.db {
+			t.Fatal("read did not go to the only healthy replica")
+		}
+	}
+}
+
+func TestReplicas(t *testing.T) {
+	c := newTestCluster(t, true, false)
+
+	var names []string
+	for name, db := range c.Replicas() {
+		if db != c.replicas[len(names)].db {
+			t.Errorf("replica %s: pool does not match", name)
+		}
+		names = append(names, name)
+	}
+	if len(names) != 2 || names[0] != "a" || names[1] != "b" {
+		t.Errorf("got replicas %v, want [a b]", names)
+	}
+}
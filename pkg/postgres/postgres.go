@@ -1,11 +1,24 @@
 package postgres
 
 import (
+	"context"
 	"database/sql"
+	"database/sql/driver"
 	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
 	"time"
 
+	"go-rest-api/pkg/logger"
+
+	"github.com/XSAM/otelsql"
 	_ "github.com/lib/pq"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.uber.org/zap"
 )
 
 const (
@@ -13,38 +26,169 @@ const (
 	_defaultConnMaxIdleTime = 30 * time.Second
 	_defaultMaxIdleConns    = 5
 	_defaultMaxOpenConns    = 10
+	_defaultSSLMode         = "disable"
+	_defaultStartupTimeout  = 30 * time.Second
+
+	_pingTimeout  = 5 * time.Second
+	_firstBackoff = 500 * time.Millisecond
+	_maxBackoff   = 10 * time.Second
 )
 
+var (
+	reComment = regexp.MustCompile(`--[^\n]*`)
+	reString  = regexp.MustCompile(`'(?:[^']|'')*'`)
+	reNumber  = regexp.MustCompile(`\$?\b\d+(?:\.\d+)?\b`)
+	reSpace   = regexp.MustCompile(`\s+`)
+)
+
+// Config -- параметры подключения. DSN, если задан, используется как есть вместо остальных
+// параметров подключения; настройки пула действуют всегда. Нулевые значения -- умолчания.
 type Config struct {
-	Host     string
-	Port     string
-	DB       string
-	User     string
-	Password string
-}
-
-func New(cfg *Config) (*sql.DB, error) {
-	src := fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=disable",
-		cfg.User,
-		cfg.Password,
-		cfg.Host,
-		cfg.Port,
-		cfg.DB,
-	)
+	DSN              string
+	Host             string
+	Port             string
+	DB               string
+	User             string
+	Password         string
+	SSLMode          string
+	SSLRootCert      string
+	ApplicationName  string
+	StatementTimeout time.Duration
+	MaxOpenConns     int
+	MaxIdleConns     int
+	ConnMaxLifetime  time.Duration
+	ConnMaxIdleTime  time.Duration
+	StartupTimeout   time.Duration
 
-	db, err := sql.Open("postgres", src)
+	// Replicas -- реплики для чтения: DSN или host[:port] с остальными параметрами основной базы.
+	Replicas     []string
+	ReplicaCheck time.Duration
+}
+
+// New открывает пул и ждёт, пока база станет доступна: Ping повторяется с экспоненциальной паузой,
+// пока не истечёт StartupTimeout. Так сервис переживает старт раньше базы в docker-compose.
+func New(ctx context.Context, cfg *Config) (*sql.DB, error) {
+	db, err := open(cfg)
 	if err != nil {
 		return nil, err
 	}
 
-	db.SetConnMaxLifetime(_defaultMaxLifetime)
-	db.SetConnMaxIdleTime(_defaultConnMaxIdleTime)
-	db.SetMaxIdleConns(_defaultMaxIdleConns)
-	db.SetMaxOpenConns(_defaultMaxOpenConns)
+	if err = ping(ctx, db, orDefault(cfg.StartupTimeout, _defaultStartupTimeout)); err != nil {
+		db.Close()
+		return nil, err
+	}
 
-	if err = db.Ping(); err != nil {
+	return db, nil
+}
+
+// open открывает пул с настройками из cfg, не проверяя подключение.
+func open(cfg *Config) (*sql.DB, error) {
+	// Запросы пишутся в спаны трассировки без значений параметров и литералов.
+	db, err := otelsql.Open("postgres", dsn(cfg),
+		otelsql.WithAttributes(semconv.DBSystemPostgreSQL, semconv.DBNamespace(cfg.DB)),
+		otelsql.WithSpanOptions(otelsql.SpanOptions{
+			DisableQuery:         true,
+			OmitConnResetSession: true,
+			OmitRows:             true,
+		}),
+		otelsql.WithAttributesGetter(statement),
+	)
+	if err != nil {
 		return nil, err
 	}
 
-	return db, err
+	db.SetConnMaxLifetime(orDefault(cfg.ConnMaxLifetime, _defaultMaxLifetime))
+	db.SetConnMaxIdleTime(orDefault(cfg.ConnMaxIdleTime, _defaultConnMaxIdleTime))
+	db.SetMaxIdleConns(orDefault(cfg.MaxIdleConns, _defaultMaxIdleConns))
+	db.SetMaxOpenConns(orDefault(cfg.MaxOpenConns, _defaultMaxOpenConns))
+
+	return db, nil
+}
+
+// dsn собирает строку подключения; неизвестные lib/pq параметры (statement_timeout)
+// передаются серверу как параметры сессии.
+func dsn(cfg *Config) string {
+	if cfg.DSN != "" {
+		return cfg.DSN
+	}
+
+	params := url.Values{}
+	params.Set("sslmode", orDefault(cfg.SSLMode, _defaultSSLMode))
+	if cfg.SSLRootCert != "" {
+		params.Set("sslrootcert", cfg.SSLRootCert)
+	}
+	if cfg.ApplicationName != "" {
+		params.Set("application_name", cfg.ApplicationName)
+	}
+	if cfg.StatementTimeout > 0 {
+		params.Set("statement_timeout", strconv.FormatInt(cfg.StatementTimeout.Milliseconds(), 10))
+	}
+
+	src := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(cfg.User, cfg.Password),
+		Host:     net.JoinHostPort(cfg.Host, cfg.Port),
+		Path:     "/" + cfg.DB,
+		RawQuery: params.Encode(),
+	}
+	return src.String()
+}
+
+// ping проверяет подключение, удваивая паузу между попытками (до _maxBackoff);
+// последняя попытка делается в момент истечения timeout.
+func ping(ctx context.Context, db *sql.DB, timeout time.Duration) error {
+	logger := logger.FromContext(ctx)
+	deadline := time.Now().Add(timeout)
+	backoff := _firstBackoff
+
+	for attempt := 1; ; attempt++ {
+		pingCtx, cancel := context.WithTimeout(ctx, _pingTimeout)
+		err := db.PingContext(pingCtx)
+		cancel()
+		if err == nil {
+			return nil
+		}
+
+		wait := min(backoff, time.Until(deadline))
+		if wait <= 0 {
+			return fmt.Errorf("postgres is unavailable after %d attempts: %w", attempt, err)
+		}
+		logger.Warn("Postgres is unavailable, retrying", zap.Int("attempt", attempt), zap.Duration("backoff", wait), zap.Error(err))
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+		backoff = min(2*backoff, _maxBackoff)
+	}
+}
+
+func orDefault[T comparable](value, def T) T {
+	var zero T
+	if value == zero {
+		return def
+	}
+	return value
+}
+
+func statement(_ context.Context, _ otelsql.Method, query string, _ []driver.NamedValue) []attribute.KeyValue {
+	if query == "" {
+		return nil
+	}
+	return []attribute.KeyValue{semconv.DBQueryText(Sanitize(query))}
+}
+
+// Sanitize убирает из запроса комментарии, строковые и числовые литералы и лишние пробелы.
+// Плейсхолдеры $1, $2 ... сохраняются.
+func Sanitize(query string) string {
+	query = reComment.ReplaceAllString(query, "")
+	query = reString.ReplaceAllString(query, "?")
+	query = reNumber.ReplaceAllStringFunc(query, func(s string) string {
+		if strings.HasPrefix(s, "$") {
+			return s
+		}
+		return "?"
+	})
+	return strings.TrimSpace(reSpace.ReplaceAllString(query, " "))
 }
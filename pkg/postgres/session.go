@@ -0,0 +1,100 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"sync/atomic"
+	"unicode"
+)
+
+type sessionKey struct{}
+
+// WithSession начинает сессию запроса: после записи в ней чтения идут в основной пул.
+func WithSession(ctx context.Context) context.Context {
+	return context.WithValue(ctx, sessionKey{}, new(atomic.Bool))
+}
+
+// MarkWrite отмечает запись в сессии; без сессии ничего не делает.
+func MarkWrite(ctx context.Context) {
+	if s, ok := ctx.Value(sessionKey{}).(*atomic.Bool); ok {
+		s.Store(true)
+	}
+}
+
+func wrote(ctx context.Context) bool {
+	s, ok := ctx.Value(sessionKey{}).(*atomic.Bool)
+	return ok && s.Load()
+}
+
+// DB -- основной пул. Запросы, которые могут писать (всё, кроме SELECT и читающих WITH, и транзакции
+// не только для чтения), отмечают сессию, чтобы следующие чтения не ушли на отстающую реплику.
+type DB struct {
+	*sql.DB
+}
+
+func (db *DB) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	MarkWrite(ctx)
+	return db.DB.ExecContext(ctx, query, args...)
+}
+
+func (db *DB) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	if !readOnly(query) {
+		MarkWrite(ctx)
+	}
+	return db.DB.QueryContext(ctx, query, args...)
+}
+
+func (db *DB) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	if !readOnly(query) {
+		MarkWrite(ctx)
+	}
+	return db.DB.QueryRowContext(ctx, query, args...)
+}
+
+func (db *DB) BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error) {
+	if opts == nil || !opts.ReadOnly {
+		MarkWrite(ctx)
+	}
+	return db.DB.BeginTx(ctx, opts)
+}
+
+// readOnly -- запрос начинается с SELECT (после пробелов, скобок и комментариев --)
+// или с WITH, и в нём нет INSERT, UPDATE, DELETE и MERGE (CTE может изменять данные).
+func readOnly(query string) bool {
+	for {
+		query = strings.TrimLeft(query, " \t\r\n(")
+		if !strings.HasPrefix(query, "--") {
+			break
+		}
+		_, query, _ = strings.Cut(query, "\n")
+	}
+
+	switch {
+	case hasKeyword(query, "SELECT"):
+		return true
+	case hasKeyword(query, "WITH"):
+		for _, word := range strings.FieldsFunc(query, notWordChar) {
+			switch strings.ToUpper(word) {
+			case "INSERT", "UPDATE", "DELETE", "MERGE":
+				return false
+			}
+		}
+		return true
+	default:
+		return false
+	}
+}
+
+// hasKeyword -- query начинается со слова keyword (без учёта регистра).
+func hasKeyword(query, keyword string) bool {
+	if len(query) < len(keyword) || !strings.EqualFold(query[:len(keyword)], keyword) {
+		return false
+	}
+	rest := query[len(keyword):]
+	return rest == "" || notWordChar(rune(rest[0]))
+}
+
+func notWordChar(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+}